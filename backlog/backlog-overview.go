@@ -9,6 +9,7 @@ import (
 	"regexp"
 	"sort"
 	"strings"
+	"time"
 )
 
 const (
@@ -24,6 +25,8 @@ var (
 
 type BacklogOverview struct {
 	markdown *MarkdownContent
+	columns  []*BacklogField
+	deps     *DependencyGraph
 }
 
 func LoadBacklogOverview(overviewPath string) (*BacklogOverview, error) {
@@ -35,7 +38,7 @@ func LoadBacklogOverview(overviewPath string) (*BacklogOverview, error) {
 }
 
 func NewBacklogOverview(markdown *MarkdownContent) *BacklogOverview {
-	return &BacklogOverview{markdown}
+	return &BacklogOverview{markdown: markdown}
 }
 
 func (overview *BacklogOverview) Save() error {
@@ -45,10 +48,25 @@ func (overview *BacklogOverview) Save() error {
 	return overview.markdown.Save()
 }
 
+func (overview *BacklogOverview) SaveUnmodified() error {
+	if overview.markdown.isDirty {
+		overview.sortGroupsByStatus()
+	}
+	return overview.markdown.SaveUnmodified()
+}
+
 func (overview *BacklogOverview) Content(timestamp string) []byte {
 	return overview.markdown.Content(timestamp)
 }
 
+func (overview *BacklogOverview) FrontMatter() bool {
+	return overview.markdown.FrontMatter()
+}
+
+func (overview *BacklogOverview) SetFrontMatter(frontMatter bool) error {
+	return overview.markdown.SetFrontMatter(frontMatter)
+}
+
 func (overview *BacklogOverview) Title() string {
 	return overview.markdown.Title()
 }
@@ -89,13 +107,41 @@ func (overview *BacklogOverview) Update(items []*BacklogItem, sorter *BacklogIte
 				overview.markdown.addGroup(group)
 			}
 			rootDir := filepath.Dir(overview.markdown.contentPath)
-			newLines := BacklogView{}.WriteMarkdownItems(items, rootDir, filepath.Join(rootDir, TagsDirectoryName))
+			newLines := BacklogView{}.WriteMarkdownItems(items, rootDir, filepath.Join(rootDir, TagsDirectoryName), overview.deps, overview.columns...)
 			group.ReplaceLines(newLines)
 		}
 	}
 	overview.Save()
 }
 
+func (overview *BacklogOverview) MoveItem(items []*BacklogItem, item *BacklogItem, index int) {
+	sorter := NewBacklogItemsSorter(overview)
+	itemsByStatus := sorter.SortedItemsByStatus()
+	for statusName, statusItems := range itemsByStatus {
+		for i, itemName := range statusItems {
+			if itemName == item.Name() {
+				itemsByStatus[statusName] = append(statusItems[:i:i], statusItems[i+1:]...)
+				break
+			}
+		}
+	}
+
+	statusName := strings.ToLower(item.Status())
+	statusItems := itemsByStatus[statusName]
+	if index < 0 {
+		index = 0
+	}
+	if index > len(statusItems) {
+		index = len(statusItems)
+	}
+	newStatusItems := make([]string, 0, len(statusItems)+1)
+	newStatusItems = append(newStatusItems, statusItems[:index]...)
+	newStatusItems = append(newStatusItems, item.Name())
+	itemsByStatus[statusName] = append(newStatusItems, statusItems[index:]...)
+
+	overview.Update(items, sorter)
+}
+
 func (overview *BacklogOverview) updateItem(item *BacklogItem, itemsByStatus map[string][]string) {
 	itemStatus := strings.ToLower(item.Status())
 NextStatus:
@@ -187,6 +233,20 @@ func (overview *BacklogOverview) UpdateClarifications(items []*BacklogItem) {
 	overview.Save()
 }
 
+func (overview *BacklogOverview) HasForecast() bool {
+	return overview.markdown.Group(ForecastTitle) != nil
+}
+
+func (overview *BacklogOverview) UpdateForecast(items []*ForecastItem, now time.Time) {
+	group := overview.markdown.Group(ForecastTitle)
+	if group == nil {
+		group = &MarkdownGroup{content: overview.markdown, title: ForecastTitle}
+		overview.markdown.addGroup(group)
+	}
+	group.ReplaceLines(BacklogView{}.WriteMarkdownForecast(items, filepath.Dir(overview.markdown.contentPath), now))
+	overview.Save()
+}
+
 func (overview *BacklogOverview) SendNewComments(items []*BacklogItem, onSend func(item *BacklogItem, to []string, comment []string) (me string, err error)) {
 	for _, item := range items {
 		comments := item.Comments()
@@ -210,12 +270,27 @@ func (overview *BacklogOverview) SendNewComments(items []*BacklogItem, onSend fu
 	}
 }
 
-func (overview *BacklogOverview) UpdateProgress(bck *Backlog) error {
-	chart, err := BacklogView{}.Progress(bck, 12, 84)
+func (overview *BacklogOverview) UpdateProgress(bck *Backlog, history *BacklogHistory, sprint *Sprint) error {
+	chart, err := BacklogView{}.Progress(bck, history, 12, 84)
 	if err != nil {
 		return err
 	}
 
+	if history != nil && sprint != nil {
+		days := history.BurnChart(sprint.Start(), sprint.End(), sprint.Name())
+		if len(days) > 0 && days[len(days)-1].Scope > 0 {
+			chart += fmt.Sprintf("\n\nSprint: %s (%s)\n", sprint.Title(), sprint.Dates())
+			chart += "\nBurnup\n" + BacklogView{}.Burnup(days, 84)
+			chart += "\n\nBurndown\n" + BacklogView{}.Burndown(days, 84)
+		}
+	}
+
+	metrics := NewItemsMetrics(bck.AllItems(), history)
+	if len(metrics) > 0 {
+		backlogRows := []*MetricsRow{NewMetricsRow(overview.Title(), metrics)}
+		chart += "\n\nLead and cycle time, days\n" + strings.Join(BacklogView{}.WriteAsciiMetricsReport(backlogRows, metrics), "\n")
+	}
+
 	chartStart, chartEnd := -1, -1
 	for i, line := range overview.markdown.freeText {
 		line = strings.TrimSpace(line)
@@ -247,18 +322,28 @@ func (overview *BacklogOverview) UpdateProgress(bck *Backlog) error {
 	return nil
 }
 
+func (overview *BacklogOverview) SetColumns(columns []*BacklogField) {
+	overview.columns = columns
+}
+
+func (overview *BacklogOverview) SetDependencies(deps *DependencyGraph) {
+	overview.deps = deps
+}
+
 func (overview *BacklogOverview) SetHideEmptyGroups(value bool) {
 	overview.markdown.HideEmptyGroups = value
 }
 
-func (overview *BacklogOverview) UpdateLinks(lastLinkTitle, lastLinkPath, rootDir, baseDir string) {
+func (overview *BacklogOverview) UpdateLinks(rootDir, baseDir string, optionalLinks ...PageLink) {
 	links := []string{
 		MakeIndexLink(rootDir, baseDir),
 		MakeIdeasLink(rootDir, baseDir),
 		MakeTagsLink(rootDir, baseDir),
 	}
-	if _, err := os.Stat(lastLinkPath); err == nil {
-		links = append(links, utils.MakeMarkdownLink(lastLinkTitle, lastLinkPath, baseDir))
+	for _, link := range optionalLinks {
+		if _, err := os.Stat(link.Path); err == nil {
+			links = append(links, utils.MakeMarkdownLink(link.Title, link.Path, baseDir))
+		}
 	}
 	overview.markdown.SetLinks(utils.JoinMarkdownLinks(links...))
 	overview.Save()
@@ -15,32 +15,45 @@ var (
 	TagsDirectoryName     = "tags"
 	TagsFileName          = "tags.md"
 	UsersDirectoryName    = "users"
-	ForbiddenBacklogNames = []string{IdeasDirectoryName, ArchiveDirectoryName, TagsDirectoryName, UsersDirectoryName}
-	ForbiddenItemNames    = []string{ArchiveDirectoryName}
+	SprintsDirectoryName  = "sprints"
+	SprintFileName        = "sprint.md"
+	QueriesDirectoryName  = "queries"
+	ForbiddenBacklogNames = []string{IdeasDirectoryName, ArchiveDirectoryName, TagsDirectoryName, UsersDirectoryName, SprintsDirectoryName, QueriesDirectoryName}
+	ForbiddenItemNames    = []string{ArchiveDirectoryName, strings.TrimSuffix(SprintFileName, ".md")}
 )
 
 type Backlog struct {
-	items []*BacklogItem
+	items  []*BacklogItem
+	fields []*BacklogField
 }
 
 func LoadBacklog(backlogDir string) (*Backlog, error) {
+	fields, err := LoadBacklogFields(backlogDir)
+	if err != nil {
+		return nil, err
+	}
+
 	var items []*BacklogItem
-	activeItems, err := loadItems(backlogDir)
+	activeItems, err := loadItems(backlogDir, fields)
 	if err != nil {
 		return nil, err
 	}
 	items = append(items, activeItems...)
 
-	archivedItems, err := loadItems(filepath.Join(backlogDir, ArchiveDirectoryName))
+	archivedItems, err := loadItems(filepath.Join(backlogDir, ArchiveDirectoryName), fields)
 	if err != nil {
 		return nil, err
 	}
 	items = append(items, archivedItems...)
 
-	return &Backlog{items: items}, nil
+	return &Backlog{items: items, fields: fields}, nil
 }
 
-func loadItems(dir string) ([]*BacklogItem, error) {
+func NewBacklog(items []*BacklogItem) *Backlog {
+	return &Backlog{items: items}
+}
+
+func loadItems(dir string, fields []*BacklogField) ([]*BacklogItem, error) {
 	infos, err := ioutil.ReadDir(dir)
 	if err != nil {
 		if os.IsNotExist(err) {
@@ -52,7 +65,7 @@ func loadItems(dir string) ([]*BacklogItem, error) {
 	for _, info := range infos {
 		baseName := strings.TrimSuffix(info.Name(), filepath.Ext(info.Name()))
 		if !info.IsDir() && strings.HasSuffix(info.Name(), ".md") && !IsForbiddenItemName(baseName) {
-			item, err := LoadBacklogItem(filepath.Join(dir, info.Name()))
+			item, err := LoadBacklogItem(filepath.Join(dir, info.Name()), fields...)
 			if err != nil {
 				return nil, err
 			}
@@ -62,6 +75,10 @@ func loadItems(dir string) ([]*BacklogItem, error) {
 	return items, nil
 }
 
+func (bck *Backlog) Fields() []*BacklogField {
+	return bck.fields
+}
+
 func (bck *Backlog) AllItems() []*BacklogItem {
 	return bck.items
 }
@@ -81,6 +98,11 @@ func (bck *Backlog) AllItemsByStatus(statusCode string) []*BacklogItem {
 	return bck.filteredItems(filter)
 }
 
+func (bck *Backlog) AllDoneItems() []*BacklogItem {
+	filter := &BacklogItemsDoneFilter{}
+	return bck.filteredItems(filter)
+}
+
 func (bck *Backlog) FilteredActiveItems(filter BacklogItemsFilter) []*BacklogItem {
 	resultFilter := &BacklogItemsAndFilter{}
 	resultFilter.And(&BacklogItemsActiveFilter{})
@@ -88,6 +110,10 @@ func (bck *Backlog) FilteredActiveItems(filter BacklogItemsFilter) []*BacklogIte
 	return bck.filteredItems(resultFilter)
 }
 
+func (bck *Backlog) FilteredItems(filter BacklogItemsFilter) []*BacklogItem {
+	return bck.filteredItems(filter)
+}
+
 func (bck *Backlog) filteredItems(filter BacklogItemsFilter) []*BacklogItem {
 	result := make([]*BacklogItem, 0)
 	for _, item := range bck.items {
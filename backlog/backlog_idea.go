@@ -42,6 +42,10 @@ func (idea *BacklogIdea) Save() error {
 	return idea.markdown.Save()
 }
 
+func (idea *BacklogIdea) SaveUnmodified() error {
+	return idea.markdown.SaveUnmodified()
+}
+
 func (idea *BacklogIdea) Name() string {
 	return idea.name
 }
@@ -50,6 +54,10 @@ func (idea *BacklogIdea) HasMetadata() bool {
 	return !idea.markdown.metadata.Empty()
 }
 
+func (idea *BacklogIdea) SetFrontMatter(frontMatter bool) error {
+	return idea.markdown.SetFrontMatter(frontMatter)
+}
+
 func (idea *BacklogIdea) Title() string {
 	return idea.markdown.Title()
 }
@@ -90,7 +98,7 @@ func (idea *BacklogIdea) Tags() []string {
 }
 
 func (idea *BacklogIdea) SetTags(tags []string) {
-	idea.markdown.SetMetadataValue(BacklogIdeaTagsMetadataKey, strings.Join(tags, " "))
+	idea.markdown.SetMetadataValues(BacklogIdeaTagsMetadataKey, tags)
 }
 
 func (idea *BacklogIdea) SetText(text string) {
@@ -1,26 +1,50 @@
 package backlog
 
 import (
+	"fmt"
 	"github.com/mreider/agilemarkdown/utils"
 	"os"
 	"path/filepath"
 	"regexp"
+	"strconv"
 	"strings"
 	"time"
 	"unicode"
 )
 
 const (
-	BacklogItemAuthorMetadataKey   = "Author"
-	BacklogItemStatusMetadataKey   = "Status"
-	BacklogItemAssignedMetadataKey = "Assigned"
-	BacklogItemEstimateMetadataKey = "Estimate"
-	BacklogItemTagsMetadataKey     = "Tags"
-	BacklogItemArchiveMetadataKey  = "Archive"
+	BacklogItemAuthorMetadataKey    = "Author"
+	BacklogItemStatusMetadataKey    = "Status"
+	BacklogItemAssignedMetadataKey  = "Assigned"
+	BacklogItemEstimateMetadataKey  = "Estimate"
+	BacklogItemTagsMetadataKey      = "Tags"
+	BacklogItemArchiveMetadataKey   = "Archive"
+	BacklogItemSprintMetadataKey    = "Sprint"
+	BacklogItemBlocksMetadataKey    = "Blocks"
+	BacklogItemBlockedByMetadataKey = "BlockedBy"
+	BacklogItemEpicMetadataKey      = "Epic"
+	BacklogItemTypeMetadataKey      = "Type"
 )
 
+const NewItemTemplate = `## Problem statement
+
+## Possible solution
+
+## Comments
+
+## Attachments
+`
+
 var (
+	backlogItemMetadataKeys = []string{
+		CreatedMetadataKey, ModifiedMetadataKey, BacklogItemAuthorMetadataKey,
+		BacklogItemStatusMetadataKey, BacklogItemAssignedMetadataKey, BacklogItemEstimateMetadataKey,
+		BacklogItemTagsMetadataKey, BacklogItemArchiveMetadataKey, BacklogItemSprintMetadataKey,
+		BacklogItemBlocksMetadataKey, BacklogItemBlockedByMetadataKey, BacklogItemEpicMetadataKey,
+		BacklogItemTypeMetadataKey}
+	dependencySeparatorRe  = regexp.MustCompile(`[\s,;]+`)
 	commentsTitleRe        = regexp.MustCompile(`^#{1,3}\s+Comments\s*$`)
+	epicProgressTitleRe    = regexp.MustCompile(`^#{1,3}\s+Epic progress\s*$`)
 	commentRe              = regexp.MustCompile(`^(\s*)((@[\w.-_]+[\s,;]+)+)(.*)$`)
 	commentUserSeparatorRe = regexp.MustCompile(`[\s,;]+`)
 )
@@ -36,33 +60,42 @@ type Comment struct {
 type BacklogItem struct {
 	name     string
 	markdown *MarkdownContent
+	fields   []*BacklogField
 }
 
-func LoadBacklogItem(itemPath string) (*BacklogItem, error) {
-	markdown, err := LoadMarkdown(itemPath, []string{
-		CreatedMetadataKey, ModifiedMetadataKey, BacklogItemAuthorMetadataKey,
-		BacklogItemStatusMetadataKey, BacklogItemAssignedMetadataKey, BacklogItemEstimateMetadataKey,
-		BacklogItemTagsMetadataKey, BacklogItemArchiveMetadataKey}, "", nil)
+func LoadBacklogItem(itemPath string, fields ...*BacklogField) (*BacklogItem, error) {
+	markdown, err := LoadMarkdown(itemPath, itemMetadataKeys(fields), "", nil)
 	if err != nil {
 		return nil, err
 	}
 	name := filepath.Base(itemPath)
 	name = strings.TrimSuffix(name, filepath.Ext(name))
-	return &BacklogItem{name, markdown}, nil
+	return &BacklogItem{name, markdown, fields}, nil
 }
 
-func NewBacklogItem(name string, markdownData string) *BacklogItem {
-	markdown := NewMarkdown(markdownData, "", []string{
-		CreatedMetadataKey, ModifiedMetadataKey, BacklogItemAuthorMetadataKey,
-		BacklogItemStatusMetadataKey, BacklogItemAssignedMetadataKey, BacklogItemEstimateMetadataKey,
-		BacklogItemTagsMetadataKey, BacklogItemArchiveMetadataKey}, "", nil)
-	return &BacklogItem{name, markdown}
+func NewBacklogItem(name string, markdownData string, fields ...*BacklogField) *BacklogItem {
+	markdown := NewMarkdown(markdownData, "", itemMetadataKeys(fields), "", nil)
+	return &BacklogItem{name, markdown, fields}
+}
+
+func itemMetadataKeys(fields []*BacklogField) []string {
+	keys := make([]string, 0, len(backlogItemMetadataKeys)+len(fields))
+	keys = append(keys, backlogItemMetadataKeys...)
+	return append(keys, fieldNames(fields)...)
 }
 
 func (item *BacklogItem) Save() error {
 	return item.markdown.Save()
 }
 
+func (item *BacklogItem) SaveUnmodified() error {
+	return item.markdown.SaveUnmodified()
+}
+
+func (item *BacklogItem) SetFrontMatter(frontMatter bool) error {
+	return item.markdown.SetFrontMatter(frontMatter)
+}
+
 func (item *BacklogItem) Name() string {
 	return item.name
 }
@@ -121,10 +154,124 @@ func (item *BacklogItem) Estimate() string {
 	return item.markdown.MetadataValue(BacklogItemEstimateMetadataKey)
 }
 
+func (item *BacklogItem) EstimatePoints() float64 {
+	points, _ := strconv.ParseFloat(item.Estimate(), 64)
+	return points
+}
+
 func (item *BacklogItem) SetEstimate(estimate string) {
 	item.markdown.SetMetadataValue(BacklogItemEstimateMetadataKey, estimate)
 }
 
+func (item *BacklogItem) Sprint() string {
+	return item.markdown.MetadataValue(BacklogItemSprintMetadataKey)
+}
+
+func (item *BacklogItem) SetSprint(sprint string) {
+	item.markdown.SetMetadataValue(BacklogItemSprintMetadataKey, sprint)
+}
+
+func (item *BacklogItem) Blocks() []string {
+	return splitDependencies(item.markdown.MetadataValue(BacklogItemBlocksMetadataKey))
+}
+
+func (item *BacklogItem) SetBlocks(items []string) {
+	item.markdown.SetMetadataValues(BacklogItemBlocksMetadataKey, items)
+}
+
+func (item *BacklogItem) BlockedBy() []string {
+	return splitDependencies(item.markdown.MetadataValue(BacklogItemBlockedByMetadataKey))
+}
+
+func (item *BacklogItem) SetBlockedBy(items []string) {
+	item.markdown.SetMetadataValues(BacklogItemBlockedByMetadataKey, items)
+}
+
+func (item *BacklogItem) Epic() string {
+	return item.markdown.MetadataValue(BacklogItemEpicMetadataKey)
+}
+
+func (item *BacklogItem) SetEpic(epic string) {
+	item.markdown.SetMetadataValue(BacklogItemEpicMetadataKey, epic)
+}
+
+func (item *BacklogItem) Type() string {
+	return item.markdown.MetadataValue(BacklogItemTypeMetadataKey)
+}
+
+func (item *BacklogItem) SetType(itemType string) {
+	item.markdown.SetMetadataValue(BacklogItemTypeMetadataKey, itemType)
+}
+
+func (item *BacklogItem) IsEpic() bool {
+	return strings.ToLower(item.Type()) == EpicItemType
+}
+
+func (item *BacklogItem) UpdateEpicProgress(lines []string) {
+	freeText := item.markdown.freeText
+	startIndex, finishIndex := -1, len(freeText)
+	for i, line := range freeText {
+		if startIndex == -1 {
+			if epicProgressTitleRe.MatchString(line) {
+				startIndex = i
+			}
+		} else if strings.HasPrefix(line, "#") {
+			finishIndex = i
+			break
+		}
+	}
+
+	var section []string
+	if len(lines) > 0 {
+		section = append([]string{"## " + EpicProgressTitle, ""}, lines...)
+		section = append(section, "")
+	}
+
+	newFreeText := make([]string, 0, len(freeText)+len(section))
+	if startIndex != -1 {
+		newFreeText = append(newFreeText, freeText[:startIndex]...)
+		newFreeText = append(newFreeText, section...)
+		newFreeText = append(newFreeText, freeText[finishIndex:]...)
+	} else {
+		if len(section) == 0 {
+			return
+		}
+		insertIndex := len(freeText)
+		for i, line := range freeText {
+			if commentsTitleRe.MatchString(line) {
+				insertIndex = i
+				break
+			}
+		}
+		for insertIndex == len(freeText) && insertIndex > 0 && strings.TrimSpace(freeText[insertIndex-1]) == "" {
+			insertIndex--
+			freeText = freeText[:insertIndex]
+		}
+		newFreeText = append(newFreeText, freeText[:insertIndex]...)
+		if insertIndex > 0 && strings.TrimSpace(freeText[insertIndex-1]) != "" {
+			newFreeText = append(newFreeText, "")
+		}
+		newFreeText = append(newFreeText, section...)
+		newFreeText = append(newFreeText, freeText[insertIndex:]...)
+	}
+	item.markdown.SetFreeText(newFreeText)
+	item.Save()
+}
+
+func splitDependencies(value string) []string {
+	var result []string
+	for _, ref := range dependencySeparatorRe.Split(value, -1) {
+		if ref != "" {
+			result = append(result, ref)
+		}
+	}
+	return result
+}
+
+func (item *BacklogItem) Description() string {
+	return strings.TrimLeft(strings.Join(item.markdown.freeText, "\n"), "\n")
+}
+
 func (item *BacklogItem) SetDescription(description string) {
 	if description != "" {
 		description = "\n" + description
@@ -247,7 +394,44 @@ func (item *BacklogItem) Tags() []string {
 }
 
 func (item *BacklogItem) SetTags(tags []string) {
-	item.markdown.SetMetadataValue(BacklogItemTagsMetadataKey, strings.Join(tags, " "))
+	item.markdown.SetMetadataValues(BacklogItemTagsMetadataKey, tags)
+}
+
+func (item *BacklogItem) Fields() []*BacklogField {
+	return item.fields
+}
+
+func (item *BacklogItem) FieldValue(name string) string {
+	return item.markdown.MetadataValue(name)
+}
+
+// HasFieldValue reports whether the metadata has the key, even with an empty value.
+func (item *BacklogItem) HasFieldValue(name string) bool {
+	return item.markdown.MetadataData(name) != nil
+}
+
+func (item *BacklogItem) SetFieldValue(name, value string) {
+	item.markdown.SetMetadataValue(name, value)
+}
+
+func (item *BacklogItem) Field(name string) (interface{}, error) {
+	field := FieldByName(item.fields, name)
+	if field == nil {
+		return nil, fmt.Errorf("unknown field '%s'", name)
+	}
+	return field.Parse(item.FieldValue(field.Name))
+}
+
+func (item *BacklogItem) FieldNumber(name string) (float64, bool) {
+	value, err := item.Field(name)
+	number, ok := value.(float64)
+	return number, err == nil && ok
+}
+
+func (item *BacklogItem) FieldDate(name string) (time.Time, bool) {
+	value, err := item.Field(name)
+	date, ok := value.(time.Time)
+	return date, err == nil && ok
 }
 
 func (item *BacklogItem) Archived() bool {
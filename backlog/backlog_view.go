@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"github.com/buger/goterm"
 	"github.com/mreider/agilemarkdown/utils"
+	"path/filepath"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -13,12 +15,23 @@ type BacklogView struct {
 }
 
 func (bv BacklogView) WriteAsciiItems(items []*BacklogItem, title string, withOrderNumber bool) []string {
-	userHeader, titleHeader, pointsHeader, tagsHeader := "User", "Title", "Points", "Tags"
-	maxAssignedLen, maxTitleLen, maxTagsLen := len(userHeader), len(titleHeader), len(tagsHeader)
+	return bv.writeAsciiItems(items, nil, title, withOrderNumber)
+}
+
+func (bv BacklogView) WriteAsciiItemsWithProject(overviews map[*BacklogItem]*BacklogOverview, items []*BacklogItem, title string, withOrderNumber bool) []string {
+	return bv.writeAsciiItems(items, overviews, title, withOrderNumber)
+}
+
+func (bv BacklogView) writeAsciiItems(items []*BacklogItem, overviews map[*BacklogItem]*BacklogOverview, title string, withOrderNumber bool) []string {
+	userHeader, projectHeader, titleHeader, pointsHeader, tagsHeader := "User", "Project", "Title", "Points", "Tags"
+	maxAssignedLen, maxProjectLen, maxTitleLen, maxTagsLen := len(userHeader), len(projectHeader), len(titleHeader), len(tagsHeader)
 	for _, item := range items {
 		if len(item.Assigned()) > maxAssignedLen {
 			maxAssignedLen = len(item.Assigned())
 		}
+		if overview := overviews[item]; overview != nil && len(overview.Title()) > maxProjectLen {
+			maxProjectLen = len(overview.Title())
+		}
 		if len(item.Title()) > maxTitleLen {
 			maxTitleLen = len(item.Title())
 		}
@@ -32,10 +45,13 @@ func (bv BacklogView) WriteAsciiItems(items []*BacklogItem, title string, withOr
 	if title != "" {
 		result = append(result, fmt.Sprintf("%s", title))
 	}
-	headers := make([]string, 0, 3)
-	headers = append(headers, fmt.Sprintf("-%s---%s---%s---%s-", strings.Repeat("-", maxAssignedLen), strings.Repeat("-", maxTitleLen), strings.Repeat("-", len(pointsHeader)), strings.Repeat("-", maxTagsLen)))
-	headers = append(headers, fmt.Sprintf(" %s | %s | %s | %s ", utils.PadStringRight(userHeader, maxAssignedLen), utils.PadStringRight(titleHeader, maxTitleLen), pointsHeader, tagsHeader))
-	headers = append(headers, fmt.Sprintf("-%s---%s---%s---%s-", strings.Repeat("-", maxAssignedLen), strings.Repeat("-", maxTitleLen), strings.Repeat("-", len(pointsHeader)), strings.Repeat("-", maxTagsLen)))
+	separator := fmt.Sprintf("-%s---%s---%s---%s-", strings.Repeat("-", maxAssignedLen), strings.Repeat("-", maxTitleLen), strings.Repeat("-", len(pointsHeader)), strings.Repeat("-", maxTagsLen))
+	header := fmt.Sprintf(" %s | %s | %s | %s ", utils.PadStringRight(userHeader, maxAssignedLen), utils.PadStringRight(titleHeader, maxTitleLen), pointsHeader, tagsHeader)
+	if overviews != nil {
+		separator = fmt.Sprintf("-%s---%s---%s---%s---%s-", strings.Repeat("-", maxAssignedLen), strings.Repeat("-", maxProjectLen), strings.Repeat("-", maxTitleLen), strings.Repeat("-", len(pointsHeader)), strings.Repeat("-", maxTagsLen))
+		header = fmt.Sprintf(" %s | %s | %s | %s | %s ", utils.PadStringRight(userHeader, maxAssignedLen), utils.PadStringRight(projectHeader, maxProjectLen), utils.PadStringRight(titleHeader, maxTitleLen), pointsHeader, tagsHeader)
+	}
+	headers := []string{separator, header, separator}
 	if withOrderNumber {
 		headers[0] = "------" + headers[0]
 		headers[1] = "   # |" + headers[1]
@@ -50,13 +66,20 @@ func (bv BacklogView) WriteAsciiItems(items []*BacklogItem, title string, withOr
 		}
 		tags := strings.Join(item.Tags(), " ")
 		line := fmt.Sprintf(" %s | %s | %s | %s ", utils.PadStringRight(item.Assigned(), maxAssignedLen), utils.PadStringRight(item.Title(), maxTitleLen), estimateStr, utils.PadStringRight(tags, maxTagsLen))
+		if overviews != nil {
+			project := ""
+			if overview := overviews[item]; overview != nil {
+				project = overview.Title()
+			}
+			line = fmt.Sprintf(" %s | %s | %s | %s | %s ", utils.PadStringRight(item.Assigned(), maxAssignedLen), utils.PadStringRight(project, maxProjectLen), utils.PadStringRight(item.Title(), maxTitleLen), estimateStr, utils.PadStringRight(tags, maxTagsLen))
+		}
 		if withOrderNumber {
 			line = fmt.Sprintf(" %s |", utils.PadIntLeft(i+1, 3)) + line
 		}
 		result = append(result, line)
 	}
 	if len(items) > 0 {
-		footer := fmt.Sprintf("-%s---%s---%s---%s-", strings.Repeat("-", maxAssignedLen), strings.Repeat("-", maxTitleLen), strings.Repeat("-", len(pointsHeader)), strings.Repeat("-", maxTagsLen))
+		footer := separator
 		if withOrderNumber {
 			footer = "------" + footer
 		}
@@ -65,14 +88,25 @@ func (bv BacklogView) WriteAsciiItems(items []*BacklogItem, title string, withOr
 	return result
 }
 
-func (bv BacklogView) WriteMarkdownItems(items []*BacklogItem, baseDir, tagsDir string) []string {
+func (bv BacklogView) WriteMarkdownItems(items []*BacklogItem, baseDir, tagsDir string, deps *DependencyGraph, columns ...*BacklogField) []string {
 	result := make([]string, 0, 50)
 	headers := make([]string, 0, 2)
 	headers = append(headers, fmt.Sprintf("| User | Title | Points | Tags |"))
 	headers = append(headers, "|---|---|:---:|---|")
+	for _, column := range columns {
+		headers[0] += fmt.Sprintf(" %s |", column.Name)
+		headers[1] += "---|"
+	}
 	result = append(result, headers...)
 	for _, item := range items {
-		line := fmt.Sprintf("| %s | %s | %s | %s |", item.Assigned(), MakeItemLink(item, baseDir), item.Estimate(), MakeTagLinks(item.Tags(), tagsDir, baseDir))
+		itemLink := MakeItemLink(item, baseDir)
+		if deps.IsBlocked(item) {
+			itemLink += " *(blocked)*"
+		}
+		line := fmt.Sprintf("| %s | %s | %s | %s |", item.Assigned(), itemLink, item.Estimate(), MakeTagLinks(item.Tags(), tagsDir, baseDir))
+		for _, column := range columns {
+			line += fmt.Sprintf(" %s |", item.FieldValue(column.Name))
+		}
 		result = append(result, line)
 	}
 	return result
@@ -91,18 +125,56 @@ func (bv BacklogView) WriteMarkdownItemsWithProject(overviews map[*BacklogItem]*
 	return result
 }
 
-func (bv BacklogView) Progress(bck *Backlog, weekCount, width int) (string, error) {
-	items := bck.AllItemsByStatus(FinishedStatus.Code)
-	currentDate := time.Now().UTC()
-	pointsByWeekDelta := make(map[int]float64)
-	for _, item := range items {
-		modified := item.Modified()
-		weekDelta := utils.WeekDelta(currentDate, modified)
-		if -weekCount < weekDelta && weekDelta <= 0 {
-			itemPoints, _ := strconv.ParseFloat(item.Estimate(), 64)
-			pointsByWeekDelta[weekDelta] += itemPoints
+func (bv BacklogView) WriteMarkdownEpicProgress(epic *Epic, baseDir string) []string {
+	if len(epic.Children) == 0 {
+		return nil
+	}
+	result := []string{
+		fmt.Sprintf("%d of %d stories, %s of %s points finished, %.0f%% complete", epic.FinishedCount(), len(epic.Children),
+			formatPoints(epic.FinishedPoints()), formatPoints(epic.TotalPoints()), epic.PercentComplete()),
+		"",
+		"| Status | Stories |",
+		"|---|:---:|",
+	}
+	countByStatus := epic.CountByStatus()
+	for _, status := range AllStatuses {
+		if count := countByStatus[status.Name]; count > 0 {
+			result = append(result, fmt.Sprintf("| %s | %d |", status.Name, count))
+			delete(countByStatus, status.Name)
 		}
 	}
+	otherStatuses := make([]string, 0, len(countByStatus))
+	for status := range countByStatus {
+		otherStatuses = append(otherStatuses, status)
+	}
+	sort.Strings(otherStatuses)
+	for _, status := range otherStatuses {
+		result = append(result, fmt.Sprintf("| %s | %d |", status, countByStatus[status]))
+	}
+
+	result = append(result, "", "| Story | Status | Points |", "|---|---|:---:|")
+	for _, child := range epic.Children {
+		result = append(result, fmt.Sprintf("| %s | %s | %s |", MakeItemLink(child, baseDir), child.Status(), child.Estimate()))
+	}
+	return result
+}
+
+func (bv BacklogView) WriteAsciiEpics(epics *EpicList) []string {
+	lines := make([][]string, 0, len(epics.Epics()))
+	for _, epic := range epics.Epics() {
+		lines = append(lines, []string{
+			epic.Item.Title(),
+			filepath.Base(itemBacklogDirectory(epic.Item)),
+			fmt.Sprintf("%d/%d", epic.FinishedCount(), len(epic.Children)),
+			fmt.Sprintf("%s/%s", formatPoints(epic.FinishedPoints()), formatPoints(epic.TotalPoints())),
+			fmt.Sprintf("%.0f%%", epic.PercentComplete()),
+		})
+	}
+	return writeAsciiTable([]string{"Epic", "Backlog", "Stories", "Points", "Done"}, lines)
+}
+
+func (bv BacklogView) Progress(bck *Backlog, history *BacklogHistory, weekCount, width int) (string, error) {
+	points := WeeklyFinishedPoints(bck, history, weekCount, time.Now().UTC())
 
 	chart := goterm.NewLineChart(width, 20)
 
@@ -110,13 +182,163 @@ func (bv BacklogView) Progress(bck *Backlog, weekCount, width int) (string, erro
 	data.AddColumn("Week")
 	data.AddColumn("Points")
 
-	for i := -weekCount + 1; i <= 0; i++ {
-		data.AddRow(float64(i), pointsByWeekDelta[i])
+	for i, weekPoints := range points {
+		data.AddRow(float64(i-weekCount+1), weekPoints)
 	}
 
 	return chart.Draw(data), nil
 }
 
+func (bv BacklogView) Burndown(days []*BurnChartDay, width int) string {
+	chart := goterm.NewLineChart(width, 20)
+
+	data := new(goterm.DataTable)
+	data.AddColumn("Day")
+	data.AddColumn("Remaining")
+
+	for i, day := range days {
+		data.AddRow(float64(i+1), day.Scope-day.Done)
+	}
+
+	return chart.Draw(data)
+}
+
+func (bv BacklogView) Burnup(days []*BurnChartDay, width int) string {
+	chart := goterm.NewLineChart(width, 20)
+
+	data := new(goterm.DataTable)
+	data.AddColumn("Day")
+	data.AddColumn("Scope")
+	data.AddColumn("Done")
+
+	for i, day := range days {
+		data.AddRow(float64(i+1), day.Scope, day.Done)
+	}
+
+	return chart.Draw(data)
+}
+
+func (bv BacklogView) WriteAsciiMetrics(rows []*MetricsRow, groupHeader string) []string {
+	headers := []string{groupHeader, "Items", "Lead avg", "Lead median", "Lead p85", "Cycle avg", "Cycle median", "Cycle p85"}
+	lines := make([][]string, 0, len(rows))
+	for _, row := range rows {
+		lines = append(lines, []string{
+			row.Group, strconv.Itoa(row.Items),
+			formatDays(row.LeadTime.Count, row.LeadTime.Average), formatDays(row.LeadTime.Count, row.LeadTime.Median), formatDays(row.LeadTime.Count, row.LeadTime.P85),
+			formatDays(row.CycleTime.Count, row.CycleTime.Average), formatDays(row.CycleTime.Count, row.CycleTime.Median), formatDays(row.CycleTime.Count, row.CycleTime.P85),
+		})
+	}
+	return writeAsciiTable(headers, lines)
+}
+
+func (bv BacklogView) WriteAsciiMetricsReport(backlogRows []*MetricsRow, metrics []*ItemMetrics) []string {
+	result := bv.WriteAsciiMetrics(backlogRows, "Backlog")
+	if userRows := MetricsRowsByUser(metrics); len(userRows) > 0 {
+		result = append(result, "")
+		result = append(result, bv.WriteAsciiMetrics(userRows, "User")...)
+	}
+	if tagRows := MetricsRowsByTag(metrics); len(tagRows) > 0 {
+		result = append(result, "")
+		result = append(result, bv.WriteAsciiMetrics(tagRows, "Tag")...)
+	}
+	return result
+}
+
+func (bv BacklogView) CycleTimeScatter(metrics []*ItemMetrics, now time.Time, width int) string {
+	var points [][2]float64
+	for _, m := range metrics {
+		if m.HasCycleTime() {
+			points = append(points, [2]float64{-now.Sub(m.Finished).Hours() / 24, m.CycleTime()})
+		}
+	}
+	if len(points) < 2 {
+		return ""
+	}
+	sort.Slice(points, func(i, j int) bool {
+		return points[i][0] > points[j][0]
+	})
+	if points[0][0] == points[len(points)-1][0] {
+		return ""
+	}
+
+	chart := goterm.NewLineChart(width, 20)
+
+	data := new(goterm.DataTable)
+	data.AddColumn("Finished, days ago")
+	data.AddColumn("Cycle time")
+
+	// goterm connects a point only with a previous point to the left of it,
+	// so descending points added twice are drawn as separate dots
+	for _, point := range points {
+		data.AddRow(point[0], point[1])
+		data.AddRow(point[0], point[1])
+	}
+
+	return chart.Draw(data)
+}
+
+func (bv BacklogView) WriteAsciiForecast(items []*ForecastItem, now time.Time) []string {
+	headers := []string{"Title", "Points", "50%", "85%"}
+	lines := make([][]string, 0, len(items))
+	for _, item := range items {
+		lines = append(lines, []string{item.Item.Title(), item.Item.Estimate(), formatForecastWeek(now, item.Week50), formatForecastWeek(now, item.Week85)})
+	}
+	return writeAsciiTable(headers, lines)
+}
+
+func (bv BacklogView) WriteMarkdownForecast(items []*ForecastItem, baseDir string, now time.Time) []string {
+	result := make([]string, 0, len(items)+2)
+	result = append(result, "| Title | Points | 50% | 85% |", "|---|:---:|---|---|")
+	for _, item := range items {
+		result = append(result, fmt.Sprintf("| %s | %s | %s | %s |", MakeItemLink(item.Item, baseDir), item.Item.Estimate(), formatForecastWeek(now, item.Week50), formatForecastWeek(now, item.Week85)))
+	}
+	return result
+}
+
+func (bv BacklogView) DependencyCycle(deps *DependencyGraph, cycle []*BacklogItem) string {
+	ids := make([]string, 0, len(cycle)+1)
+	for _, item := range cycle {
+		ids = append(ids, deps.ItemID(item))
+	}
+	ids = append(ids, deps.ItemID(cycle[0]))
+	return strings.Join(ids, " -> ")
+}
+
+func (bv BacklogView) WriteAsciiDependencies(deps *DependencyGraph) []string {
+	var result []string
+	for _, item := range deps.Items() {
+		result = append(result, fmt.Sprintf("%s (%s)", deps.ItemID(item), item.Status()))
+		for _, blocker := range deps.BlockedBy(item) {
+			result = append(result, fmt.Sprintf("    blocked by %s (%s)", deps.ItemID(blocker), blocker.Status()))
+		}
+		for _, blockedItem := range deps.Blocks(item) {
+			result = append(result, fmt.Sprintf("    blocks %s (%s)", deps.ItemID(blockedItem), blockedItem.Status()))
+		}
+	}
+	return result
+}
+
+func (bv BacklogView) SprintSummary(sprint *Sprint, items []*BacklogItem, now time.Time) []string {
+	var totalPoints, donePoints float64
+	for _, item := range NewBacklog(items).FilteredActiveItems(NewBacklogItemsSprintFilter(sprint.Name())) {
+		totalPoints += item.EstimatePoints()
+		if IsDoneStatusName(item.Status()) {
+			donePoints += item.EstimatePoints()
+		}
+	}
+
+	result := make([]string, 0, 4)
+	if sprint.Goal() != "" {
+		result = append(result, fmt.Sprintf("Goal: %s", sprint.Goal()))
+	}
+	result = append(result, fmt.Sprintf("Dates: %s", sprint.Dates()))
+	if sprint.State() == SprintStateActive {
+		result = append(result, fmt.Sprintf("Days left: %d", sprint.DaysLeft(now)))
+	}
+	result = append(result, fmt.Sprintf("Points: %s of %s finished", strconv.FormatFloat(donePoints, 'f', -1, 64), strconv.FormatFloat(totalPoints, 'f', -1, 64)))
+	return result
+}
+
 func (bv BacklogView) WriteMarkdownIdeas(ideas []*BacklogIdea, baseDir, tagsDir string) []string {
 	result := make([]string, 0, 50)
 	result = append(result, fmt.Sprintf("| Author | Idea | Tags |"))
@@ -127,3 +349,54 @@ func (bv BacklogView) WriteMarkdownIdeas(ideas []*BacklogIdea, baseDir, tagsDir
 	}
 	return result
 }
+
+func formatDays(count int, days float64) string {
+	if count == 0 {
+		return "-"
+	}
+	return strconv.FormatFloat(days, 'f', 1, 64)
+}
+
+func formatPoints(points float64) string {
+	return strconv.FormatFloat(points, 'f', -1, 64)
+}
+
+func formatForecastWeek(now time.Time, week int) string {
+	if week > ForecastMaxWeeks {
+		return "-"
+	}
+	return fmt.Sprintf("week of %s", ForecastWeekStart(now, week).Format("2006-01-02"))
+}
+
+func writeAsciiTable(headers []string, lines [][]string) []string {
+	widths := make([]int, len(headers))
+	for i, header := range headers {
+		widths[i] = len(header)
+		for _, line := range lines {
+			if len(line[i]) > widths[i] {
+				widths[i] = len(line[i])
+			}
+		}
+	}
+
+	formatLine := func(values []string) string {
+		cells := make([]string, len(values))
+		for i, value := range values {
+			if i == 0 {
+				cells[i] = utils.PadStringRight(value, widths[i])
+			} else {
+				cells[i] = utils.PadStringLeft(value, widths[i])
+			}
+		}
+		return fmt.Sprintf(" %s ", strings.Join(cells, " | "))
+	}
+	separator := strings.Repeat("-", len(formatLine(headers)))
+
+	result := make([]string, 0, len(lines)+4)
+	result = append(result, separator, formatLine(headers), separator)
+	for _, line := range lines {
+		result = append(result, formatLine(line))
+	}
+	result = append(result, separator)
+	return result
+}
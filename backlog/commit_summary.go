@@ -0,0 +1,152 @@
+package backlog
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// ItemChange is a story changed in a commit. Before is nil for a new story and After is nil for a deleted one.
+type ItemChange struct {
+	Before *BacklogItem
+	After  *BacklogItem
+}
+
+func (change *ItemChange) title() string {
+	item := change.After
+	if item == nil {
+		item = change.Before
+	}
+	if item.Title() != "" {
+		return item.Title()
+	}
+	return item.Name()
+}
+
+// CommitSummary describes the changed stories for a commit message, e.g. "alice: 3 items → finished, 1 new item 'X'".
+// The body has a line per changed story.
+func CommitSummary(author string, changes []*ItemChange) (subject string, body []string) {
+	var newItems, estimated, archived, deleted, edited []string
+	var statuses, assignees []string
+	byStatus := make(map[string][]string)
+	byAssignee := make(map[string][]string)
+
+	for _, change := range changes {
+		title := change.title()
+		var details []string
+		switch {
+		case change.Before == nil:
+			newItems = append(newItems, title)
+			details = append(details, fmt.Sprintf("new, %s", strings.ToLower(change.After.Status())))
+		case change.After == nil:
+			deleted = append(deleted, title)
+			details = append(details, "deleted")
+		default:
+			before, after := change.Before, change.After
+			summarized := false
+			if before.Title() != after.Title() {
+				details = append(details, fmt.Sprintf("renamed from '%s'", before.Title()))
+			}
+			if status := strings.ToLower(after.Status()); strings.ToLower(before.Status()) != status {
+				if _, ok := byStatus[status]; !ok {
+					statuses = append(statuses, status)
+				}
+				byStatus[status] = append(byStatus[status], title)
+				summarized = true
+				details = append(details, fmt.Sprintf("%s → %s", strings.ToLower(before.Status()), status))
+			}
+			if before.Estimate() != after.Estimate() {
+				estimated = append(estimated, title)
+				summarized = true
+				details = append(details, fmt.Sprintf("estimate %s → %s", emptyAsDash(before.Estimate()), emptyAsDash(after.Estimate())))
+			}
+			if assigned := after.Assigned(); before.Assigned() != assigned {
+				if _, ok := byAssignee[assigned]; !ok {
+					assignees = append(assignees, assigned)
+				}
+				byAssignee[assigned] = append(byAssignee[assigned], title)
+				summarized = true
+				if assigned == "" {
+					details = append(details, "unassigned")
+				} else {
+					details = append(details, fmt.Sprintf("assigned to %s", assigned))
+				}
+			}
+			if tags := strings.Join(after.Tags(), " "); strings.Join(before.Tags(), " ") != tags {
+				details = append(details, fmt.Sprintf("tags %s → %s", emptyAsDash(strings.Join(before.Tags(), " ")), emptyAsDash(tags)))
+			}
+			if !before.Archived() && after.Archived() {
+				archived = append(archived, title)
+				summarized = true
+				details = append(details, "archived")
+			}
+			if len(details) == 0 && !bytes.Equal(before.Content(), after.Content()) {
+				details = append(details, "edited")
+			}
+			if len(details) == 0 {
+				continue
+			}
+			if !summarized {
+				edited = append(edited, title)
+			}
+		}
+		body = append(body, fmt.Sprintf("- %s: %s", title, strings.Join(details, ", ")))
+	}
+
+	var parts []string
+	switch len(newItems) {
+	case 0:
+	case 1:
+		parts = append(parts, fmt.Sprintf("1 new item '%s'", newItems[0]))
+	default:
+		parts = append(parts, fmt.Sprintf("%d new items", len(newItems)))
+	}
+	for _, status := range statuses {
+		parts = append(parts, fmt.Sprintf("%s → %s", itemsPhrase(byStatus[status]), status))
+	}
+	for _, assigned := range assignees {
+		if assigned == "" {
+			parts = append(parts, fmt.Sprintf("%s unassigned", itemsPhrase(byAssignee[assigned])))
+		} else {
+			parts = append(parts, fmt.Sprintf("%s assigned to %s", itemsPhrase(byAssignee[assigned]), assigned))
+		}
+	}
+	switch len(estimated) {
+	case 0:
+	case 1:
+		parts = append(parts, fmt.Sprintf("estimate changed on '%s'", estimated[0]))
+	default:
+		parts = append(parts, fmt.Sprintf("estimates changed on %d items", len(estimated)))
+	}
+	for _, group := range []struct {
+		titles []string
+		action string
+	}{{archived, "archived"}, {deleted, "deleted"}, {edited, "edited"}} {
+		if len(group.titles) > 0 {
+			parts = append(parts, fmt.Sprintf("%s %s", itemsPhrase(group.titles), group.action))
+		}
+	}
+
+	if len(parts) == 0 {
+		return "sync", nil
+	}
+	subject = strings.Join(parts, ", ")
+	if author != "" {
+		subject = fmt.Sprintf("%s: %s", author, subject)
+	}
+	return subject, body
+}
+
+func itemsPhrase(titles []string) string {
+	if len(titles) == 1 {
+		return fmt.Sprintf("'%s'", titles[0])
+	}
+	return fmt.Sprintf("%d items", len(titles))
+}
+
+func emptyAsDash(value string) string {
+	if value == "" {
+		return "-"
+	}
+	return value
+}
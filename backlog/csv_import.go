@@ -1,79 +1,83 @@
 package backlog
 
 import (
-	"bufio"
-	"encoding/csv"
-	"fmt"
 	"github.com/mreider/agilemarkdown/utils"
-	"io"
-	"os"
-	"path/filepath"
 	"regexp"
+	"strconv"
 	"strings"
 	"time"
 )
 
+const pivotalDateLayout = "Jan 2, 2006"
+
 var (
-	startsFromCapitalLetter = regexp.MustCompile(`^[A-Z][a-z].*`)
-	spacesRe                = regexp.MustCompile(`\s+`)
-	delimiterRe             = regexp.MustCompile(`\s*,\s*`)
-)
+	pivotalCommentRe = regexp.MustCompile(`(?s)^(.*)\s+\((.+) - ([A-Z][a-z]{2} \d{1,2}, \d{4})\)$`)
 
-type CsvImporter struct {
-	csvPath    string
-	backlogDir string
+	pivotalColumns = []string{"backlog", "id", "name", "title", "labels", "estimate", "current state", "status", "created at",
+		"modified at", "requested by", "owned by", "description", "comment", "comments", "archived", "blocked by", "blocks"}
+)
 
-	headers map[string]int
+// PivotalImporter reads CSV files exported by Pivotal Tracker and by am export.
+type PivotalImporter struct {
 }
 
-func NewCsvImporter(csvPath string, backlogDir string) *CsvImporter {
-	return &CsvImporter{csvPath: csvPath, backlogDir: backlogDir}
+func (imp *PivotalImporter) Name() string {
+	return "pivotal"
 }
 
-func (imp *CsvImporter) Import() error {
-	csvFile, err := os.Open(imp.csvPath)
+func (imp *PivotalImporter) Read(path string) ([]*ImportedItem, error) {
+	table, err := readCsvTable(path)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	defer csvFile.Close()
-	reader := csv.NewReader(bufio.NewReader(csvFile))
-	reader.FieldsPerRecord = -1
-	for {
-		line, err := reader.Read()
-		if err == io.EOF {
-			break
-		} else if err != nil {
-			return err
+
+	items := make([]*ImportedItem, 0, len(table.rows))
+	for _, row := range table.rows {
+		created := table.value(row, "created at")
+		if createdDate, err := time.Parse(pivotalDateLayout, created); err == nil {
+			created = utils.GetTimestamp(createdDate.Add(time.Hour * 12))
+		}
+		archived, _ := strconv.ParseBool(table.value(row, "archived"))
+		item := &ImportedItem{
+			Backlog:     table.value(row, "backlog"),
+			Name:        table.value(row, "name"),
+			Title:       table.value(row, "title"),
+			State:       table.value(row, "current state"),
+			Status:      table.value(row, "status"),
+			Created:     created,
+			Modified:    table.value(row, "modified at"),
+			Author:      table.value(row, "requested by"),
+			Assigned:    table.value(row, "owned by"),
+			Estimate:    table.value(row, "estimate"),
+			Tags:        delimiterRe.Split(table.value(row, "labels"), -1),
+			Description: table.rawValue(row, "description"),
+			Archived:    archived,
+			Values:      make(map[string]string),
+		}
+		if comments := strings.TrimSpace(table.rawValue(row, "comments")); comments != "" {
+			// am export writes the Comments section as it is, so it's restored without parsing.
+			item.Description = strings.TrimRight(item.Description, "\n") + "\n\n## Comments\n\n" + comments + "\n"
+		}
+		for _, comment := range table.values(row, "comment") {
+			item.Comments = append(item.Comments, imp.parseComment(comment))
+		}
+		if blockedBy := table.value(row, "blocked by"); blockedBy != "" {
+			item.BlockedBy = delimiterRe.Split(blockedBy, -1)
 		}
-		if imp.headers == nil {
-			imp.parseHeaders(line)
-		} else {
-			err := imp.createItemIfNotExists(line)
-			if err != nil {
-				return err
+		if blocks := table.value(row, "blocks"); blocks != "" {
+			item.Blocks = delimiterRe.Split(blocks, -1)
+		}
+		for header := range table.headers {
+			if !utils.ContainsStringIgnoreCase(pivotalColumns, header) {
+				item.Values[header] = table.value(row, header)
 			}
 		}
+		items = append(items, item)
 	}
-
-	return nil
-}
-
-func (imp *CsvImporter) parseHeaders(line []string) {
-	imp.headers = make(map[string]int)
-	for i, header := range line {
-		imp.headers[strings.ToLower(header)] = i
-	}
-}
-
-func (imp *CsvImporter) cellValue(line []string, header string) string {
-	if headerIndex, ok := imp.headers[header]; !ok || headerIndex >= len(line) {
-		return ""
-	} else {
-		return strings.TrimSpace(line[headerIndex])
-	}
+	return items, nil
 }
 
-func (imp *CsvImporter) stateToStatus(state string) *BacklogItemStatus {
+func (imp *PivotalImporter) DefaultStatus(state string) *BacklogItemStatus {
 	switch strings.ToLower(state) {
 	case "accepted":
 		return FinishedStatus
@@ -87,64 +91,14 @@ func (imp *CsvImporter) stateToStatus(state string) *BacklogItemStatus {
 	return UnplannedStatus
 }
 
-func (imp *CsvImporter) getItemName(title string) string {
-	itemName := utils.GetValidFileName(title)
-	if startsFromCapitalLetter.MatchString(itemName) {
-		itemName = strings.ToLower(itemName[0:1]) + itemName[1:]
-	}
-
-	return itemName
-}
-
-func (imp *CsvImporter) createItemIfNotExists(line []string) error {
-	title := imp.cellValue(line, "title")
-	labels := delimiterRe.Split(imp.cellValue(line, "labels"), -1)
-	itemName := imp.getItemName(title)
-	itemPath := filepath.Join(imp.backlogDir, fmt.Sprintf("%s.md", itemName))
-	_, err := os.Stat(itemPath)
-	if err == nil {
-		fmt.Printf("The item '%s' already exists. Skipping.\n", itemName)
-		return nil
-	}
-	if !os.IsNotExist(err) {
-		return err
+func (imp *PivotalImporter) parseComment(comment string) *ImportedComment {
+	matches := pivotalCommentRe.FindStringSubmatch(comment)
+	if matches == nil {
+		return &ImportedComment{Text: comment}
 	}
-
-	item, err := LoadBacklogItem(itemPath)
-	if err != nil {
-		return err
+	created := matches[3]
+	if createdDate, err := time.Parse(pivotalDateLayout, created); err == nil {
+		created = createdDate.Format("2006-01-02")
 	}
-
-	tagSet := make(map[string]bool)
-	var tags []string
-	for _, label := range labels {
-		label = spacesRe.ReplaceAllString(label, "-")
-		if label != "" && !tagSet[strings.ToLower(label)] {
-			tagSet[label] = true
-			tags = append(tags, label)
-		}
-	}
-
-	estimate := imp.cellValue(line, "estimate")
-	status := imp.stateToStatus(imp.cellValue(line, "current state"))
-	created := imp.cellValue(line, "created at")
-	author := imp.cellValue(line, "requested by")
-	assigned := imp.cellValue(line, "owned by")
-	description := imp.cellValue(line, "description")
-
-	if createdDate, err := time.Parse("Jan 2, 2006", created); err == nil {
-		createdDate = createdDate.Add(time.Hour * 12)
-		created = utils.GetTimestamp(createdDate)
-	}
-
-	item.SetTitle(title)
-	item.SetCreated(created)
-	item.SetModified()
-	item.SetAuthor(author)
-	item.SetStatus(status)
-	item.SetAssigned(assigned)
-	item.SetEstimate(estimate)
-	item.SetTags(tags)
-	item.SetDescription(description)
-	return item.Save()
+	return &ImportedComment{Author: matches[2], Created: created, Text: matches[1]}
 }
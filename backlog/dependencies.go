@@ -0,0 +1,226 @@
+package backlog
+
+import (
+	"fmt"
+	"github.com/mreider/agilemarkdown/utils"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+type DependencyGraph struct {
+	*itemRefs
+	blockers map[*BacklogItem][]*BacklogItem
+	blocked  map[*BacklogItem][]*BacklogItem
+	missing  []*MissingDependency
+}
+
+type itemRefs struct {
+	rootDir   string
+	items     []*BacklogItem
+	itemPaths map[string]*BacklogItem
+}
+
+type MissingDependency struct {
+	Item *BacklogItem
+	Ref  string
+}
+
+func NewDependencyGraph(rootDir string, items []*BacklogItem) *DependencyGraph {
+	g := &DependencyGraph{
+		itemRefs: newItemRefs(rootDir, items),
+		blockers: make(map[*BacklogItem][]*BacklogItem),
+		blocked:  make(map[*BacklogItem][]*BacklogItem),
+	}
+	for _, item := range items {
+		for _, ref := range item.BlockedBy() {
+			if blocker := g.resolve(item, ref); blocker != nil {
+				g.addDependency(blocker, item)
+			} else {
+				g.missing = append(g.missing, &MissingDependency{Item: item, Ref: ref})
+			}
+		}
+		for _, ref := range item.Blocks() {
+			if blockedItem := g.resolve(item, ref); blockedItem != nil {
+				g.addDependency(item, blockedItem)
+			} else {
+				g.missing = append(g.missing, &MissingDependency{Item: item, Ref: ref})
+			}
+		}
+	}
+	return g
+}
+
+func newItemRefs(rootDir string, items []*BacklogItem) *itemRefs {
+	rootDir, _ = filepath.Abs(rootDir)
+	refs := &itemRefs{rootDir: rootDir, items: items, itemPaths: make(map[string]*BacklogItem, len(items))}
+	for _, item := range items {
+		itemPath, _ := filepath.Abs(item.Path())
+		refs.itemPaths[itemPath] = item
+	}
+	return refs
+}
+
+func (refs *itemRefs) resolve(item *BacklogItem, ref string) *BacklogItem {
+	refPath := strings.TrimSuffix(filepath.FromSlash(ref), ".md") + ".md"
+	if strings.ContainsRune(ref, '/') {
+		for _, baseDir := range []string{filepath.Dir(item.Path()), refs.rootDir} {
+			fullPath, _ := filepath.Abs(filepath.Join(baseDir, refPath))
+			if refItem := refs.itemPaths[fullPath]; refItem != nil {
+				return refItem
+			}
+		}
+		return nil
+	}
+
+	name := strings.TrimSuffix(refPath, ".md")
+	var candidates []*BacklogItem
+	for _, candidate := range refs.items {
+		if strings.ToLower(candidate.Name()) == strings.ToLower(name) || candidate.Name() == utils.GetValidFileName(name) {
+			candidates = append(candidates, candidate)
+		}
+	}
+	if len(candidates) == 0 {
+		return nil
+	}
+	itemBacklogDir := itemBacklogDirectory(item)
+	for _, candidate := range candidates {
+		if itemBacklogDirectory(candidate) == itemBacklogDir {
+			return candidate
+		}
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		return candidates[i].Path() < candidates[j].Path()
+	})
+	return candidates[0]
+}
+
+func (g *DependencyGraph) addDependency(blocker, blockedItem *BacklogItem) {
+	for _, item := range g.blockers[blockedItem] {
+		if item == blocker {
+			return
+		}
+	}
+	g.blockers[blockedItem] = append(g.blockers[blockedItem], blocker)
+	g.blocked[blocker] = append(g.blocked[blocker], blockedItem)
+}
+
+func (g *DependencyGraph) Items() []*BacklogItem {
+	var result []*BacklogItem
+	for _, item := range g.items {
+		if len(g.blockers[item]) > 0 || len(g.blocked[item]) > 0 {
+			result = append(result, item)
+		}
+	}
+	return result
+}
+
+func (g *DependencyGraph) BlockedBy(item *BacklogItem) []*BacklogItem {
+	return g.blockers[g.find(item)]
+}
+
+func (g *DependencyGraph) Blocks(item *BacklogItem) []*BacklogItem {
+	return g.blocked[g.find(item)]
+}
+
+func (g *DependencyGraph) IsBlocked(item *BacklogItem) bool {
+	if g == nil {
+		return false
+	}
+	for _, blocker := range g.blockers[g.find(item)] {
+		if !IsDoneStatusName(blocker.Status()) {
+			return true
+		}
+	}
+	return false
+}
+
+func (refs *itemRefs) find(item *BacklogItem) *BacklogItem {
+	itemPath, _ := filepath.Abs(item.Path())
+	if refItem := refs.itemPaths[itemPath]; refItem != nil {
+		return refItem
+	}
+	return item
+}
+
+func (g *DependencyGraph) Missing() []*MissingDependency {
+	return g.missing
+}
+
+func (g *DependencyGraph) Cycles() [][]*BacklogItem {
+	const (
+		notVisited = iota
+		inProgress
+		visited
+	)
+	state := make(map[*BacklogItem]int, len(g.items))
+	var stack []*BacklogItem
+	var cycles [][]*BacklogItem
+
+	var visit func(item *BacklogItem)
+	visit = func(item *BacklogItem) {
+		state[item] = inProgress
+		stack = append(stack, item)
+		for _, next := range g.blocked[item] {
+			switch state[next] {
+			case notVisited:
+				visit(next)
+			case inProgress:
+				for i := len(stack) - 1; i >= 0; i-- {
+					if stack[i] == next {
+						cycle := make([]*BacklogItem, len(stack)-i)
+						copy(cycle, stack[i:])
+						cycles = append(cycles, cycle)
+						break
+					}
+				}
+			}
+		}
+		stack = stack[:len(stack)-1]
+		state[item] = visited
+	}
+
+	for _, item := range g.items {
+		if state[item] == notVisited {
+			visit(item)
+		}
+	}
+	return cycles
+}
+
+func (refs *itemRefs) ItemID(item *BacklogItem) string {
+	itemPath, _ := filepath.Abs(item.Path())
+	relPath, err := filepath.Rel(refs.rootDir, itemPath)
+	if err != nil {
+		relPath = item.Name()
+	}
+	return filepath.ToSlash(strings.TrimSuffix(relPath, ".md"))
+}
+
+func (g *DependencyGraph) Dot() []string {
+	result := []string{"digraph dependencies {", "  rankdir=LR;"}
+	for _, item := range g.Items() {
+		attrs := fmt.Sprintf("label=%q", item.Title())
+		if IsDoneStatusName(item.Status()) {
+			attrs += ", color=gray, fontcolor=gray"
+		} else if g.IsBlocked(item) {
+			attrs += ", color=red"
+		}
+		result = append(result, fmt.Sprintf("  %q [%s];", g.ItemID(item), attrs))
+	}
+	for _, item := range g.Items() {
+		for _, blockedItem := range g.blocked[item] {
+			result = append(result, fmt.Sprintf("  %q -> %q;", g.ItemID(item), g.ItemID(blockedItem)))
+		}
+	}
+	result = append(result, "}")
+	return result
+}
+
+func itemBacklogDirectory(item *BacklogItem) string {
+	dir, _ := filepath.Abs(filepath.Dir(item.Path()))
+	if filepath.Base(dir) == ArchiveDirectoryName {
+		dir = filepath.Dir(dir)
+	}
+	return dir
+}
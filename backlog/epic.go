@@ -0,0 +1,124 @@
+package backlog
+
+import (
+	"sort"
+	"strings"
+)
+
+const (
+	EpicItemType      = "epic"
+	EpicProgressTitle = "Epic progress"
+)
+
+type EpicList struct {
+	*itemRefs
+	epics   []*Epic
+	missing []*MissingDependency
+}
+
+type Epic struct {
+	Item     *BacklogItem
+	Children []*BacklogItem
+}
+
+func NewEpicList(rootDir string, items []*BacklogItem) *EpicList {
+	list := &EpicList{itemRefs: newItemRefs(rootDir, items)}
+	epicsByItem := make(map[*BacklogItem]*Epic)
+	addEpic := func(item *BacklogItem) *Epic {
+		epic := epicsByItem[item]
+		if epic == nil {
+			epic = &Epic{Item: item}
+			epicsByItem[item] = epic
+			list.epics = append(list.epics, epic)
+		}
+		return epic
+	}
+
+	for _, item := range items {
+		if item.IsEpic() {
+			addEpic(item)
+		}
+	}
+	for _, item := range items {
+		ref := item.Epic()
+		if ref == "" {
+			continue
+		}
+		parent := list.resolve(item, ref)
+		if parent == nil || parent == item {
+			list.missing = append(list.missing, &MissingDependency{Item: item, Ref: ref})
+			continue
+		}
+		epic := addEpic(parent)
+		epic.Children = append(epic.Children, item)
+	}
+
+	sort.SliceStable(list.epics, func(i, j int) bool {
+		return list.ItemID(list.epics[i].Item) < list.ItemID(list.epics[j].Item)
+	})
+	return list
+}
+
+func (list *EpicList) Epics() []*Epic {
+	return list.epics
+}
+
+func (list *EpicList) Epic(item *BacklogItem) *Epic {
+	item = list.find(item)
+	for _, epic := range list.epics {
+		if epic.Item == item {
+			return epic
+		}
+	}
+	return nil
+}
+
+func (list *EpicList) Missing() []*MissingDependency {
+	return list.missing
+}
+
+func (epic *Epic) CountByStatus() map[string]int {
+	result := make(map[string]int)
+	for _, child := range epic.Children {
+		result[strings.ToLower(child.Status())]++
+	}
+	return result
+}
+
+func (epic *Epic) FinishedCount() int {
+	var count int
+	for _, child := range epic.Children {
+		if IsDoneStatusName(child.Status()) {
+			count++
+		}
+	}
+	return count
+}
+
+func (epic *Epic) TotalPoints() float64 {
+	var points float64
+	for _, child := range epic.Children {
+		points += child.EstimatePoints()
+	}
+	return points
+}
+
+func (epic *Epic) FinishedPoints() float64 {
+	var points float64
+	for _, child := range epic.Children {
+		if IsDoneStatusName(child.Status()) {
+			points += child.EstimatePoints()
+		}
+	}
+	return points
+}
+
+func (epic *Epic) PercentComplete() float64 {
+	if total := epic.TotalPoints(); total > 0 {
+		return epic.FinishedPoints() / total * 100
+	}
+	if len(epic.Children) > 0 {
+		return float64(epic.FinishedCount()) / float64(len(epic.Children)) * 100
+	}
+	return 0
+}
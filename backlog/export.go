@@ -0,0 +1,136 @@
+package backlog
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"github.com/mreider/agilemarkdown/utils"
+	"io"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+const (
+	ExportFormatCSV  = "csv"
+	ExportFormatJSON = "json"
+	ExportFormatXLSX = "xlsx"
+)
+
+var (
+	ExportFormats = []string{ExportFormatCSV, ExportFormatJSON, ExportFormatXLSX}
+
+	exportHeaders = []string{
+		"Backlog", "Name", "Title", "Labels", "Estimate", "Current State", "Status", "Created at", "Modified at", "Requested by",
+		"Owned by", "Description", "Comments", "Archived", "Sprint", "Epic", "Type", "Blocked by", "Blocks",
+	}
+)
+
+type ItemsExporter struct {
+	fields []*BacklogField
+}
+
+func NewItemsExporter(fields []*BacklogField) *ItemsExporter {
+	return &ItemsExporter{fields: fields}
+}
+
+func (exp *ItemsExporter) Headers() []string {
+	headers := append([]string{}, exportHeaders...)
+	for _, field := range exp.fields {
+		headers = append(headers, field.Name)
+	}
+	return headers
+}
+
+func (exp *ItemsExporter) Row(item *BacklogItem) []string {
+	row := []string{
+		filepath.Base(itemBacklogDirectory(item)),
+		item.Name(),
+		item.Title(),
+		strings.Join(item.Tags(), ", "),
+		item.Estimate(),
+		statusToState(StatusByName(item.Status())),
+		strings.ToLower(item.Status()),
+		item.FieldValue(CreatedMetadataKey),
+		item.FieldValue(ModifiedMetadataKey),
+		item.Author(),
+		item.Assigned(),
+		exportDescription(item),
+		exportComments(item),
+		strconv.FormatBool(item.Archived()),
+		item.Sprint(),
+		item.Epic(),
+		item.Type(),
+		strings.Join(item.BlockedBy(), ", "),
+		strings.Join(item.Blocks(), ", "),
+	}
+	for _, field := range exp.fields {
+		row = append(row, item.FieldValue(field.Name))
+	}
+	return row
+}
+
+// exportDescription returns the text of the item without the comments, they are exported in their own column.
+func exportDescription(item *BacklogItem) string {
+	before, comments, after := splitItemComments(item.markdown.freeText)
+	if comments == nil {
+		return item.Description()
+	}
+	lines := append(append([]string{}, before...), after...)
+	return strings.TrimRight(strings.TrimLeft(strings.Join(lines, "\n"), "\n"), " \t\n")
+}
+
+// exportComments returns the Comments section without its title, as it's written in the item, so the import restores it.
+func exportComments(item *BacklogItem) string {
+	_, comments, _ := splitItemComments(item.markdown.freeText)
+	if len(comments) == 0 {
+		return ""
+	}
+	return strings.Trim(strings.Join(comments[1:], "\n"), " \t\n")
+}
+
+func (exp *ItemsExporter) Export(w io.Writer, format string, items []*BacklogItem) error {
+	rows := make([][]string, 0, len(items)+1)
+	rows = append(rows, exp.Headers())
+	for _, item := range items {
+		rows = append(rows, exp.Row(item))
+	}
+
+	switch format {
+	case ExportFormatCSV:
+		writer := csv.NewWriter(w)
+		if err := writer.WriteAll(rows); err != nil {
+			return err
+		}
+		return writer.Error()
+	case ExportFormatJSON:
+		objects := make([]map[string]string, 0, len(items))
+		for _, row := range rows[1:] {
+			object := make(map[string]string)
+			for i, header := range rows[0] {
+				object[header] = row[i]
+			}
+			objects = append(objects, object)
+		}
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(objects)
+	case ExportFormatXLSX:
+		return utils.WriteXLSX(w, "Stories", rows)
+	}
+	return fmt.Errorf("unknown format '%s', should be one of: %s", format, strings.Join(ExportFormats, ", "))
+}
+
+func statusToState(status *BacklogItemStatus) string {
+	switch {
+	case status == nil:
+		return "unscheduled"
+	case status.Done:
+		return "accepted"
+	case status.Started:
+		return "started"
+	case status.Initial:
+		return "unscheduled"
+	}
+	return "unstarted"
+}
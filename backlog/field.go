@@ -0,0 +1,170 @@
+package backlog
+
+import (
+	"errors"
+	"fmt"
+	"github.com/mreider/agilemarkdown/config"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	FieldsFileName = "fields.json"
+
+	FieldTypeString = "string"
+	FieldTypeEnum   = "enum"
+	FieldTypeDate   = "date"
+	FieldTypeNumber = "number"
+	FieldTypeUser   = "user"
+
+	FieldDateLayout = "2006-01-02"
+)
+
+var fieldTypes = []string{FieldTypeString, FieldTypeEnum, FieldTypeDate, FieldTypeNumber, FieldTypeUser}
+
+type BacklogField struct {
+	Name   string
+	Type   string
+	Values []string
+	Column bool
+}
+
+func LoadBacklogFields(backlogDir string) ([]*BacklogField, error) {
+	fieldsPath := filepath.Join(backlogDir, FieldsFileName)
+	fieldsCfg, err := config.LoadFieldsConfig(fieldsPath)
+	if err != nil {
+		return nil, fmt.Errorf("can't load the fields file %s: %v", fieldsPath, err)
+	}
+
+	fields := make([]*BacklogField, 0, len(fieldsCfg))
+	for _, fieldCfg := range fieldsCfg {
+		field := &BacklogField{
+			Name:   strings.TrimSpace(fieldCfg.Name),
+			Type:   strings.ToLower(strings.TrimSpace(fieldCfg.Type)),
+			Values: fieldCfg.Values,
+			Column: fieldCfg.Column,
+		}
+		if field.Type == "" {
+			field.Type = FieldTypeString
+		}
+		err := validateField(field, fields)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", fieldsPath, err)
+		}
+		fields = append(fields, field)
+	}
+	return fields, nil
+}
+
+func validateField(field *BacklogField, prevFields []*BacklogField) error {
+	if field.Name == "" || strings.ContainsAny(field.Name, ": \t") {
+		return fmt.Errorf("invalid field name '%s'", field.Name)
+	}
+	for _, key := range backlogItemMetadataKeys {
+		if strings.ToLower(key) == strings.ToLower(field.Name) {
+			return fmt.Errorf("the field name '%s' is reserved", field.Name)
+		}
+	}
+	if FieldByName(prevFields, field.Name) != nil {
+		return fmt.Errorf("duplicate field '%s'", field.Name)
+	}
+	isValidType := false
+	for _, fieldType := range fieldTypes {
+		isValidType = isValidType || field.Type == fieldType
+	}
+	if !isValidType {
+		return fmt.Errorf("the field '%s' has an unknown type '%s'", field.Name, field.Type)
+	}
+	if field.Type == FieldTypeEnum && len(field.Values) == 0 {
+		return fmt.Errorf("the enum field '%s' should have values", field.Name)
+	}
+	return nil
+}
+
+func FieldByName(fields []*BacklogField, name string) *BacklogField {
+	for _, field := range fields {
+		if strings.ToLower(field.Name) == strings.ToLower(name) {
+			return field
+		}
+	}
+	return nil
+}
+
+func ColumnFields(fields []*BacklogField) []*BacklogField {
+	var columns []*BacklogField
+	for _, field := range fields {
+		if field.Column {
+			columns = append(columns, field)
+		}
+	}
+	return columns
+}
+
+func fieldNames(fields []*BacklogField) []string {
+	names := make([]string, 0, len(fields))
+	for _, field := range fields {
+		names = append(names, field.Name)
+	}
+	return names
+}
+
+func (field *BacklogField) Parse(value string) (interface{}, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return nil, nil
+	}
+	switch field.Type {
+	case FieldTypeEnum:
+		for _, allowedValue := range field.Values {
+			if strings.ToLower(allowedValue) == strings.ToLower(value) {
+				return allowedValue, nil
+			}
+		}
+		return nil, fmt.Errorf("should be one of: %s", strings.Join(field.Values, ", "))
+	case FieldTypeDate:
+		date, err := time.Parse(FieldDateLayout, value)
+		if err != nil {
+			return nil, errors.New("should be a date in YYYY-MM-DD format")
+		}
+		return date, nil
+	case FieldTypeNumber:
+		number, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return nil, errors.New("should be a number")
+		}
+		return number, nil
+	default:
+		return value, nil
+	}
+}
+
+func (field *BacklogField) Validate(value string, isKnownUser func(user string) bool) error {
+	parsed, err := field.Parse(value)
+	if err != nil {
+		return err
+	}
+	if field.Type == FieldTypeUser && parsed != nil && isKnownUser != nil && !isKnownUser(parsed.(string)) {
+		return errors.New("should be a known user")
+	}
+	return nil
+}
+
+func (field *BacklogField) Equal(value1, value2 string) bool {
+	parsed1, err1 := field.Parse(value1)
+	parsed2, err2 := field.Parse(value2)
+	if err1 != nil || err2 != nil {
+		return strings.ToLower(strings.TrimSpace(value1)) == strings.ToLower(strings.TrimSpace(value2))
+	}
+	switch v1 := parsed1.(type) {
+	case time.Time:
+		v2, ok := parsed2.(time.Time)
+		return ok && v1.Equal(v2)
+	case string:
+		v2, ok := parsed2.(string)
+		return ok && strings.ToLower(v1) == strings.ToLower(v2)
+	default:
+		return parsed1 == parsed2
+	}
+}
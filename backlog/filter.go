@@ -37,6 +37,32 @@ type BacklogItemsActiveFilter struct {
 type BacklogItemsArchivedFilter struct {
 }
 
+type BacklogItemsDoneFilter struct {
+}
+
+type BacklogItemsSprintFilter struct {
+	sprint string
+}
+
+type BacklogItemsFieldFilter struct {
+	field *BacklogField
+	value string
+}
+
+type BacklogItemsNotFilter struct {
+	filter BacklogItemsFilter
+}
+
+type BacklogItemsTitleFilter struct {
+	text string
+}
+
+type BacklogItemsCompareFilter struct {
+	value   func(item *BacklogItem) (float64, bool)
+	op      string
+	operand float64
+}
+
 type tagFilter struct {
 	tag string
 }
@@ -137,3 +163,64 @@ func (f *BacklogItemsActiveFilter) Match(item *BacklogItem) bool {
 func (f *BacklogItemsArchivedFilter) Match(item *BacklogItem) bool {
 	return item.Archived()
 }
+
+func (f *BacklogItemsDoneFilter) Match(item *BacklogItem) bool {
+	return IsDoneStatusName(item.Status())
+}
+
+func NewBacklogItemsSprintFilter(sprint string) *BacklogItemsSprintFilter {
+	return &BacklogItemsSprintFilter{sprint: strings.ToLower(sprint)}
+}
+
+func (f *BacklogItemsSprintFilter) Match(item *BacklogItem) bool {
+	return strings.ToLower(item.Sprint()) == f.sprint
+}
+
+func NewBacklogItemsFieldFilter(field *BacklogField, value string) *BacklogItemsFieldFilter {
+	return &BacklogItemsFieldFilter{field: field, value: value}
+}
+
+func (f *BacklogItemsFieldFilter) Match(item *BacklogItem) bool {
+	return f.field.Equal(item.FieldValue(f.field.Name), f.value)
+}
+
+func NewBacklogItemsNotFilter(filter BacklogItemsFilter) *BacklogItemsNotFilter {
+	return &BacklogItemsNotFilter{filter: filter}
+}
+
+func (f *BacklogItemsNotFilter) Match(item *BacklogItem) bool {
+	return !f.filter.Match(item)
+}
+
+func NewBacklogItemsTitleFilter(text string) *BacklogItemsTitleFilter {
+	return &BacklogItemsTitleFilter{text: strings.ToLower(text)}
+}
+
+func (f *BacklogItemsTitleFilter) Match(item *BacklogItem) bool {
+	return strings.Contains(strings.ToLower(item.Title()), f.text)
+}
+
+func NewBacklogItemsCompareFilter(value func(item *BacklogItem) (float64, bool), op string, operand float64) *BacklogItemsCompareFilter {
+	return &BacklogItemsCompareFilter{value: value, op: op, operand: operand}
+}
+
+func (f *BacklogItemsCompareFilter) Match(item *BacklogItem) bool {
+	value, ok := f.value(item)
+	if !ok {
+		return false
+	}
+	switch f.op {
+	case ">":
+		return value > f.operand
+	case ">=":
+		return value >= f.operand
+	case "<":
+		return value < f.operand
+	case "<=":
+		return value <= f.operand
+	case "!=":
+		return value != f.operand
+	default:
+		return value == f.operand
+	}
+}
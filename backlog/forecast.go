@@ -0,0 +1,84 @@
+package backlog
+
+import (
+	"github.com/mreider/agilemarkdown/utils"
+	"math/rand"
+	"sort"
+	"time"
+)
+
+const (
+	ForecastTitle    = "Forecast"
+	ForecastMaxWeeks = 520
+	ForecastRuns     = 1000
+	ForecastWeeks    = 12
+)
+
+type ForecastItem struct {
+	Item   *BacklogItem
+	Week50 int
+	Week85 int
+}
+
+func WeeklyFinishedPoints(bck *Backlog, history *BacklogHistory, weekCount int, now time.Time) []float64 {
+	points := make([]float64, weekCount)
+	for _, item := range bck.AllDoneItems() {
+		weekDelta := utils.WeekDelta(now, history.FinishedDate(item))
+		if -weekCount < weekDelta && weekDelta <= 0 {
+			points[weekCount-1+weekDelta] += item.EstimatePoints()
+		}
+	}
+	return points
+}
+
+func Forecast(items []*BacklogItem, weeklyPoints []float64, runs int, rnd *rand.Rand) []*ForecastItem {
+	hasPoints := false
+	for _, points := range weeklyPoints {
+		hasPoints = hasPoints || points > 0
+	}
+	if !hasPoints || len(items) == 0 || runs <= 0 {
+		return nil
+	}
+
+	weeks := make([][]float64, len(items))
+	for i := range weeks {
+		weeks[i] = make([]float64, runs)
+	}
+	for run := 0; run < runs; run++ {
+		var required, finished float64
+		week := 1
+		finished = weeklyPoints[rnd.Intn(len(weeklyPoints))]
+		for i, item := range items {
+			required += item.EstimatePoints()
+			for finished < required && week <= ForecastMaxWeeks {
+				week++
+				finished += weeklyPoints[rnd.Intn(len(weeklyPoints))]
+			}
+			weeks[i][run] = float64(week)
+		}
+	}
+
+	result := make([]*ForecastItem, 0, len(items))
+	for i, item := range items {
+		sort.Float64s(weeks[i])
+		result = append(result, &ForecastItem{Item: item, Week50: int(percentile(weeks[i], 0.5)), Week85: int(percentile(weeks[i], 0.85))})
+	}
+	return result
+}
+
+func ForecastBacklog(bck *Backlog, history *BacklogHistory, sorter *BacklogItemsSorter, weekCount, runs int, now time.Time) []*ForecastItem {
+	status := StatusByName(PlannedStatus.Name)
+	if status == nil {
+		return nil
+	}
+	items := bck.FilteredActiveItems(NewBacklogItemsStatusCodeFilter(status.Code))
+	sorter.SortItemsByStatus(status, items)
+
+	// the current week isn't finished yet, so only full weeks are used
+	weeklyPoints := WeeklyFinishedPoints(bck, history, weekCount+1, now)[:weekCount]
+	return Forecast(items, weeklyPoints, runs, rand.New(rand.NewSource(1)))
+}
+
+func ForecastWeekStart(now time.Time, week int) time.Time {
+	return utils.WeekStart(now).AddDate(0, 0, 7*(week-1))
+}
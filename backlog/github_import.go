@@ -0,0 +1,95 @@
+package backlog
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/mreider/agilemarkdown/utils"
+	"io/ioutil"
+	"strings"
+	"time"
+)
+
+type gitHubIssue struct {
+	Number    int          `json:"number"`
+	Title     string       `json:"title"`
+	Body      string       `json:"body"`
+	State     string       `json:"state"`
+	CreatedAt time.Time    `json:"createdAt"`
+	Author    gitHubUser   `json:"author"`
+	Assignees []gitHubUser `json:"assignees"`
+	Labels    []struct {
+		Name string `json:"name"`
+	} `json:"labels"`
+	Milestone *struct {
+		Title string `json:"title"`
+	} `json:"milestone"`
+	Comments []struct {
+		Author    gitHubUser `json:"author"`
+		Body      string     `json:"body"`
+		CreatedAt time.Time  `json:"createdAt"`
+	} `json:"comments"`
+}
+
+type gitHubUser struct {
+	Login string `json:"login"`
+	Name  string `json:"name"`
+}
+
+// GitHubImporter reads JSON files of gh issue list --json.
+type GitHubImporter struct {
+}
+
+func (imp *GitHubImporter) Name() string {
+	return "github"
+}
+
+func (imp *GitHubImporter) Read(path string) ([]*ImportedItem, error) {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var issues []*gitHubIssue
+	if err := json.Unmarshal(content, &issues); err != nil {
+		return nil, err
+	}
+
+	items := make([]*ImportedItem, 0, len(issues))
+	for _, issue := range issues {
+		item := &ImportedItem{
+			Name:        fmt.Sprintf("issue-%d", issue.Number),
+			Title:       strings.TrimSpace(issue.Title),
+			State:       issue.State,
+			Author:      issue.Author.Login,
+			Description: strings.TrimSpace(strings.Replace(issue.Body, "\r\n", "\n", -1)),
+			Values:      make(map[string]string),
+		}
+		if !issue.CreatedAt.IsZero() {
+			item.Created = utils.GetTimestamp(issue.CreatedAt)
+		}
+		if len(issue.Assignees) > 0 {
+			item.Assigned = issue.Assignees[0].Login
+		}
+		for _, label := range issue.Labels {
+			item.Tags = append(item.Tags, label.Name)
+		}
+		if issue.Milestone != nil {
+			item.Values["milestone"] = issue.Milestone.Title
+		}
+		for _, comment := range issue.Comments {
+			item.Comments = append(item.Comments, &ImportedComment{
+				Author:  comment.Author.Login,
+				Created: utils.GetTimestamp(comment.CreatedAt),
+				Text:    strings.Replace(comment.Body, "\r\n", "\n", -1),
+			})
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+func (imp *GitHubImporter) DefaultStatus(state string) *BacklogItemStatus {
+	if strings.ToLower(state) == "closed" {
+		return guessStatus(state)
+	}
+	return InitialStatus()
+}
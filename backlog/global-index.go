@@ -54,6 +54,28 @@ func (index *GlobalIndex) UpdateBacklogs(overviews []*BacklogOverview, archives
 	index.Save()
 }
 
+func (index *GlobalIndex) UpdateSavedQueries(queries []*SavedQuery, baseDir string) {
+	queriesGroup := index.markdown.Group("Queries")
+	if len(queries) == 0 {
+		if queriesGroup != nil {
+			index.markdown.removeGroup(queriesGroup)
+			index.Save()
+		}
+		return
+	}
+
+	lines := make([]string, 0, len(queries))
+	for _, query := range queries {
+		lines = append(lines, MakeSavedQueryLink(query, baseDir)+"  ")
+	}
+	if queriesGroup == nil {
+		queriesGroup = &MarkdownGroup{title: "Queries", content: index.markdown}
+		index.markdown.addGroup(queriesGroup)
+	}
+	queriesGroup.ReplaceLines(lines)
+	index.Save()
+}
+
 func (index *GlobalIndex) UpdateLinks(rootDir string) {
 	links := []string{
 		MakeIndexLink(rootDir, filepath.Dir(index.markdown.contentPath)),
@@ -0,0 +1,313 @@
+package backlog
+
+import (
+	"github.com/mreider/agilemarkdown/git"
+	"gopkg.in/yaml.v2"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	historyMetadataKeys = []string{BacklogItemStatusMetadataKey, BacklogItemEstimateMetadataKey, BacklogItemAssignedMetadataKey, BacklogItemSprintMetadataKey}
+	metadataLineRe      = regexp.MustCompile(`^\s*([A-Za-z][\w-]*)\s*:\s*(.*?)\s*$`)
+)
+
+type BacklogHistory struct {
+	items       []*BacklogItemHistory
+	itemsByPath map[string]*BacklogItemHistory
+}
+
+type BacklogItemHistory struct {
+	states []*BacklogItemState
+}
+
+type BacklogItemState struct {
+	Date    time.Time
+	Author  string
+	Deleted bool
+	values  map[string]string
+}
+
+type BacklogItemChange struct {
+	Date     time.Time
+	Author   string
+	OldValue string
+	NewValue string
+}
+
+type BurnChartDay struct {
+	Date  time.Time
+	Scope float64
+	Done  float64
+}
+
+func LoadBacklogHistory(repo git.Repository, backlogDir string, items []*BacklogItem) (*BacklogHistory, error) {
+	backlogDir, _ = filepath.Abs(backlogDir)
+	topDir, err := repo.TopLevelDirectory()
+	if err != nil {
+		return nil, err
+	}
+	changes, err := repo.FileChanges(backlogDir)
+	if err != nil {
+		return nil, err
+	}
+	return NewBacklogHistory(topDir, changes, items, time.Now()), nil
+}
+
+func NewBacklogHistory(rootDir string, changes []*git.FileChange, items []*BacklogItem, now time.Time) *BacklogHistory {
+	history := &BacklogHistory{itemsByPath: make(map[string]*BacklogItemHistory)}
+	historiesByPath := make(map[string]*BacklogItemHistory)
+	for _, change := range changes {
+		itemName := strings.TrimSuffix(filepath.Base(change.Path), filepath.Ext(change.Path))
+		if filepath.Ext(change.Path) != ".md" || IsForbiddenItemName(itemName) {
+			continue
+		}
+		var itemHistory *BacklogItemHistory
+		if change.OldPath != "" {
+			itemHistory = historiesByPath[change.OldPath]
+			delete(historiesByPath, change.OldPath)
+		}
+		if itemHistory == nil {
+			itemHistory = historiesByPath[change.Path]
+		}
+		if itemHistory == nil {
+			itemHistory = &BacklogItemHistory{}
+			history.items = append(history.items, itemHistory)
+		}
+
+		if change.Deleted {
+			delete(historiesByPath, change.Path)
+			itemHistory.states = append(itemHistory.states, &BacklogItemState{Date: change.Date, Author: change.Author, Deleted: true})
+			continue
+		}
+		historiesByPath[change.Path] = itemHistory
+
+		var values map[string]string
+		if change.Content != nil {
+			// Only the metadata of the whole file counts, the same lines in the text or comments don't change the story.
+			item := NewBacklogItem(itemName, strings.Join(change.Content, "\n"))
+			values = make(map[string]string, len(historyMetadataKeys))
+			for _, key := range historyMetadataKeys {
+				values[key] = item.markdown.MetadataValue(key)
+			}
+		} else {
+			values = itemHistory.lastValues()
+			for _, line := range change.Removed {
+				if key, _, ok := parseHistoryMetadataLine(line); ok {
+					values[key] = ""
+				}
+			}
+			for _, line := range change.Added {
+				if key, value, ok := parseHistoryMetadataLine(line); ok {
+					values[key] = value
+				}
+			}
+		}
+		itemHistory.states = append(itemHistory.states, &BacklogItemState{Date: change.Date, Author: change.Author, values: values})
+	}
+
+	for path, itemHistory := range historiesByPath {
+		history.itemsByPath[filepath.Join(rootDir, filepath.FromSlash(path))] = itemHistory
+	}
+
+	for _, item := range items {
+		itemPath, _ := filepath.Abs(item.Path())
+		itemHistory := history.itemsByPath[itemPath]
+		if itemHistory == nil {
+			itemHistory = &BacklogItemHistory{}
+			history.items = append(history.items, itemHistory)
+			history.itemsByPath[itemPath] = itemHistory
+		}
+
+		values := make(map[string]string, len(historyMetadataKeys))
+		for _, key := range historyMetadataKeys {
+			values[key] = item.markdown.MetadataValue(key)
+		}
+		if !itemHistory.hasValues(values) {
+			date := now
+			if modified := item.Modified(); modified.Before(now) && (len(itemHistory.states) == 0 || modified.After(itemHistory.states[len(itemHistory.states)-1].Date)) {
+				date = modified
+			}
+			itemHistory.states = append(itemHistory.states, &BacklogItemState{Date: date, values: values})
+		}
+	}
+	return history
+}
+
+func parseHistoryMetadataLine(line string) (key, value string, ok bool) {
+	matches := metadataLineRe.FindStringSubmatch(line)
+	if matches == nil {
+		return "", "", false
+	}
+	for _, metadataKey := range historyMetadataKeys {
+		if strings.ToLower(metadataKey) == strings.ToLower(matches[1]) {
+			value := matches[2]
+			if strings.HasPrefix(value, `"`) || strings.HasPrefix(value, "'") {
+				var unquoted string
+				if yaml.Unmarshal([]byte(value), &unquoted) == nil {
+					value = unquoted
+				}
+			}
+			return metadataKey, value, true
+		}
+	}
+	return "", "", false
+}
+
+func (h *BacklogHistory) Items() []*BacklogItemHistory {
+	return h.items
+}
+
+func (h *BacklogHistory) Item(item *BacklogItem) *BacklogItemHistory {
+	itemPath, _ := filepath.Abs(item.Path())
+	return h.itemsByPath[itemPath]
+}
+
+func (h *BacklogHistory) FinishedDate(item *BacklogItem) time.Time {
+	if h != nil {
+		if itemHistory := h.Item(item); itemHistory != nil {
+			if finished, ok := itemHistory.FinishedDate(); ok {
+				return finished
+			}
+		}
+	}
+	return item.Modified()
+}
+
+func (h *BacklogHistory) BurnChart(from, to time.Time, sprint string) []*BurnChartDay {
+	from, to = dayStart(from), dayStart(to)
+	if today := dayStart(time.Now()); to.After(today) {
+		to = today
+	}
+
+	var result []*BurnChartDay
+	for day := from; !day.After(to); day = day.AddDate(0, 0, 1) {
+		dayEnd := day.AddDate(0, 0, 1).Add(-time.Nanosecond)
+		chartDay := &BurnChartDay{Date: day}
+		for _, itemHistory := range h.items {
+			state := itemHistory.StateAt(dayEnd)
+			if state == nil {
+				continue
+			}
+			if sprint != "" && strings.ToLower(state.Sprint()) != strings.ToLower(sprint) {
+				continue
+			}
+			chartDay.Scope += state.EstimatePoints()
+			if IsDoneStatusName(state.Status()) {
+				chartDay.Done += state.EstimatePoints()
+			}
+		}
+		result = append(result, chartDay)
+	}
+	return result
+}
+
+func (h *BacklogItemHistory) States() []*BacklogItemState {
+	return h.states
+}
+
+func (h *BacklogItemHistory) StateAt(moment time.Time) *BacklogItemState {
+	var result *BacklogItemState
+	for _, state := range h.states {
+		if state.Date.After(moment) {
+			break
+		}
+		result = state
+	}
+	if result != nil && result.Deleted {
+		return nil
+	}
+	return result
+}
+
+func (h *BacklogItemHistory) Changes(key string) []*BacklogItemChange {
+	var changes []*BacklogItemChange
+	var value string
+	for _, state := range h.states {
+		if state.Deleted {
+			continue
+		}
+		if newValue := state.values[key]; newValue != value {
+			changes = append(changes, &BacklogItemChange{Date: state.Date, Author: state.Author, OldValue: value, NewValue: newValue})
+			value = newValue
+		}
+	}
+	return changes
+}
+
+func (h *BacklogItemHistory) FinishedDate() (time.Time, bool) {
+	var finished time.Time
+	done := false
+	for _, change := range h.Changes(BacklogItemStatusMetadataKey) {
+		newDone := IsDoneStatusName(change.NewValue)
+		if newDone && !done {
+			finished = change.Date
+		}
+		done = newDone
+	}
+	return finished, done
+}
+
+func (h *BacklogItemHistory) StartedDate() (time.Time, bool) {
+	for _, change := range h.Changes(BacklogItemStatusMetadataKey) {
+		if IsStartedStatusName(change.NewValue) {
+			return change.Date, true
+		}
+	}
+	return time.Time{}, false
+}
+
+func (h *BacklogItemHistory) lastValues() map[string]string {
+	values := make(map[string]string, len(historyMetadataKeys))
+	if len(h.states) > 0 {
+		for key, value := range h.states[len(h.states)-1].values {
+			values[key] = value
+		}
+	}
+	return values
+}
+
+func (h *BacklogItemHistory) hasValues(values map[string]string) bool {
+	if len(h.states) == 0 || h.states[len(h.states)-1].Deleted {
+		return false
+	}
+	lastValues := h.states[len(h.states)-1].values
+	for key, value := range values {
+		if lastValues[key] != value {
+			return false
+		}
+	}
+	return true
+}
+
+func (s *BacklogItemState) Value(key string) string {
+	return s.values[key]
+}
+
+func (s *BacklogItemState) Status() string {
+	return s.values[BacklogItemStatusMetadataKey]
+}
+
+func (s *BacklogItemState) Estimate() string {
+	return s.values[BacklogItemEstimateMetadataKey]
+}
+
+func (s *BacklogItemState) EstimatePoints() float64 {
+	points, _ := strconv.ParseFloat(s.Estimate(), 64)
+	return points
+}
+
+func (s *BacklogItemState) Assigned() string {
+	return s.values[BacklogItemAssignedMetadataKey]
+}
+
+func (s *BacklogItemState) Sprint() string {
+	return s.values[BacklogItemSprintMetadataKey]
+}
+
+func dayStart(value time.Time) time.Time {
+	return time.Date(value.Year(), value.Month(), value.Day(), 0, 0, 0, 0, time.Local)
+}
@@ -0,0 +1,467 @@
+package backlog
+
+import (
+	"bufio"
+	"encoding/csv"
+	"fmt"
+	"github.com/mreider/agilemarkdown/users"
+	"github.com/mreider/agilemarkdown/utils"
+	"html"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+var (
+	startsFromCapitalLetter = regexp.MustCompile(`^[A-Z][a-z].*`)
+	spacesRe                = regexp.MustCompile(`\s+`)
+	delimiterRe             = regexp.MustCompile(`\s*,\s*`)
+	htmlBreakRe             = regexp.MustCompile(`(?i)<br\s*/?>|</p>|</li>|</h\d>`)
+	htmlTagRe               = regexp.MustCompile(`<[^>]*>`)
+	blankLinesRe            = regexp.MustCompile(`\n{3,}`)
+
+	Importers = []Importer{&PivotalImporter{}, &JiraImporter{}, &TrelloImporter{}, &GitHubImporter{}}
+)
+
+// Importer reads stories from a file exported by another tracker.
+type Importer interface {
+	Name() string
+	Read(path string) ([]*ImportedItem, error)
+	// DefaultStatus maps a state of the tracker when there is no configured mapping for it.
+	DefaultStatus(state string) *BacklogItemStatus
+}
+
+type ImportedItem struct {
+	// Backlog is set when the file holds the backlog of the story, e.g. a file exported by am export.
+	Backlog string
+	Name    string
+	Title   string
+	State   string
+	// Status is set when the file holds a status of agilemarkdown, it takes priority over State.
+	Status      string
+	Created     string
+	Modified    string
+	Author      string
+	Assigned    string
+	Estimate    string
+	Tags        []string
+	Description string
+	Comments    []*ImportedComment
+	Archived    bool
+	BlockedBy   []string
+	Blocks      []string
+	// Values holds the sprint, epic, type and custom field values by lowercase name.
+	Values map[string]string
+}
+
+type ImportedComment struct {
+	Author  string
+	Created string
+	Text    string
+}
+
+type ItemsImport struct {
+	backlogDir string
+	userList   *users.UserList
+	statuses   map[string]string
+	users      map[string]string
+}
+
+func ImporterByName(name string) Importer {
+	for _, importer := range Importers {
+		if strings.ToLower(name) == importer.Name() {
+			return importer
+		}
+	}
+	return nil
+}
+
+func ImporterNames() []string {
+	names := make([]string, 0, len(Importers))
+	for _, importer := range Importers {
+		names = append(names, importer.Name())
+	}
+	return names
+}
+
+// DetectImporter guesses the importer by the extension and the beginning of the file.
+func DetectImporter(path string) (Importer, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	head, err := bufio.NewReader(file).Peek(4096)
+	if err != nil && err != io.EOF && err != bufio.ErrBufferFull {
+		return nil, err
+	}
+	content := strings.TrimSpace(string(head))
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".csv":
+		firstLine := strings.ToLower(strings.SplitN(content, "\n", 2)[0])
+		if strings.Contains(firstLine, "issue key") || strings.Contains(firstLine, "summary") {
+			return &JiraImporter{}, nil
+		}
+		return &PivotalImporter{}, nil
+	case ".xml":
+		return &JiraImporter{}, nil
+	case ".json":
+		if strings.HasPrefix(content, "[") {
+			return &GitHubImporter{}, nil
+		}
+		return &TrelloImporter{}, nil
+	}
+	return nil, fmt.Errorf("can't detect the format of '%s', should be one of: %s", path, strings.Join(ImporterNames(), ", "))
+}
+
+func NewItemsImport(backlogDir string, userList *users.UserList) *ItemsImport {
+	return &ItemsImport{backlogDir: backlogDir, userList: userList}
+}
+
+// SetStatusMapping sets statuses (names or codes) for states of the tracker.
+func (imp *ItemsImport) SetStatusMapping(statuses map[string]string) {
+	imp.statuses = lowerKeys(statuses)
+}
+
+// SetUserMapping sets users of the user list for users of the tracker.
+func (imp *ItemsImport) SetUserMapping(users map[string]string) {
+	imp.users = lowerKeys(users)
+}
+
+func (imp *ItemsImport) Import(importer Importer, path string) (int, error) {
+	fields, err := LoadBacklogFields(imp.backlogDir)
+	if err != nil {
+		return 0, err
+	}
+	importedItems, err := importer.Read(path)
+	if err != nil {
+		return 0, err
+	}
+	var backlogNames []string
+	for _, importedItem := range importedItems {
+		if importedItem.Backlog != "" && !utils.ContainsStringIgnoreCase(backlogNames, importedItem.Backlog) {
+			backlogNames = append(backlogNames, importedItem.Backlog)
+		}
+	}
+	if len(backlogNames) > 1 {
+		return 0, fmt.Errorf("the file has stories of several backlogs (%s), they should be imported one backlog at a time", strings.Join(backlogNames, ", "))
+	}
+
+	created := 0
+	for _, importedItem := range importedItems {
+		ok, err := imp.createItemIfNotExists(importer, importedItem, fields)
+		if err != nil {
+			return created, err
+		}
+		if ok {
+			created++
+		}
+	}
+	return created, nil
+}
+
+func (imp *ItemsImport) createItemIfNotExists(importer Importer, importedItem *ImportedItem, fields []*BacklogField) (bool, error) {
+	itemName := importedItem.Name
+	if itemName == "" {
+		itemName = importedItemName(importedItem.Title)
+	} else {
+		if strings.ContainsAny(itemName, `/\`) || strings.Contains(itemName, "..") {
+			return false, fmt.Errorf("the imported item name '%s' isn't valid", importedItem.Name)
+		}
+		itemName = utils.GetValidFileName(itemName)
+	}
+	if itemName == "" || IsForbiddenItemName(itemName) {
+		return false, fmt.Errorf("the imported item name '%s' isn't valid", itemName)
+	}
+	itemPath := filepath.Join(imp.backlogDir, fmt.Sprintf("%s.md", itemName))
+	for _, path := range []string{itemPath, filepath.Join(imp.backlogDir, ArchiveDirectoryName, filepath.Base(itemPath))} {
+		_, err := os.Stat(path)
+		if err == nil {
+			fmt.Printf("The item '%s' already exists. Skipping.\n", itemName)
+			return false, nil
+		}
+		if !os.IsNotExist(err) {
+			return false, err
+		}
+	}
+
+	item, err := LoadBacklogItem(itemPath, fields...)
+	if err != nil {
+		return false, err
+	}
+
+	tagSet := make(map[string]bool)
+	var tags []string
+	for _, tag := range importedItem.Tags {
+		tag = spacesRe.ReplaceAllString(strings.TrimSpace(tag), "-")
+		if tag != "" && !tagSet[strings.ToLower(tag)] {
+			tagSet[strings.ToLower(tag)] = true
+			tags = append(tags, tag)
+		}
+	}
+
+	item.SetTitle(importedItem.Title)
+	item.SetCreated(importedItem.Created)
+	item.SetAuthor(imp.userName(importedItem.Author))
+	item.SetStatus(imp.status(importer, importedItem))
+	item.SetAssigned(imp.userName(importedItem.Assigned))
+	item.SetEstimate(importedItem.Estimate)
+	item.SetTags(tags)
+	item.SetDescription(imp.description(importedItem))
+	if importedItem.Archived {
+		item.SetArchived(true)
+	}
+	keys := map[string]string{
+		strings.ToLower(BacklogItemSprintMetadataKey): BacklogItemSprintMetadataKey,
+		strings.ToLower(BacklogItemEpicMetadataKey):   BacklogItemEpicMetadataKey,
+		strings.ToLower(BacklogItemTypeMetadataKey):   BacklogItemTypeMetadataKey,
+	}
+	for _, field := range fields {
+		keys[strings.ToLower(field.Name)] = field.Name
+	}
+	for name, value := range importedItem.Values {
+		if key, ok := keys[strings.ToLower(name)]; ok && strings.TrimSpace(value) != "" {
+			item.SetFieldValue(key, strings.TrimSpace(value))
+		}
+	}
+	if len(importedItem.BlockedBy) > 0 {
+		item.SetBlockedBy(importedItem.BlockedBy)
+	}
+	if len(importedItem.Blocks) > 0 {
+		item.SetBlocks(importedItem.Blocks)
+	}
+	if _, err := utils.ParseTimestamp(importedItem.Modified); err == nil {
+		item.markdown.SetMetadataValue(ModifiedMetadataKey, importedItem.Modified)
+		err = item.markdown.SaveAt(importedItem.Modified)
+		if err != nil {
+			return false, err
+		}
+	} else {
+		item.SetModified()
+		if err := item.Save(); err != nil {
+			return false, err
+		}
+	}
+	if importedItem.Archived {
+		return true, item.MoveToBacklogArchiveDirectory()
+	}
+	return true, nil
+}
+
+func (imp *ItemsImport) status(importer Importer, importedItem *ImportedItem) *BacklogItemStatus {
+	if status := StatusByName(importedItem.Status); status != nil {
+		return status
+	}
+	state := importedItem.State
+	if mapped, ok := imp.statuses[strings.ToLower(strings.TrimSpace(state))]; ok {
+		if status := StatusByName(mapped); status != nil {
+			return status
+		}
+		if status := StatusByCode(mapped); status != nil {
+			return status
+		}
+	}
+	if status := importer.DefaultStatus(state); status != nil {
+		return status
+	}
+	return InitialStatus()
+}
+
+func (imp *ItemsImport) user(name string) *users.User {
+	if mapped, ok := imp.users[strings.ToLower(name)]; ok {
+		name = mapped
+	}
+	if imp.userList == nil {
+		return nil
+	}
+	return imp.userList.User(name)
+}
+
+func (imp *ItemsImport) userName(name string) string {
+	name = utils.CollapseWhiteSpaces(name)
+	if name == "" {
+		return ""
+	}
+	if user := imp.user(name); user != nil {
+		return user.Name()
+	}
+	if mapped, ok := imp.users[strings.ToLower(name)]; ok {
+		return mapped
+	}
+	return name
+}
+
+func (imp *ItemsImport) userNick(name string) string {
+	name = utils.CollapseWhiteSpaces(name)
+	if user := imp.user(name); user != nil {
+		return user.Nick()
+	}
+	if mapped, ok := imp.users[strings.ToLower(name)]; ok {
+		name = mapped
+	}
+	if name == "" {
+		return "unknown"
+	}
+	return strings.Replace(name, " ", ".", -1)
+}
+
+// description appends the imported comments to the description. They are written as closed comments
+// so sync doesn't send them again.
+func (imp *ItemsImport) description(importedItem *ImportedItem) string {
+	description := importedItem.Description
+	if len(importedItem.Comments) == 0 {
+		return description
+	}
+
+	lines := []string{strings.TrimRight(description, "\n")}
+	hasCommentsTitle := false
+	for _, line := range strings.Split(description, "\n") {
+		if commentsTitleRe.MatchString(line) {
+			hasCommentsTitle = true
+		}
+	}
+	if !hasCommentsTitle {
+		lines = append(lines, "", "## Comments")
+	}
+	for _, comment := range importedItem.Comments {
+		header := fmt.Sprintf(" @%s", imp.userNick(comment.Author))
+		if comment.Created != "" {
+			header += fmt.Sprintf(" (%s)", comment.Created)
+		}
+		textLines := strings.Split(strings.TrimSpace(comment.Text), "\n")
+		lines = append(lines, "", fmt.Sprintf("%s %s", header, escapeCommentLine(textLines[0])))
+		for _, line := range textLines[1:] {
+			lines = append(lines, escapeCommentLine(line))
+		}
+	}
+	return strings.TrimLeft(strings.Join(lines, "\n"), "\n") + "\n"
+}
+
+func escapeCommentLine(line string) string {
+	line = strings.TrimRight(line, " \t\r")
+	trimmed := strings.TrimSpace(line)
+	if strings.HasPrefix(trimmed, "#") || strings.HasPrefix(trimmed, "@") {
+		return `\` + trimmed
+	}
+	return line
+}
+
+func importedItemName(title string) string {
+	itemName := utils.GetValidFileName(title)
+	if startsFromCapitalLetter.MatchString(itemName) {
+		itemName = strings.ToLower(itemName[0:1]) + itemName[1:]
+	}
+	return itemName
+}
+
+// guessStatus maps common state names of trackers to the configured statuses.
+func guessStatus(state string) *BacklogItemStatus {
+	if status := StatusByName(strings.TrimSpace(state)); status != nil {
+		return status
+	}
+	state = strings.ToLower(state)
+	containsAny := func(words ...string) bool {
+		for _, word := range words {
+			if strings.Contains(state, word) {
+				return true
+			}
+		}
+		return false
+	}
+	switch {
+	case containsAny("done", "closed", "resolved", "complete", "accepted", "finished", "fixed"):
+		if statuses := DoneStatuses(); len(statuses) > 0 {
+			return statuses[0]
+		}
+	case containsAny("progress", "doing", "review", "started", "testing", "qa"):
+		if statuses := StartedStatuses(); len(statuses) > 0 {
+			return statuses[0]
+		}
+	case containsAny("to do", "todo", "selected", "planned", "ready", "next", "open"):
+		if status := StatusByName(PlannedStatus.Name); status != nil {
+			return status
+		}
+	}
+	return InitialStatus()
+}
+
+func htmlToText(value string) string {
+	value = htmlBreakRe.ReplaceAllString(value, "$0\n")
+	value = htmlTagRe.ReplaceAllString(value, "")
+	value = html.UnescapeString(value)
+	lines := strings.Split(strings.Replace(value, "\r\n", "\n", -1), "\n")
+	for i := range lines {
+		lines[i] = strings.TrimSpace(lines[i])
+	}
+	return strings.TrimSpace(blankLinesRe.ReplaceAllString(strings.Join(lines, "\n"), "\n\n"))
+}
+
+func lowerKeys(values map[string]string) map[string]string {
+	result := make(map[string]string, len(values))
+	for key, value := range values {
+		result[strings.ToLower(strings.TrimSpace(key))] = value
+	}
+	return result
+}
+
+// csvTable is a CSV file where a header can be repeated, like Labels or Comment in Jira exports.
+type csvTable struct {
+	headers map[string][]int
+	rows    [][]string
+}
+
+func readCsvTable(path string) (*csvTable, error) {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	reader := csv.NewReader(strings.NewReader(strings.TrimPrefix(string(content), "\ufeff")))
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	table := &csvTable{headers: make(map[string][]int)}
+	if len(records) == 0 {
+		return table, nil
+	}
+	for i, header := range records[0] {
+		header = strings.ToLower(strings.TrimSpace(header))
+		table.headers[header] = append(table.headers[header], i)
+	}
+	table.rows = records[1:]
+	return table, nil
+}
+
+func (table *csvTable) hasHeader(header string) bool {
+	_, ok := table.headers[header]
+	return ok
+}
+
+func (table *csvTable) rawValue(row []string, header string) string {
+	for _, index := range table.headers[header] {
+		if index < len(row) {
+			return row[index]
+		}
+	}
+	return ""
+}
+
+func (table *csvTable) value(row []string, header string) string {
+	return strings.TrimSpace(table.rawValue(row, header))
+}
+
+func (table *csvTable) values(row []string, header string) []string {
+	var result []string
+	for _, index := range table.headers[header] {
+		if index < len(row) && strings.TrimSpace(row[index]) != "" {
+			result = append(result, strings.TrimSpace(row[index]))
+		}
+	}
+	return result
+}
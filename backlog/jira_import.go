@@ -0,0 +1,196 @@
+package backlog
+
+import (
+	"encoding/xml"
+	"github.com/mreider/agilemarkdown/utils"
+	"io/ioutil"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	jiraDateLayouts     = []string{"02/Jan/06 3:04 PM", "2006-01-02 15:04", "2/Jan/06 3:04 PM", "Mon, 2 Jan 2006 15:04:05 -0700"}
+	jiraCustomFieldRe   = regexp.MustCompile(`^custom field \((.+)\)$`)
+	jiraEstimateHeaders = []string{"story points", "story point estimate"}
+	jiraEpicHeaders     = []string{"epic link", "parent", "parent id"}
+	jiraColumns         = []string{"issue key", "summary", "status", "created", "reporter", "assignee", "labels",
+		"description", "comment", "issue type", "sprint"}
+)
+
+type jiraRss struct {
+	Items []*jiraXMLItem `xml:"channel>item"`
+}
+
+type jiraXMLItem struct {
+	Key          string                `xml:"key"`
+	Summary      string                `xml:"summary"`
+	Description  string                `xml:"description"`
+	Type         string                `xml:"type"`
+	Status       string                `xml:"status"`
+	Created      string                `xml:"created"`
+	Assignee     string                `xml:"assignee"`
+	Reporter     string                `xml:"reporter"`
+	Parent       string                `xml:"parent"`
+	Labels       []string              `xml:"labels>label"`
+	Comments     []*jiraXMLComment     `xml:"comments>comment"`
+	CustomFields []*jiraXMLCustomField `xml:"customfields>customfield"`
+}
+
+type jiraXMLComment struct {
+	Author  string `xml:"author,attr"`
+	Created string `xml:"created,attr"`
+	Text    string `xml:",chardata"`
+}
+
+type jiraXMLCustomField struct {
+	Name   string   `xml:"customfieldname"`
+	Values []string `xml:"customfieldvalues>customfieldvalue"`
+}
+
+// JiraImporter reads CSV and XML files exported from Jira issue search.
+type JiraImporter struct {
+}
+
+func (imp *JiraImporter) Name() string {
+	return "jira"
+}
+
+func (imp *JiraImporter) Read(path string) ([]*ImportedItem, error) {
+	if strings.ToLower(filepath.Ext(path)) == ".xml" {
+		return imp.readXML(path)
+	}
+	return imp.readCSV(path)
+}
+
+func (imp *JiraImporter) DefaultStatus(state string) *BacklogItemStatus {
+	return guessStatus(state)
+}
+
+func (imp *JiraImporter) readCSV(path string) ([]*ImportedItem, error) {
+	table, err := readCsvTable(path)
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]*ImportedItem, 0, len(table.rows))
+	for _, row := range table.rows {
+		item := &ImportedItem{
+			Name:        strings.ToLower(table.value(row, "issue key")),
+			Title:       table.value(row, "summary"),
+			State:       table.value(row, "status"),
+			Created:     jiraTimestamp(table.value(row, "created")),
+			Author:      table.value(row, "reporter"),
+			Assigned:    table.value(row, "assignee"),
+			Tags:        table.values(row, "labels"),
+			Description: strings.TrimSpace(table.rawValue(row, "description")),
+			Values:      make(map[string]string),
+		}
+		for header := range table.headers {
+			name := header
+			if matches := jiraCustomFieldRe.FindStringSubmatch(header); matches != nil {
+				name = matches[1]
+			}
+			value := table.value(row, header)
+			switch {
+			case value == "":
+			case utils.ContainsStringIgnoreCase(jiraEstimateHeaders, name):
+				item.Estimate = jiraEstimate(value)
+			case utils.ContainsStringIgnoreCase(jiraEpicHeaders, name):
+				item.Values[strings.ToLower(BacklogItemEpicMetadataKey)] = strings.ToLower(value)
+			case !utils.ContainsStringIgnoreCase(jiraColumns, header):
+				item.Values[name] = value
+			}
+		}
+		item.Values[strings.ToLower(BacklogItemTypeMetadataKey)] = strings.ToLower(table.value(row, "issue type"))
+		if sprints := table.values(row, "sprint"); len(sprints) > 0 {
+			item.Values[strings.ToLower(BacklogItemSprintMetadataKey)] = sprints[len(sprints)-1]
+		}
+		for _, comment := range table.values(row, "comment") {
+			parts := strings.SplitN(comment, ";", 3)
+			if len(parts) == 3 {
+				item.Comments = append(item.Comments, &ImportedComment{Created: jiraTimestamp(parts[0]), Author: parts[1], Text: parts[2]})
+			} else {
+				item.Comments = append(item.Comments, &ImportedComment{Text: comment})
+			}
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+func (imp *JiraImporter) readXML(path string) ([]*ImportedItem, error) {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var rss jiraRss
+	if err := xml.Unmarshal(content, &rss); err != nil {
+		return nil, err
+	}
+
+	items := make([]*ImportedItem, 0, len(rss.Items))
+	for _, xmlItem := range rss.Items {
+		assignee := strings.TrimSpace(xmlItem.Assignee)
+		if strings.ToLower(assignee) == "unassigned" {
+			assignee = ""
+		}
+		item := &ImportedItem{
+			Name:        strings.ToLower(strings.TrimSpace(xmlItem.Key)),
+			Title:       strings.TrimSpace(xmlItem.Summary),
+			State:       strings.TrimSpace(xmlItem.Status),
+			Created:     jiraTimestamp(xmlItem.Created),
+			Author:      strings.TrimSpace(xmlItem.Reporter),
+			Assigned:    assignee,
+			Tags:        xmlItem.Labels,
+			Description: htmlToText(xmlItem.Description),
+			Values:      make(map[string]string),
+		}
+		item.Values[strings.ToLower(BacklogItemTypeMetadataKey)] = strings.ToLower(strings.TrimSpace(xmlItem.Type))
+		if parent := strings.TrimSpace(xmlItem.Parent); parent != "" {
+			item.Values[strings.ToLower(BacklogItemEpicMetadataKey)] = strings.ToLower(parent)
+		}
+		for _, field := range xmlItem.CustomFields {
+			if len(field.Values) == 0 {
+				continue
+			}
+			value := strings.TrimSpace(field.Values[len(field.Values)-1])
+			switch {
+			case utils.ContainsStringIgnoreCase(jiraEstimateHeaders, field.Name):
+				item.Estimate = jiraEstimate(value)
+			case utils.ContainsStringIgnoreCase(jiraEpicHeaders, field.Name):
+				item.Values[strings.ToLower(BacklogItemEpicMetadataKey)] = strings.ToLower(value)
+			default:
+				item.Values[field.Name] = value
+			}
+		}
+		for _, comment := range xmlItem.Comments {
+			item.Comments = append(item.Comments, &ImportedComment{
+				Author:  comment.Author,
+				Created: jiraTimestamp(comment.Created),
+				Text:    htmlToText(comment.Text),
+			})
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+func jiraTimestamp(value string) string {
+	value = strings.TrimSpace(value)
+	for _, layout := range jiraDateLayouts {
+		if moment, err := time.Parse(layout, value); err == nil {
+			return utils.GetTimestamp(moment)
+		}
+	}
+	return value
+}
+
+func jiraEstimate(value string) string {
+	if estimate, err := strconv.ParseFloat(value, 64); err == nil {
+		return strconv.FormatFloat(estimate, 'f', -1, 64)
+	}
+	return value
+}
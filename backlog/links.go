@@ -7,6 +7,11 @@ import (
 	"strings"
 )
 
+type PageLink struct {
+	Title string
+	Path  string
+}
+
 func MakeItemLink(item *BacklogItem, baseDir string) string {
 	itemPath := item.markdown.contentPath
 	if itemPath == "" {
@@ -33,6 +38,10 @@ func MakeArchiveLink(archive *BacklogOverview, title string, baseDir string) str
 	return utils.MakeMarkdownLink(title, archivePath, baseDir)
 }
 
+func MakeSavedQueryLink(query *SavedQuery, baseDir string) string {
+	return utils.MakeMarkdownLink(query.Title(), query.Path(), baseDir)
+}
+
 func MakeIndexLink(rootDir, baseDir string) string {
 	return utils.MakeMarkdownLink("home", filepath.Join(rootDir, IndexFileName), baseDir)
 }
@@ -11,26 +11,30 @@ import (
 )
 
 const (
-	CreatedMetadataKey  = "Created"
-	ModifiedMetadataKey = "Modified"
+	CreatedMetadataKey   = "Created"
+	ModifiedMetadataKey  = "Modified"
+	frontMatterSeparator = "---"
 )
 
 var (
 	linksRe = regexp.MustCompile(`^(\[[^])]+]\([^])]+\)(\s*(\|\|)?\s*)?)+$`)
+
+	UseFrontMatter = false
 )
 
 type MarkdownContent struct {
 	contentPath      string
 	groupTitlePrefix string
 
-	isDirty  bool
-	title    string
-	header   string
-	links    string
-	metadata *MarkdownMetadata
-	groups   []*MarkdownGroup
-	freeText []string
-	footer   []string
+	isDirty     bool
+	frontMatter bool
+	title       string
+	header      string
+	links       string
+	metadata    *MarkdownMetadata
+	groups      []*MarkdownGroup
+	freeText    []string
+	footer      []string
 
 	HideEmptyGroups bool
 }
@@ -58,8 +62,19 @@ func LoadMarkdown(markdownPath string, metadataKeys []string, groupTitlePrefix s
 
 func NewMarkdown(data, markdownPath string, metadataKeys []string, groupTitlePrefix string, footerRe *regexp.Regexp) *MarkdownContent {
 	content := &MarkdownContent{contentPath: markdownPath, groupTitlePrefix: groupTitlePrefix, metadata: NewMarkdownMetadata(metadataKeys)}
-	if len(data) > 0 {
+	if len(data) == 0 {
+		content.frontMatter = UseFrontMatter
+	} else {
 		lines := strings.Split(data, "\n")
+		if frontMatterEnd := content.parseFrontMatter(lines); frontMatterEnd > 0 {
+			lines = append([]string{}, lines[frontMatterEnd:]...)
+			for len(lines) > 0 && strings.TrimSpace(lines[0]) == "" {
+				lines = lines[1:]
+			}
+			if len(lines) == 0 {
+				lines = []string{""}
+			}
+		}
 		metadataIndex := 0
 		if strings.HasPrefix(lines[0], "# ") {
 			content.title = strings.TrimSpace(strings.TrimPrefix(lines[0], "# "))
@@ -96,7 +111,14 @@ func NewMarkdown(data, markdownPath string, metadataKeys []string, groupTitlePre
 			}
 			metadataIndex++
 		}
-		parsed := content.metadata.ParseLines(lines[metadataIndex:]) + metadataIndex
+		parsed := metadataIndex
+		if !content.frontMatter {
+			parsed += content.metadata.ParseLines(lines[metadataIndex:])
+		} else {
+			for parsed < len(lines) && strings.TrimSpace(lines[parsed]) == "" {
+				parsed++
+			}
+		}
 
 		if groupTitlePrefix != "" {
 			var currentGroup *MarkdownGroup
@@ -136,14 +158,36 @@ func NewMarkdown(data, markdownPath string, metadataKeys []string, groupTitlePre
 	return content
 }
 
+func (content *MarkdownContent) parseFrontMatter(lines []string) int {
+	if strings.TrimSpace(lines[0]) != frontMatterSeparator {
+		return 0
+	}
+	for i := 1; i < len(lines); i++ {
+		line := strings.TrimSpace(lines[i])
+		if line == frontMatterSeparator || line == "..." {
+			if content.metadata.ParseFrontMatter(lines[1:i]) != nil {
+				return 0
+			}
+			content.frontMatter = true
+			return i + 1
+		}
+	}
+	return 0
+}
+
 func (content *MarkdownContent) Save() error {
+	return content.SaveAt(utils.GetCurrentTimestamp())
+}
+
+// SaveAt saves the changed content as modified at the timestamp, e.g. the modification time of an imported story.
+func (content *MarkdownContent) SaveAt(timestamp string) error {
 	if content.contentPath == "" {
 		return nil
 	}
 	if !content.isDirty {
 		return nil
 	}
-	data := content.Content(utils.GetCurrentTimestamp())
+	data := content.Content(timestamp)
 	err := ioutil.WriteFile(content.contentPath, data, 0644)
 	if err != nil {
 		return err
@@ -152,6 +196,19 @@ func (content *MarkdownContent) Save() error {
 	return nil
 }
 
+// SaveUnmodified saves the changed content keeping its modification time, e.g. when only the metadata format changes.
+// Content without timestamps gets the current time like Save does.
+func (content *MarkdownContent) SaveUnmodified() error {
+	modified := content.MetadataValue(ModifiedMetadataKey)
+	if modified == "" {
+		modified = content.MetadataValue(CreatedMetadataKey)
+	}
+	if modified == "" {
+		return content.Save()
+	}
+	return content.SaveAt(modified)
+}
+
 func (content *MarkdownContent) Content(timestamp string) []byte {
 	emptyCreated := content.MetadataValue(CreatedMetadataKey) == ""
 	if content.metadata.IsAllowedKey(CreatedMetadataKey) && emptyCreated {
@@ -165,6 +222,14 @@ func (content *MarkdownContent) Content(timestamp string) []byte {
 		}
 	}
 	result := bytes.NewBuffer(nil)
+	if content.frontMatter && !content.metadata.Empty() {
+		result.WriteString(frontMatterSeparator)
+		result.WriteString("\n")
+		result.WriteString(strings.Join(content.metadata.FrontMatterLines(), "\n"))
+		result.WriteString("\n")
+		result.WriteString(frontMatterSeparator)
+		result.WriteString("\n")
+	}
 	if content.title != "" {
 		result.WriteString(fmt.Sprintf("# %s", content.title))
 		result.WriteString("\n")
@@ -179,14 +244,22 @@ func (content *MarkdownContent) Content(timestamp string) []byte {
 		result.WriteString(content.links)
 		result.WriteString("\n")
 	}
-	if !content.metadata.Empty() {
+	freeText := content.freeText
+	if !content.frontMatter && !content.metadata.Empty() {
 		result.WriteString("\n")
 		result.WriteString(strings.Join(content.metadata.RawLines(), "\n"))
 		result.WriteString("\n")
+	} else if content.frontMatter {
+		for len(freeText) > 0 && strings.TrimSpace(freeText[0]) == "" {
+			freeText = freeText[1:]
+		}
+		if len(freeText) > 0 && (content.title != "" || content.header != "" || content.links != "") {
+			result.WriteString("\n")
+		}
 	}
-	for i, line := range content.freeText {
+	for i, line := range freeText {
 		result.WriteString(line)
-		if i < len(content.freeText)-1 {
+		if i < len(freeText)-1 {
 			result.WriteString("\n")
 		}
 	}
@@ -230,6 +303,38 @@ func (content *MarkdownContent) SetMetadataValue(key, value string) {
 	}
 }
 
+func (content *MarkdownContent) SetMetadataValues(key string, values []string) {
+	if content.metadata.SetValues(key, values) {
+		content.markDirty()
+	}
+}
+
+func (content *MarkdownContent) MetadataData(key string) interface{} {
+	return content.metadata.Data(key)
+}
+
+func (content *MarkdownContent) FrontMatter() bool {
+	return content.frontMatter
+}
+
+// SetFrontMatter switches the format of the metadata. It fails when the metadata can't be converted
+// to 'Key: value' lines without losing data.
+func (content *MarkdownContent) SetFrontMatter(frontMatter bool) error {
+	if content.frontMatter != frontMatter {
+		if !frontMatter {
+			if err := content.metadata.CheckRawLines(); err != nil {
+				return fmt.Errorf("%s: %v", content.contentPath, err)
+			}
+		}
+		content.frontMatter = frontMatter
+		if !frontMatter && len(content.freeText) > 0 && strings.TrimSpace(content.freeText[0]) != "" {
+			content.freeText = append([]string{""}, content.freeText...)
+		}
+		content.markDirty()
+	}
+	return nil
+}
+
 func (content *MarkdownContent) GroupCount() int {
 	return len(content.groups)
 }
@@ -249,6 +354,16 @@ func (content *MarkdownContent) addGroup(group *MarkdownGroup) {
 	content.markDirty()
 }
 
+func (content *MarkdownContent) removeGroup(group *MarkdownGroup) {
+	for i, g := range content.groups {
+		if g == group {
+			content.groups = append(content.groups[:i], content.groups[i+1:]...)
+			content.markDirty()
+			return
+		}
+	}
+}
+
 func (content *MarkdownContent) SetFreeText(freeText []string) {
 	if utils.AreEqualStrings(content.freeText, freeText) {
 		return
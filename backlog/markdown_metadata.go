@@ -2,6 +2,7 @@ package backlog
 
 import (
 	"fmt"
+	"gopkg.in/yaml.v2"
 	"strings"
 )
 
@@ -13,6 +14,7 @@ type MarkdownMetadata struct {
 type markdownMetadataItem struct {
 	key   string
 	value string
+	data  interface{}
 }
 
 func NewMarkdownMetadata(allowedKeys []string) *MarkdownMetadata {
@@ -33,6 +35,31 @@ func (m *MarkdownMetadata) RawLines() []string {
 	return result
 }
 
+// CheckRawLines returns an error for the first value which can't be written as a 'Key: value' line
+// without losing data, e.g. a nested map or a multi-line string of front matter.
+func (m *MarkdownMetadata) CheckRawLines() error {
+	for _, item := range m.items {
+		if strings.ContainsAny(item.value, "\r\n") {
+			return fmt.Errorf("the value of '%s' has several lines", item.key)
+		}
+		switch data := item.data.(type) {
+		case yaml.MapSlice:
+			return fmt.Errorf("the value of '%s' is a map", item.key)
+		case []interface{}:
+			for _, value := range data {
+				switch value.(type) {
+				case yaml.MapSlice, []interface{}:
+					return fmt.Errorf("the list '%s' has nested values", item.key)
+				}
+				if strings.ContainsAny(fmt.Sprint(value), " \t\r\n") {
+					return fmt.Errorf("the list '%s' has values with spaces", item.key)
+				}
+			}
+		}
+	}
+	return nil
+}
+
 func (m *MarkdownMetadata) IsAllowedKey(key string) bool {
 	return m.allowedKeys[strings.ToLower(key)]
 }
@@ -49,7 +76,10 @@ func (m *MarkdownMetadata) Value(key string) string {
 func (m *MarkdownMetadata) SetValue(key, value string) bool {
 	for _, item := range m.items {
 		if strings.ToLower(item.key) == strings.ToLower(key) {
-			item.value = value
+			if item.value != value {
+				item.value = value
+				item.data = nil
+			}
 			return true
 		}
 	}
@@ -58,11 +88,27 @@ func (m *MarkdownMetadata) SetValue(key, value string) bool {
 		return false
 	}
 
-	item := &markdownMetadataItem{key, value}
+	item := &markdownMetadataItem{key: key, value: value}
 	m.items = append(m.items, item)
 	return true
 }
 
+func (m *MarkdownMetadata) SetValues(key string, values []string) bool {
+	if !m.SetValue(key, strings.Join(values, " ")) {
+		return false
+	}
+	data := make([]interface{}, 0, len(values))
+	for _, value := range values {
+		data = append(data, value)
+	}
+	for _, item := range m.items {
+		if strings.ToLower(item.key) == strings.ToLower(key) {
+			item.data = data
+		}
+	}
+	return true
+}
+
 func (m *MarkdownMetadata) ParseLines(lines []string) int {
 	m.items = nil
 	parsed := 0
@@ -72,15 +118,15 @@ func (m *MarkdownMetadata) ParseLines(lines []string) int {
 			parsed++
 			continue
 		}
-		if !strings.Contains(trimmedLine, ":") { // TODO: multi-line metadata
+		if !strings.Contains(trimmedLine, ":") {
 			break
 		}
 		parts := strings.SplitN(trimmedLine, ":", 2)
 		var item *markdownMetadataItem
 		if len(parts) == 1 {
-			item = &markdownMetadataItem{strings.TrimSpace(parts[0]), ""}
+			item = &markdownMetadataItem{key: strings.TrimSpace(parts[0])}
 		} else {
-			item = &markdownMetadataItem{strings.TrimSpace(parts[0]), strings.TrimSpace(parts[1])}
+			item = &markdownMetadataItem{key: strings.TrimSpace(parts[0]), value: strings.TrimSpace(parts[1])}
 		}
 		m.items = append(m.items, item)
 		parsed++
@@ -96,6 +142,69 @@ func (m *MarkdownMetadata) ParseLines(lines []string) int {
 	return parsed
 }
 
+func (m *MarkdownMetadata) Data(key string) interface{} {
+	for _, item := range m.items {
+		if strings.ToLower(item.key) == strings.ToLower(key) {
+			if item.data != nil {
+				return item.data
+			}
+			return item.value
+		}
+	}
+	return nil
+}
+
+func (m *MarkdownMetadata) ParseFrontMatter(lines []string) error {
+	var data yaml.MapSlice
+	err := yaml.Unmarshal([]byte(strings.Join(lines, "\n")), &data)
+	if err != nil {
+		return err
+	}
+	m.items = nil
+	for _, entry := range data {
+		item := &markdownMetadataItem{key: fmt.Sprint(entry.Key)}
+		switch value := entry.Value.(type) {
+		case nil:
+		case yaml.MapSlice:
+			item.data = value
+		case []interface{}:
+			values := make([]string, 0, len(value))
+			for _, v := range value {
+				values = append(values, fmt.Sprint(v))
+			}
+			item.value = strings.Join(values, " ")
+			item.data = value
+		default:
+			item.value = fmt.Sprint(value)
+		}
+		m.items = append(m.items, item)
+	}
+	return nil
+}
+
+func (m *MarkdownMetadata) FrontMatterLines() []string {
+	data := make(yaml.MapSlice, 0, len(m.items))
+	for _, item := range m.items {
+		var value interface{} = item.value
+		if item.data != nil {
+			value = item.data
+		} else if item.value != "" {
+			var scalar interface{}
+			if err := yaml.Unmarshal([]byte(item.value), &scalar); err == nil {
+				switch scalar.(type) {
+				case int, float64, bool:
+					if fmt.Sprint(scalar) == item.value {
+						value = scalar
+					}
+				}
+			}
+		}
+		data = append(data, yaml.MapItem{Key: item.key, Value: value})
+	}
+	out, _ := yaml.Marshal(data)
+	return strings.Split(strings.TrimSuffix(string(out), "\n"), "\n")
+}
+
 func (m *MarkdownMetadata) Empty() bool {
 	return len(m.items) == 0
 }
@@ -0,0 +1,320 @@
+package backlog
+
+import (
+	"fmt"
+	"github.com/mreider/agilemarkdown/utils"
+	"path/filepath"
+	"strings"
+)
+
+var listMetadataKeys = []string{BacklogItemTagsMetadataKey, BacklogItemBlocksMetadataKey, BacklogItemBlockedByMetadataKey}
+
+type MergeConflict struct {
+	Field  string
+	Ours   string
+	Theirs string
+}
+
+func (conflict *MergeConflict) String() string {
+	return fmt.Sprintf("%s: ours '%s', theirs '%s'", conflict.Field, conflict.Ours, conflict.Theirs)
+}
+
+// MergeItemContent merges two versions of an item file changed from a common base.
+// Metadata is merged key by key, comments are united and the rest of the text is merged line by line.
+// When both sides change the same value, ours is kept and the conflict is returned.
+func MergeItemContent(base, ours, theirs string, fields []*BacklogField) (string, []*MergeConflict) {
+	metadataKeys := itemMetadataKeys(fields)
+	baseContent := NewMarkdown(base, "", metadataKeys, "", nil)
+	oursContent := NewMarkdown(ours, "", metadataKeys, "", nil)
+	theirsContent := NewMarkdown(theirs, "", metadataKeys, "", nil)
+
+	var conflicts []*MergeConflict
+	mergeValue := func(field, baseValue, oursValue, theirsValue string) string {
+		value, ok := merge3Value(baseValue, oursValue, theirsValue)
+		if !ok {
+			conflicts = append(conflicts, &MergeConflict{Field: field, Ours: oursValue, Theirs: theirsValue})
+		}
+		return value
+	}
+
+	result := oursContent
+	result.title = mergeValue("Title", baseContent.title, oursContent.title, theirsContent.title)
+	result.header = mergeValue("Header", baseContent.header, oursContent.header, theirsContent.header)
+
+	var keys []string
+	for _, content := range []*MarkdownContent{oursContent, theirsContent} {
+		for _, item := range content.metadata.items {
+			if !utils.ContainsStringIgnoreCase(keys, item.key) {
+				keys = append(keys, item.key)
+			}
+		}
+	}
+	var items []*markdownMetadataItem
+	modified := ""
+	for _, key := range keys {
+		baseItem, oursItem, theirsItem := baseContent.metadata.item(key), oursContent.metadata.item(key), theirsContent.metadata.item(key)
+		deletedValue := func(value string) bool {
+			return baseItem.key != "" && ((oursItem.key == "" && value == oursItem.value) || (theirsItem.key == "" && value == theirsItem.value))
+		}
+		switch {
+		case strings.ToLower(key) == strings.ToLower(ModifiedMetadataKey):
+			modified = latestTimestamp(oursItem.value, theirsItem.value)
+			items = append(items, &markdownMetadataItem{key: key, value: modified})
+		case utils.ContainsStringIgnoreCase(listMetadataKeys, key):
+			values := mergeValueSets(splitDependencies(baseItem.value), splitDependencies(oursItem.value), splitDependencies(theirsItem.value))
+			if deletedValue(strings.Join(values, " ")) {
+				continue
+			}
+			data := make([]interface{}, 0, len(values))
+			for _, value := range values {
+				data = append(data, value)
+			}
+			items = append(items, &markdownMetadataItem{key: key, value: strings.Join(values, " "), data: data})
+		default:
+			value := mergeValue(key, baseItem.value, oursItem.value, theirsItem.value)
+			switch {
+			case deletedValue(value):
+				// the key is deleted on one side and isn't changed on the other one
+			case theirsItem.key != "" && value == theirsItem.value && value != oursItem.value:
+				items = append(items, &markdownMetadataItem{key: key, value: value, data: theirsItem.data})
+			case oursItem.key != "":
+				items = append(items, oursItem)
+			default:
+				items = append(items, &markdownMetadataItem{key: key, value: value})
+			}
+		}
+	}
+	result.metadata.items = items
+
+	baseBefore, baseComments, baseAfter := splitItemComments(baseContent.freeText)
+	oursBefore, oursComments, oursAfter := splitItemComments(oursContent.freeText)
+	theirsBefore, theirsComments, theirsAfter := splitItemComments(theirsContent.freeText)
+	before, beforeConflict := utils.Merge3(baseBefore, oursBefore, theirsBefore)
+	after, afterConflict := utils.Merge3(baseAfter, oursAfter, theirsAfter)
+	if beforeConflict || afterConflict {
+		conflicts = append(conflicts, &MergeConflict{Field: "Description", Ours: "see the conflict markers", Theirs: "see the conflict markers"})
+	}
+	freeText := before
+	if oursComments != nil || theirsComments != nil {
+		commentsTitle := "## Comments"
+		for _, comments := range [][]string{oursComments, theirsComments} {
+			if len(comments) > 0 {
+				commentsTitle = comments[0]
+				break
+			}
+		}
+		for len(freeText) > 0 && strings.TrimSpace(freeText[len(freeText)-1]) == "" {
+			freeText = freeText[:len(freeText)-1]
+		}
+		freeText = append(freeText, "", commentsTitle, "")
+		for _, block := range mergeCommentBlocks(parseCommentBlocks(baseComments), parseCommentBlocks(oursComments), parseCommentBlocks(theirsComments)) {
+			freeText = append(freeText, strings.Split(block, "\n")...)
+			freeText = append(freeText, "")
+		}
+		freeText = append(freeText, after...)
+	}
+	result.freeText = freeText
+
+	if modified == "" {
+		modified = utils.GetCurrentTimestamp()
+	}
+	return string(result.Content(modified)), conflicts
+}
+
+func (m *MarkdownMetadata) item(key string) *markdownMetadataItem {
+	for _, item := range m.items {
+		if strings.ToLower(item.key) == strings.ToLower(key) {
+			return item
+		}
+	}
+	return &markdownMetadataItem{}
+}
+
+func merge3Value(base, ours, theirs string) (string, bool) {
+	switch {
+	case ours == theirs, theirs == base:
+		return ours, true
+	case ours == base:
+		return theirs, true
+	}
+	return ours, false
+}
+
+func latestTimestamp(timestamp1, timestamp2 string) string {
+	moment1, err1 := utils.ParseTimestamp(timestamp1)
+	moment2, err2 := utils.ParseTimestamp(timestamp2)
+	if err1 != nil || (err2 == nil && moment2.After(moment1)) {
+		return timestamp2
+	}
+	return timestamp1
+}
+
+// mergeValueSets keeps the values added on either side and drops the values removed on either side.
+func mergeValueSets(base, ours, theirs []string) []string {
+	var result []string
+	for _, values := range [][]string{ours, theirs} {
+		for _, value := range values {
+			removed := utils.ContainsStringIgnoreCase(base, value) && (!utils.ContainsStringIgnoreCase(ours, value) || !utils.ContainsStringIgnoreCase(theirs, value))
+			if !removed && !utils.ContainsStringIgnoreCase(result, value) {
+				result = append(result, value)
+			}
+		}
+	}
+	return result
+}
+
+// splitItemComments splits the free text into the text before the Comments section, the section itself with its title
+// and the text after it. The section is nil when there are no comments.
+func splitItemComments(freeText []string) (before, comments, after []string) {
+	start := -1
+	for i := len(freeText) - 1; i >= 0; i-- {
+		if commentsTitleRe.MatchString(freeText[i]) {
+			start = i
+			break
+		}
+	}
+	if start == -1 {
+		return freeText, nil, nil
+	}
+	finish := len(freeText)
+	for i := start + 1; i < len(freeText); i++ {
+		if strings.HasPrefix(freeText[i], "#") {
+			finish = i
+			break
+		}
+	}
+	return freeText[:start], freeText[start:finish], freeText[finish:]
+}
+
+// parseCommentBlocks returns the comments of the section, a block is the lines of a comment joined with "\n".
+func parseCommentBlocks(comments []string) []string {
+	var blocks [][]string
+	for i, line := range comments {
+		if i == 0 {
+			continue
+		}
+		if commentRe.MatchString(strings.TrimRight(line, " \t")) || (len(blocks) == 0 && strings.TrimSpace(line) != "") {
+			blocks = append(blocks, nil)
+		}
+		if len(blocks) > 0 {
+			blocks[len(blocks)-1] = append(blocks[len(blocks)-1], line)
+		}
+	}
+	result := make([]string, 0, len(blocks))
+	for _, lines := range blocks {
+		for len(lines) > 0 && strings.TrimSpace(lines[len(lines)-1]) == "" {
+			lines = lines[:len(lines)-1]
+		}
+		result = append(result, strings.Join(lines, "\n"))
+	}
+	return result
+}
+
+// mergeCommentBlocks merges the comments like utils.Merge3 merges lines, a comment is a unit. A comment changed on one
+// side only, e.g. marked as sent, takes that change, and a comment deleted on one side is deleted. Where both sides
+// changed the same comments, the comments of ours are followed by the new comments of theirs.
+func mergeCommentBlocks(base, ours, theirs []string) []string {
+	return utils.Merge3Func(base, ours, theirs, func(baseChunk, oursChunk, theirsChunk []string) []string {
+		deleted := withoutBlocks(baseChunk, theirsChunk)
+		added := withoutBlocks(withoutBlocks(theirsChunk, baseChunk), oursChunk)
+		return append(withoutBlocks(oursChunk, deleted), added...)
+	})
+}
+
+// withoutBlocks returns the blocks without the removed ones, a removed block is taken out once.
+func withoutBlocks(blocks, removed []string) []string {
+	counts := make(map[string]int)
+	for _, block := range removed {
+		counts[block]++
+	}
+	result := make([]string, 0, len(blocks))
+	for _, block := range blocks {
+		if counts[block] > 0 {
+			counts[block]--
+			continue
+		}
+		result = append(result, block)
+	}
+	return result
+}
+
+// MergeOverviewContent merges the order of stories in the groups of an overview changed on both sides.
+// The rest of the overview is taken from ours as sync rebuilds it from the stories.
+func MergeOverviewContent(base, ours, theirs string) string {
+	metadataKeys := []string{CreatedMetadataKey, ModifiedMetadataKey}
+	baseContent := NewMarkdown(base, "", metadataKeys, "### ", OverviewFooterRe)
+	oursContent := NewMarkdown(ours, "", metadataKeys, "### ", OverviewFooterRe)
+	theirsContent := NewMarkdown(theirs, "", metadataKeys, "### ", OverviewFooterRe)
+
+	groupLines := func(content *MarkdownContent, title string) []string {
+		if group := content.Group(title); group != nil {
+			return group.lines
+		}
+		return nil
+	}
+	for _, group := range theirsContent.groups {
+		if oursContent.Group(group.title) == nil {
+			oursContent.addGroup(&MarkdownGroup{content: oursContent, title: group.title, lines: group.lines})
+		}
+	}
+	for _, group := range oursContent.groups {
+		if group.title == ClarificationsTitle {
+			continue
+		}
+		group.lines = mergeOverviewGroupLines(groupLines(baseContent, group.title), group.lines, groupLines(theirsContent, group.title))
+	}
+
+	modified := latestTimestamp(oursContent.MetadataValue(ModifiedMetadataKey), theirsContent.MetadataValue(ModifiedMetadataKey))
+	if modified == "" {
+		modified = utils.GetCurrentTimestamp()
+	}
+	return string(oursContent.Content(modified))
+}
+
+func mergeOverviewGroupLines(base, ours, theirs []string) []string {
+	itemLines := make(map[string]string)
+	itemNames := func(lines []string) []string {
+		var names []string
+		for _, line := range lines {
+			if name := overviewLineItemName(line); name != "" {
+				names = append(names, name)
+				if _, ok := itemLines[name]; !ok {
+					itemLines[name] = line
+				}
+			}
+		}
+		return names
+	}
+	oursNames, theirsNames := itemNames(ours), itemNames(theirs)
+	mergedNames, _ := utils.Merge3(itemNames(base), oursNames, theirsNames)
+
+	headerLines := ours
+	if len(oursNames) == 0 {
+		headerLines = theirs
+	}
+	var result []string
+	for _, line := range headerLines {
+		if overviewLineItemName(line) != "" {
+			break
+		}
+		result = append(result, line)
+	}
+	added := make(map[string]bool)
+	for _, name := range mergedNames {
+		if name == utils.MergeOursMarker || name == utils.MergeSeparator || name == utils.MergeTheirsMarker || added[name] {
+			continue
+		}
+		added[name] = true
+		result = append(result, itemLines[name])
+	}
+	return result
+}
+
+func overviewLineItemName(line string) string {
+	matches := overviewItemRe.FindStringSubmatch(line)
+	if len(matches) == 0 {
+		return ""
+	}
+	itemName := filepath.Base(matches[1])
+	return strings.TrimSuffix(itemName, filepath.Ext(itemName))
+}
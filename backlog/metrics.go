@@ -0,0 +1,159 @@
+package backlog
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"time"
+)
+
+type ItemMetrics struct {
+	Item     *BacklogItem
+	Created  time.Time
+	Started  time.Time
+	Finished time.Time
+}
+
+type TimeStats struct {
+	Count   int
+	Average float64
+	Median  float64
+	P85     float64
+}
+
+type MetricsRow struct {
+	Group     string
+	Items     int
+	LeadTime  TimeStats
+	CycleTime TimeStats
+}
+
+func NewItemsMetrics(items []*BacklogItem, history *BacklogHistory) []*ItemMetrics {
+	result := make([]*ItemMetrics, 0, len(items))
+	for _, item := range items {
+		if !IsDoneStatusName(item.Status()) {
+			continue
+		}
+		metrics := &ItemMetrics{Item: item, Created: item.Created(), Finished: history.FinishedDate(item)}
+		if history != nil {
+			if itemHistory := history.Item(item); itemHistory != nil {
+				if started, ok := itemHistory.StartedDate(); ok && !started.After(metrics.Finished) {
+					metrics.Started = started
+				}
+				if states := itemHistory.States(); metrics.Created.IsZero() && len(states) > 0 {
+					metrics.Created = states[0].Date
+				}
+			}
+		}
+		if metrics.Finished.IsZero() {
+			continue
+		}
+		result = append(result, metrics)
+	}
+	return result
+}
+
+func (m *ItemMetrics) HasLeadTime() bool {
+	return !m.Created.IsZero() && !m.Created.After(m.Finished)
+}
+
+func (m *ItemMetrics) LeadTime() float64 {
+	return m.Finished.Sub(m.Created).Hours() / 24
+}
+
+func (m *ItemMetrics) HasCycleTime() bool {
+	return !m.Started.IsZero()
+}
+
+func (m *ItemMetrics) CycleTime() float64 {
+	return m.Finished.Sub(m.Started).Hours() / 24
+}
+
+func NewMetricsRow(group string, metrics []*ItemMetrics) *MetricsRow {
+	var leadTimes, cycleTimes []float64
+	for _, m := range metrics {
+		if m.HasLeadTime() {
+			leadTimes = append(leadTimes, m.LeadTime())
+		}
+		if m.HasCycleTime() {
+			cycleTimes = append(cycleTimes, m.CycleTime())
+		}
+	}
+	return &MetricsRow{Group: group, Items: len(metrics), LeadTime: NewTimeStats(leadTimes), CycleTime: NewTimeStats(cycleTimes)}
+}
+
+func MetricsRowsByUser(metrics []*ItemMetrics) []*MetricsRow {
+	return metricsRowsByGroup(metrics, func(m *ItemMetrics) []string {
+		if m.Item.Assigned() == "" {
+			return nil
+		}
+		return []string{m.Item.Assigned()}
+	})
+}
+
+func MetricsRowsByTag(metrics []*ItemMetrics) []*MetricsRow {
+	return metricsRowsByGroup(metrics, func(m *ItemMetrics) []string {
+		return m.Item.Tags()
+	})
+}
+
+func metricsRowsByGroup(metrics []*ItemMetrics, groups func(m *ItemMetrics) []string) []*MetricsRow {
+	groupMetrics := make(map[string][]*ItemMetrics)
+	groupNames := make(map[string]string)
+	for _, m := range metrics {
+		for _, group := range groups(m) {
+			key := strings.ToLower(group)
+			if _, ok := groupNames[key]; !ok {
+				groupNames[key] = group
+			}
+			groupMetrics[key] = append(groupMetrics[key], m)
+		}
+	}
+	keys := make([]string, 0, len(groupMetrics))
+	for key := range groupMetrics {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	result := make([]*MetricsRow, 0, len(keys))
+	for _, key := range keys {
+		result = append(result, NewMetricsRow(groupNames[key], groupMetrics[key]))
+	}
+	return result
+}
+
+func NewTimeStats(values []float64) TimeStats {
+	if len(values) == 0 {
+		return TimeStats{}
+	}
+	sorted := make([]float64, len(values))
+	copy(sorted, values)
+	sort.Float64s(sorted)
+
+	var sum float64
+	for _, value := range sorted {
+		sum += value
+	}
+	return TimeStats{
+		Count:   len(sorted),
+		Average: sum / float64(len(sorted)),
+		Median:  median(sorted),
+		P85:     percentile(sorted, 0.85),
+	}
+}
+
+func median(sorted []float64) float64 {
+	middle := len(sorted) / 2
+	if len(sorted)%2 == 0 {
+		return (sorted[middle-1] + sorted[middle]) / 2
+	}
+	return sorted[middle]
+}
+
+func percentile(sorted []float64, p float64) float64 {
+	index := int(math.Ceil(p*float64(len(sorted)))) - 1
+	if index < 0 {
+		index = 0
+	}
+	return sorted[index]
+}
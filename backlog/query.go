@@ -0,0 +1,316 @@
+package backlog
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	QueryMeUser      = "@me"
+	QueryArchivedKey = "archived"
+)
+
+var queryTermRe = regexp.MustCompile(`^([A-Za-z][\w-]*)(:|!=|>=|<=|=|>|<)(.*)$`)
+
+type queryParser struct {
+	tokens []string
+	pos    int
+	fields []*BacklogField
+	me     []string
+}
+
+// ParseItemsQuery compiles a query like `status:doing AND (tag:ui OR tag:api) AND points>=3`
+// to a filter. Terms next to each other are joined with AND.
+func ParseItemsQuery(query string, fields []*BacklogField, me ...string) (BacklogItemsFilter, error) {
+	tokens, err := tokenizeQuery(query)
+	if err != nil {
+		return nil, err
+	}
+	if len(tokens) == 0 {
+		return &BacklogItemsTrueFilter{}, nil
+	}
+	p := &queryParser{tokens: tokens, fields: fields, me: me}
+	filter, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.tokens) {
+		return nil, fmt.Errorf("unexpected '%s'", p.tokens[p.pos])
+	}
+	return filter, nil
+}
+
+// QueryHasKey reports whether a term of the query uses the key, e.g. `archived` to look at archived stories too.
+func QueryHasKey(query, key string) bool {
+	tokens, err := tokenizeQuery(query)
+	if err != nil {
+		return false
+	}
+	for _, token := range tokens {
+		matches := queryTermRe.FindStringSubmatch(strings.TrimPrefix(token, "-"))
+		if matches != nil && strings.ToLower(matches[1]) == strings.ToLower(key) {
+			return true
+		}
+	}
+	return false
+}
+
+func tokenizeQuery(query string) ([]string, error) {
+	var tokens []string
+	var token []rune
+	inQuotes := false
+	flush := func() {
+		if len(token) > 0 {
+			tokens = append(tokens, string(token))
+			token = nil
+		}
+	}
+	for _, r := range query {
+		switch {
+		case r == '"':
+			inQuotes = !inQuotes
+			token = append(token, r)
+		case inQuotes:
+			token = append(token, r)
+		case r == '(' || r == ')':
+			flush()
+			tokens = append(tokens, string(r))
+		case r == ' ' || r == '\t' || r == '\n':
+			flush()
+		default:
+			token = append(token, r)
+		}
+	}
+	if inQuotes {
+		return nil, fmt.Errorf("unclosed quote in '%s'", query)
+	}
+	flush()
+	return tokens, nil
+}
+
+func (p *queryParser) peek() string {
+	if p.pos < len(p.tokens) {
+		return p.tokens[p.pos]
+	}
+	return ""
+}
+
+func (p *queryParser) parseOr() (BacklogItemsFilter, error) {
+	filter, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	if strings.ToUpper(p.peek()) != "OR" {
+		return filter, nil
+	}
+	orFilter := &BacklogItemsOrFilter{}
+	orFilter.Or(filter)
+	for strings.ToUpper(p.peek()) == "OR" {
+		p.pos++
+		filter, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		orFilter.Or(filter)
+	}
+	return orFilter, nil
+}
+
+func (p *queryParser) parseAnd() (BacklogItemsFilter, error) {
+	andFilter := &BacklogItemsAndFilter{}
+	count := 0
+	for {
+		token := p.peek()
+		if token == "" || token == ")" || strings.ToUpper(token) == "OR" {
+			break
+		}
+		if strings.ToUpper(token) == "AND" {
+			if count == 0 {
+				return nil, fmt.Errorf("unexpected 'AND'")
+			}
+			p.pos++
+			continue
+		}
+		filter, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		andFilter.And(filter)
+		count++
+	}
+	if count == 0 {
+		if token := p.peek(); token != "" {
+			return nil, fmt.Errorf("unexpected '%s'", token)
+		}
+		return nil, fmt.Errorf("unexpected end of the query")
+	}
+	return andFilter, nil
+}
+
+func (p *queryParser) parseUnary() (BacklogItemsFilter, error) {
+	token := p.peek()
+	switch {
+	case strings.ToUpper(token) == "NOT":
+		p.pos++
+		filter, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return NewBacklogItemsNotFilter(filter), nil
+	case token == "(":
+		p.pos++
+		filter, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if p.peek() != ")" {
+			return nil, fmt.Errorf("')' expected")
+		}
+		p.pos++
+		return filter, nil
+	case strings.HasPrefix(token, "-") && len(token) > 1:
+		p.tokens[p.pos] = token[1:]
+		filter, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return NewBacklogItemsNotFilter(filter), nil
+	default:
+		p.pos++
+		return p.parseTerm(token)
+	}
+}
+
+func (p *queryParser) parseTerm(term string) (BacklogItemsFilter, error) {
+	matches := queryTermRe.FindStringSubmatch(term)
+	if matches == nil {
+		return NewBacklogItemsTitleFilter(unquoteQueryValue(term)), nil
+	}
+	key, op, value := strings.ToLower(matches[1]), matches[2], unquoteQueryValue(matches[3])
+	if op == ":" {
+		op = "="
+	}
+	isEquality := op == "=" || op == "!="
+
+	var filter BacklogItemsFilter
+	switch {
+	case key == "status" && isEquality:
+		status := StatusByName(value)
+		if status == nil {
+			status = StatusByCode(value)
+		}
+		if status == nil {
+			return nil, fmt.Errorf("unknown status '%s'", value)
+		}
+		filter = NewBacklogItemsStatusCodeFilter(status.Code)
+	case (key == "tag" || key == "tags") && isEquality:
+		filter = &tagFilter{tag: strings.ToLower(value)}
+	case (key == "assigned" || key == "user") && isEquality:
+		if strings.ToLower(value) == QueryMeUser && len(p.me) == 0 {
+			return nil, fmt.Errorf("%s can't be used here", QueryMeUser)
+		}
+		filter = p.assignedFilter(value)
+	case key == "sprint" && isEquality:
+		filter = NewBacklogItemsSprintFilter(value)
+	case key == "title" && isEquality:
+		filter = NewBacklogItemsTitleFilter(value)
+	case (key == "epic" || key == "author" || key == "type") && isEquality:
+		filter = NewBacklogItemsFieldFilter(&BacklogField{Name: strings.Title(key), Type: FieldTypeString}, value)
+	case key == QueryArchivedKey && isEquality:
+		archived, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, fmt.Errorf("archived should be true or false")
+		}
+		if archived {
+			filter = &BacklogItemsArchivedFilter{}
+		} else {
+			filter = &BacklogItemsActiveFilter{}
+		}
+	case key == "points" || key == "estimate":
+		points, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%s should be a number", key)
+		}
+		return NewBacklogItemsCompareFilter(func(item *BacklogItem) (float64, bool) {
+			return item.EstimatePoints(), true
+		}, op, points), nil
+	case key == "created" || key == "modified":
+		date, err := time.ParseInLocation(FieldDateLayout, value, time.Local)
+		if err != nil {
+			return nil, fmt.Errorf("%s should be a date in YYYY-MM-DD format", key)
+		}
+		return NewBacklogItemsCompareFilter(func(item *BacklogItem) (float64, bool) {
+			itemDate := item.Created()
+			if key == "modified" {
+				itemDate = item.Modified()
+			}
+			return float64(dayStart(itemDate).Unix()), !itemDate.IsZero()
+		}, op, float64(date.Unix())), nil
+	default:
+		field := FieldByName(p.fields, key)
+		if field == nil {
+			return nil, fmt.Errorf("unknown key '%s'", matches[1])
+		}
+		if isEquality {
+			filter = NewBacklogItemsFieldFilter(field, value)
+			break
+		}
+		return p.fieldCompareFilter(field, op, value)
+	}
+	if filter == nil {
+		return nil, fmt.Errorf("'%s' can't be used with %s", op, key)
+	}
+	if op == "!=" {
+		return NewBacklogItemsNotFilter(filter), nil
+	}
+	return filter, nil
+}
+
+func (p *queryParser) assignedFilter(user string) BacklogItemsFilter {
+	if user == "" {
+		return NewBacklogItemsFieldFilter(&BacklogField{Name: BacklogItemAssignedMetadataKey, Type: FieldTypeString}, "")
+	}
+	if strings.ToLower(user) != QueryMeUser {
+		return NewBacklogItemsAssignedFilter(user)
+	}
+	filter := &BacklogItemsOrFilter{}
+	for _, me := range p.me {
+		if me != "" {
+			filter.Or(NewBacklogItemsAssignedFilter(me))
+		}
+	}
+	return filter
+}
+
+func (p *queryParser) fieldCompareFilter(field *BacklogField, op, value string) (BacklogItemsFilter, error) {
+	switch field.Type {
+	case FieldTypeNumber:
+		number, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%s should be a number", field.Name)
+		}
+		return NewBacklogItemsCompareFilter(func(item *BacklogItem) (float64, bool) {
+			return item.FieldNumber(field.Name)
+		}, op, number), nil
+	case FieldTypeDate:
+		date, err := time.Parse(FieldDateLayout, value)
+		if err != nil {
+			return nil, fmt.Errorf("%s should be a date in YYYY-MM-DD format", field.Name)
+		}
+		return NewBacklogItemsCompareFilter(func(item *BacklogItem) (float64, bool) {
+			itemDate, ok := item.FieldDate(field.Name)
+			return float64(itemDate.Unix()), ok
+		}, op, float64(date.Unix())), nil
+	}
+	return nil, fmt.Errorf("'%s' can't be used with %s", op, field.Name)
+}
+
+func unquoteQueryValue(value string) string {
+	if len(value) >= 2 && strings.HasPrefix(value, `"`) && strings.HasSuffix(value, `"`) {
+		return value[1 : len(value)-1]
+	}
+	return value
+}
@@ -0,0 +1,106 @@
+package backlog
+
+import (
+	"github.com/mreider/agilemarkdown/utils"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+const (
+	SavedQueryMetadataKey     = "Query"
+	SavedQueryUserMetadataKey = "User"
+)
+
+type SavedQuery struct {
+	name     string
+	markdown *MarkdownContent
+}
+
+func LoadSavedQuery(queryPath string) (*SavedQuery, error) {
+	markdown, err := LoadMarkdown(queryPath, []string{SavedQueryMetadataKey, SavedQueryUserMetadataKey}, "", nil)
+	if err != nil {
+		return nil, err
+	}
+	name := filepath.Base(queryPath)
+	name = strings.TrimSuffix(name, filepath.Ext(name))
+	return &SavedQuery{name, markdown}, nil
+}
+
+func LoadSavedQueries(queriesDir string) ([]*SavedQuery, error) {
+	infos, err := ioutil.ReadDir(queriesDir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	queries := make([]*SavedQuery, 0, len(infos))
+	for _, info := range infos {
+		if info.IsDir() || !strings.HasSuffix(info.Name(), ".md") {
+			continue
+		}
+		query, err := LoadSavedQuery(filepath.Join(queriesDir, info.Name()))
+		if err != nil {
+			return nil, err
+		}
+		queries = append(queries, query)
+	}
+	sort.SliceStable(queries, func(i, j int) bool {
+		return strings.ToLower(queries[i].Title()) < strings.ToLower(queries[j].Title())
+	})
+	return queries, nil
+}
+
+func (query *SavedQuery) Save() error {
+	return query.markdown.Save()
+}
+
+func (query *SavedQuery) Name() string {
+	return query.name
+}
+
+func (query *SavedQuery) Path() string {
+	return query.markdown.contentPath
+}
+
+func (query *SavedQuery) Title() string {
+	if query.markdown.Title() == "" {
+		return query.name
+	}
+	return query.markdown.Title()
+}
+
+func (query *SavedQuery) Query() string {
+	return query.markdown.MetadataValue(SavedQueryMetadataKey)
+}
+
+// User is the user '@me' stands for in the query. A saved query is shared, so it has no current user.
+func (query *SavedQuery) User() string {
+	return query.markdown.MetadataValue(SavedQueryUserMetadataKey)
+}
+
+func (query *SavedQuery) Filter(fields []*BacklogField) (BacklogItemsFilter, error) {
+	if user := query.User(); user != "" {
+		return ParseItemsQuery(query.Query(), fields, user)
+	}
+	return ParseItemsQuery(query.Query(), fields)
+}
+
+func (query *SavedQuery) Update(lines []string) {
+	query.markdown.SetFreeText(lines)
+	query.Save()
+}
+
+func (query *SavedQuery) UpdateLinks(rootDir string) {
+	links := []string{
+		MakeIndexLink(rootDir, filepath.Dir(query.markdown.contentPath)),
+		MakeIdeasLink(rootDir, filepath.Dir(query.markdown.contentPath)),
+		MakeTagsLink(rootDir, filepath.Dir(query.markdown.contentPath)),
+	}
+	query.markdown.SetLinks(utils.JoinMarkdownLinks(links...))
+	query.Save()
+}
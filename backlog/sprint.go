@@ -0,0 +1,202 @@
+package backlog
+
+import (
+	"fmt"
+	"github.com/mreider/agilemarkdown/utils"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	SprintGoalMetadataKey  = "Goal"
+	SprintStartMetadataKey = "Start"
+	SprintEndMetadataKey   = "End"
+	SprintStateMetadataKey = "State"
+
+	SprintStatePlanned = "planned"
+	SprintStateActive  = "active"
+	SprintStateClosed  = "closed"
+
+	SprintDateLayout = "2006-01-02"
+)
+
+type Sprint struct {
+	name     string
+	markdown *MarkdownContent
+}
+
+func LoadSprint(sprintPath string) (*Sprint, error) {
+	markdown, err := LoadMarkdown(sprintPath, []string{
+		CreatedMetadataKey, ModifiedMetadataKey, SprintGoalMetadataKey,
+		SprintStartMetadataKey, SprintEndMetadataKey, SprintStateMetadataKey}, "", nil)
+	if err != nil {
+		return nil, err
+	}
+	name := filepath.Base(sprintPath)
+	name = strings.TrimSuffix(name, filepath.Ext(name))
+	return &Sprint{name, markdown}, nil
+}
+
+func LoadSprints(sprintsDir string) ([]*Sprint, error) {
+	infos, err := ioutil.ReadDir(sprintsDir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	sprints := make([]*Sprint, 0, len(infos))
+	for _, info := range infos {
+		if info.IsDir() || !strings.HasSuffix(info.Name(), ".md") {
+			continue
+		}
+		sprint, err := LoadSprint(filepath.Join(sprintsDir, info.Name()))
+		if err != nil {
+			return nil, err
+		}
+		sprints = append(sprints, sprint)
+	}
+	sort.SliceStable(sprints, func(i, j int) bool {
+		if !sprints[i].Start().Equal(sprints[j].Start()) {
+			return sprints[i].Start().Before(sprints[j].Start())
+		}
+		return sprints[i].Name() < sprints[j].Name()
+	})
+	return sprints, nil
+}
+
+func ActiveSprint(sprints []*Sprint) *Sprint {
+	for _, sprint := range sprints {
+		if sprint.State() == SprintStateActive {
+			return sprint
+		}
+	}
+	return nil
+}
+
+func NextPlannedSprint(sprints []*Sprint, current *Sprint) *Sprint {
+	for _, sprint := range sprints {
+		if sprint == current || sprint.State() != SprintStatePlanned {
+			continue
+		}
+		if current == nil || !sprint.Start().Before(current.Start()) {
+			return sprint
+		}
+	}
+	return nil
+}
+
+func SprintByName(sprints []*Sprint, name string) *Sprint {
+	name = strings.ToLower(name)
+	for _, sprint := range sprints {
+		if strings.ToLower(sprint.Name()) == name {
+			return sprint
+		}
+	}
+	return nil
+}
+
+func (sprint *Sprint) Save() error {
+	return sprint.markdown.Save()
+}
+
+func (sprint *Sprint) SaveUnmodified() error {
+	return sprint.markdown.SaveUnmodified()
+}
+
+func (sprint *Sprint) Name() string {
+	return sprint.name
+}
+
+func (sprint *Sprint) Path() string {
+	return sprint.markdown.contentPath
+}
+
+func (sprint *Sprint) SetFrontMatter(frontMatter bool) error {
+	return sprint.markdown.SetFrontMatter(frontMatter)
+}
+
+func (sprint *Sprint) Title() string {
+	if sprint.markdown.Title() == "" {
+		return sprint.name
+	}
+	return sprint.markdown.Title()
+}
+
+func (sprint *Sprint) SetTitle(title string) {
+	sprint.markdown.SetTitle(title)
+}
+
+func (sprint *Sprint) SetCreated() {
+	sprint.markdown.SetMetadataValue(CreatedMetadataKey, "")
+}
+
+func (sprint *Sprint) Goal() string {
+	return sprint.markdown.MetadataValue(SprintGoalMetadataKey)
+}
+
+func (sprint *Sprint) SetGoal(goal string) {
+	sprint.markdown.SetMetadataValue(SprintGoalMetadataKey, goal)
+}
+
+func (sprint *Sprint) Start() time.Time {
+	value, _ := time.Parse(SprintDateLayout, sprint.markdown.MetadataValue(SprintStartMetadataKey))
+	return value
+}
+
+func (sprint *Sprint) SetStart(start time.Time) {
+	sprint.markdown.SetMetadataValue(SprintStartMetadataKey, start.Format(SprintDateLayout))
+}
+
+func (sprint *Sprint) End() time.Time {
+	value, _ := time.Parse(SprintDateLayout, sprint.markdown.MetadataValue(SprintEndMetadataKey))
+	return value
+}
+
+func (sprint *Sprint) SetEnd(end time.Time) {
+	sprint.markdown.SetMetadataValue(SprintEndMetadataKey, end.Format(SprintDateLayout))
+}
+
+func (sprint *Sprint) State() string {
+	state := strings.ToLower(sprint.markdown.MetadataValue(SprintStateMetadataKey))
+	if state == "" {
+		state = SprintStatePlanned
+	}
+	return state
+}
+
+func (sprint *Sprint) SetState(state string) {
+	sprint.markdown.SetMetadataValue(SprintStateMetadataKey, state)
+}
+
+func (sprint *Sprint) Dates() string {
+	return fmt.Sprintf("%s - %s", sprint.Start().Format(SprintDateLayout), sprint.End().Format(SprintDateLayout))
+}
+
+func (sprint *Sprint) DaysLeft(now time.Time) int {
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	days := int(sprint.End().Sub(today).Hours()/24) + 1
+	if days < 0 {
+		days = 0
+	}
+	return days
+}
+
+func (sprint *Sprint) Content() []byte {
+	return sprint.markdown.Content(utils.GetCurrentTimestamp())
+}
+
+func (sprint *Sprint) UpdateLinks(rootDir string) {
+	links := []string{
+		MakeIndexLink(rootDir, filepath.Dir(sprint.markdown.contentPath)),
+		MakeIdeasLink(rootDir, filepath.Dir(sprint.markdown.contentPath)),
+		MakeTagsLink(rootDir, filepath.Dir(sprint.markdown.contentPath)),
+	}
+	sprint.markdown.SetLinks(utils.JoinMarkdownLinks(links...))
+	sprint.Save()
+}
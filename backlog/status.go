@@ -1,6 +1,7 @@
 package backlog
 
 import (
+	"errors"
 	"fmt"
 	"github.com/mreider/agilemarkdown/utils"
 	"strings"
@@ -10,16 +11,75 @@ type BacklogItemStatus struct {
 	Code        string
 	Name        string
 	Description string
+	Done        bool
+	Initial     bool
+	Started     bool
+	From        []string
 }
 
 var (
-	DoingStatus     = &BacklogItemStatus{"d", "doing", "in doing"}
-	PlannedStatus   = &BacklogItemStatus{"p", "planned", "planned"}
-	UnplannedStatus = &BacklogItemStatus{"u", "unplanned", "unplanned"}
-	FinishedStatus  = &BacklogItemStatus{"f", "finished", "finished"}
+	DoingStatus     = &BacklogItemStatus{Code: "d", Name: "doing", Description: "in doing", Started: true}
+	PlannedStatus   = &BacklogItemStatus{Code: "p", Name: "planned", Description: "planned"}
+	UnplannedStatus = &BacklogItemStatus{Code: "u", Name: "unplanned", Description: "unplanned", Initial: true}
+	FinishedStatus  = &BacklogItemStatus{Code: "f", Name: "finished", Description: "finished", Done: true}
 )
 
-var AllStatuses = []*BacklogItemStatus{DoingStatus, PlannedStatus, UnplannedStatus, FinishedStatus}
+var (
+	DefaultStatuses = []*BacklogItemStatus{DoingStatus, PlannedStatus, UnplannedStatus, FinishedStatus}
+	AllStatuses     = DefaultStatuses
+)
+
+const archiveStatusCode = "a"
+
+func SetStatuses(statuses []*BacklogItemStatus) error {
+	if len(statuses) == 0 {
+		AllStatuses = DefaultStatuses
+		return nil
+	}
+
+	codes := make(map[string]bool)
+	names := make(map[string]bool)
+	hasDone := false
+	for _, status := range statuses {
+		status.Code = strings.ToLower(strings.TrimSpace(status.Code))
+		status.Name = strings.ToLower(strings.TrimSpace(status.Name))
+		if status.Code == "" || status.Name == "" {
+			return errors.New("a status should have a code and a name")
+		}
+		if strings.ContainsAny(status.Code, " \t") {
+			return fmt.Errorf("the status code '%s' shouldn't contain spaces", status.Code)
+		}
+		if status.Code == archiveStatusCode {
+			return fmt.Errorf("the status code '%s' is reserved for archiving", status.Code)
+		}
+		if codes[status.Code] {
+			return fmt.Errorf("duplicate status code '%s'", status.Code)
+		}
+		if names[status.Name] {
+			return fmt.Errorf("duplicate status name '%s'", status.Name)
+		}
+		if status.Description == "" {
+			status.Description = status.Name
+		}
+		codes[status.Code] = true
+		names[status.Name] = true
+		hasDone = hasDone || status.Done
+	}
+	if !hasDone {
+		return errors.New("at least one status should be marked as done")
+	}
+	for _, status := range statuses {
+		for i, code := range status.From {
+			code = strings.ToLower(strings.TrimSpace(code))
+			if !codes[code] {
+				return fmt.Errorf("the status '%s' has an unknown status code '%s' in its transitions", status.Name, code)
+			}
+			status.From[i] = code
+		}
+	}
+	AllStatuses = statuses
+	return nil
+}
 
 func StatusByCode(statusCode string) *BacklogItemStatus {
 	for _, status := range AllStatuses {
@@ -78,6 +138,88 @@ func AllStatusesList() string {
 	return strings.Join(result, ", ")
 }
 
+func DoneStatuses() []*BacklogItemStatus {
+	result := make([]*BacklogItemStatus, 0, 1)
+	for _, status := range AllStatuses {
+		if status.Done {
+			result = append(result, status)
+		}
+	}
+	return result
+}
+
+func UnfinishedStatuses() []*BacklogItemStatus {
+	result := make([]*BacklogItemStatus, 0, len(AllStatuses))
+	for _, status := range AllStatuses {
+		if !status.Done {
+			result = append(result, status)
+		}
+	}
+	return result
+}
+
+func InitialStatus() *BacklogItemStatus {
+	for _, status := range AllStatuses {
+		if status.Initial {
+			return status
+		}
+	}
+	if status := StatusByName(UnplannedStatus.Name); status != nil {
+		return status
+	}
+	for _, status := range AllStatuses {
+		if !status.Done {
+			return status
+		}
+	}
+	return AllStatuses[0]
+}
+
+func StartedStatuses() []*BacklogItemStatus {
+	result := make([]*BacklogItemStatus, 0, 1)
+	for _, status := range AllStatuses {
+		if status.Started {
+			result = append(result, status)
+		}
+	}
+	if len(result) == 0 {
+		if status := StatusByName(DoingStatus.Name); status != nil {
+			result = append(result, status)
+		}
+	}
+	return result
+}
+
+func IsStartedStatusName(statusName string) bool {
+	status := StatusByName(statusName)
+	if status == nil {
+		return false
+	}
+	for _, startedStatus := range StartedStatuses() {
+		if startedStatus == status {
+			return true
+		}
+	}
+	return false
+}
+
+func IsDoneStatusName(statusName string) bool {
+	status := StatusByName(statusName)
+	return status != nil && status.Done
+}
+
+func IsAllowedTransition(from, to *BacklogItemStatus) bool {
+	if from == nil || to == nil || from.Name == to.Name || len(to.From) == 0 {
+		return true
+	}
+	for _, code := range to.From {
+		if code == from.Code {
+			return true
+		}
+	}
+	return false
+}
+
 func (status *BacklogItemStatus) CapitalizedName() string {
 	return utils.TitleFirstLetter(status.Name)
 }
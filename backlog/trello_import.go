@@ -0,0 +1,154 @@
+package backlog
+
+import (
+	"encoding/json"
+	"github.com/mreider/agilemarkdown/utils"
+	"io/ioutil"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type trelloBoard struct {
+	Lists   []*trelloList   `json:"lists"`
+	Cards   []*trelloCard   `json:"cards"`
+	Members []*trelloMember `json:"members"`
+	Actions []*trelloAction `json:"actions"`
+}
+
+type trelloList struct {
+	ID     string `json:"id"`
+	Name   string `json:"name"`
+	Closed bool   `json:"closed"`
+}
+
+type trelloCard struct {
+	ID        string   `json:"id"`
+	Name      string   `json:"name"`
+	Desc      string   `json:"desc"`
+	IDList    string   `json:"idList"`
+	IDMembers []string `json:"idMembers"`
+	Closed    bool     `json:"closed"`
+	Labels    []struct {
+		Name  string `json:"name"`
+		Color string `json:"color"`
+	} `json:"labels"`
+}
+
+type trelloMember struct {
+	ID       string `json:"id"`
+	FullName string `json:"fullName"`
+	Username string `json:"username"`
+}
+
+func (member *trelloMember) name() string {
+	if member.FullName != "" {
+		return member.FullName
+	}
+	return member.Username
+}
+
+type trelloAction struct {
+	Type          string       `json:"type"`
+	Date          time.Time    `json:"date"`
+	MemberCreator trelloMember `json:"memberCreator"`
+	Data          struct {
+		Text string `json:"text"`
+		Card struct {
+			ID string `json:"id"`
+		} `json:"card"`
+	} `json:"data"`
+}
+
+// TrelloImporter reads JSON board exports of Trello. Lists are used as states.
+type TrelloImporter struct {
+}
+
+func (imp *TrelloImporter) Name() string {
+	return "trello"
+}
+
+func (imp *TrelloImporter) Read(path string) ([]*ImportedItem, error) {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var board trelloBoard
+	if err := json.Unmarshal(content, &board); err != nil {
+		return nil, err
+	}
+
+	lists := make(map[string]*trelloList)
+	for _, list := range board.Lists {
+		lists[list.ID] = list
+	}
+	members := make(map[string]*trelloMember)
+	for _, member := range board.Members {
+		members[member.ID] = member
+	}
+	sort.SliceStable(board.Actions, func(i, j int) bool {
+		return board.Actions[i].Date.Before(board.Actions[j].Date)
+	})
+	authors := make(map[string]string)
+	comments := make(map[string][]*ImportedComment)
+	for _, action := range board.Actions {
+		switch action.Type {
+		case "createCard":
+			authors[action.Data.Card.ID] = action.MemberCreator.name()
+		case "commentCard":
+			comments[action.Data.Card.ID] = append(comments[action.Data.Card.ID], &ImportedComment{
+				Author:  action.MemberCreator.name(),
+				Created: utils.GetTimestamp(action.Date),
+				Text:    action.Data.Text,
+			})
+		}
+	}
+
+	items := make([]*ImportedItem, 0, len(board.Cards))
+	for _, card := range board.Cards {
+		item := &ImportedItem{
+			Title:       strings.TrimSpace(card.Name),
+			Created:     trelloCreated(card.ID),
+			Author:      authors[card.ID],
+			Description: strings.TrimSpace(card.Desc),
+			Comments:    comments[card.ID],
+			Archived:    card.Closed,
+		}
+		if list := lists[card.IDList]; list != nil {
+			item.State = list.Name
+			item.Archived = item.Archived || list.Closed
+		}
+		for _, memberID := range card.IDMembers {
+			if member := members[memberID]; member != nil {
+				item.Assigned = member.name()
+				break
+			}
+		}
+		for _, label := range card.Labels {
+			if label.Name != "" {
+				item.Tags = append(item.Tags, label.Name)
+			} else if label.Color != "" {
+				item.Tags = append(item.Tags, label.Color)
+			}
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+func (imp *TrelloImporter) DefaultStatus(state string) *BacklogItemStatus {
+	return guessStatus(state)
+}
+
+// trelloCreated gets the creation time from the card id, its first 4 bytes are a Unix timestamp.
+func trelloCreated(id string) string {
+	if len(id) < 8 {
+		return ""
+	}
+	seconds, err := strconv.ParseInt(id[:8], 16, 64)
+	if err != nil {
+		return ""
+	}
+	return utils.GetTimestamp(time.Unix(seconds, 0).UTC())
+}
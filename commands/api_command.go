@@ -0,0 +1,49 @@
+package commands
+
+import (
+	"fmt"
+	"github.com/mreider/agilemarkdown/git"
+	"github.com/mreider/agilemarkdown/server"
+	"gopkg.in/urfave/cli.v1"
+	"net/http"
+	"os"
+)
+
+var APICommand = cli.Command{
+	Name:      "api",
+	Usage:     "Serve a JSON REST API over backlogs, stories, ideas, users and tags",
+	ArgsUsage: " ",
+	Flags: []cli.Flag{
+		cli.StringFlag{
+			Name:  "address",
+			Value: "localhost:8081",
+			Usage: "Address to listen on",
+		},
+		cli.BoolFlag{
+			Name:  "sync",
+			Usage: "Run a full sync with the remote git repo after every change",
+		},
+		cli.StringFlag{
+			Name:  "author",
+			Usage: "Author of new stories and ideas, and of the sync commits",
+		},
+	},
+	Action: func(c *cli.Context) error {
+		rootDir, err := findRootDirectory()
+		if err != nil {
+			return err
+		}
+		if err := os.Chdir(rootDir); err != nil {
+			return err
+		}
+
+		repo := git.NewExecRepository(rootDir)
+		sync := NewSyncAction(repo, rootDir, c.String("author"))
+		sync.testMode = !c.Bool("sync")
+		mux := http.NewServeMux()
+		mux.Handle(server.APIPrefix, server.NewAPI(repo, rootDir, c.String("author"), sync.Execute))
+
+		fmt.Printf("Serving the API of %s on http://%s%s\n", rootDir, c.String("address"), server.APIPrefix)
+		return http.ListenAndServe(c.String("address"), mux)
+	},
+}
@@ -3,6 +3,7 @@ package commands
 import (
 	"fmt"
 	"github.com/mreider/agilemarkdown/backlog"
+	"github.com/mreider/agilemarkdown/git"
 	"gopkg.in/urfave/cli.v1"
 	"path/filepath"
 	"time"
@@ -12,32 +13,60 @@ var ArchiveCommand = cli.Command{
 	Name:      "archive",
 	Usage:     "Archive stories before a certain date",
 	ArgsUsage: "YYYY-MM-DD",
+	Flags: []cli.Flag{
+		queryFlag,
+	},
 	Action: func(c *cli.Context) error {
 		if err := checkIsBacklogDirectory(); err != nil {
 			fmt.Println(err)
 			return nil
 		}
-		if c.NArg() != 1 {
-			fmt.Println("A date should be specified")
+		query := c.String("query")
+		if c.NArg() > 1 || (c.NArg() == 0 && query == "") {
+			fmt.Println("A date or a query should be specified")
 			return nil
 		}
 
-		beforeDate, err := time.Parse("2006-1-2", c.Args()[0])
-		if err != nil {
-			fmt.Println("Invalid date. Should be in YYYY-MM-DD format.")
-			return nil
+		var beforeDate time.Time
+		if c.NArg() == 1 {
+			var err error
+			beforeDate, err = time.Parse("2006-1-2", c.Args()[0])
+			if err != nil {
+				fmt.Println("Invalid date. Should be in YYYY-MM-DD format.")
+				return nil
+			}
 		}
 
+		repo := git.NewExecRepository("")
 		backlogDir, _ := filepath.Abs(".")
 		bck, err := backlog.LoadBacklog(backlogDir)
 		if err != nil {
 			return err
 		}
 
+		queryFilter, err := parseItemsQuery(repo, query, filepath.Dir(backlogDir), bck.Fields())
+		if err != nil {
+			fmt.Printf("illegal query: %v\n", err)
+			return nil
+		}
+
+		history, err := backlog.LoadBacklogHistory(repo, backlogDir, bck.AllItems())
+		if err != nil {
+			return err
+		}
+
 		var itemsToArchive []*backlog.BacklogItem
-		for _, item := range bck.ActiveItems() {
+		for _, item := range bck.FilteredActiveItems(queryFilter) {
+			if beforeDate.IsZero() {
+				itemsToArchive = append(itemsToArchive, item)
+				continue
+			}
+			itemDate := item.Modified()
+			if backlog.IsDoneStatusName(item.Status()) {
+				itemDate = history.FinishedDate(item)
+			}
 			// beforeDate doesn't contain time part. So '<= beforeDate' means '< beforeDate+1day'
-			if item.Modified().Before(beforeDate.Add(time.Hour * 24)) {
+			if itemDate.Before(beforeDate.Add(time.Hour * 24)) {
 				itemsToArchive = append(itemsToArchive, item)
 			}
 		}
@@ -4,6 +4,7 @@ import (
 	"bufio"
 	"fmt"
 	"github.com/mreider/agilemarkdown/backlog"
+	"github.com/mreider/agilemarkdown/git"
 	"gopkg.in/urfave/cli.v1"
 	"os"
 	"regexp"
@@ -12,53 +13,72 @@ import (
 )
 
 var (
-	itemStatusRe = regexp.MustCompile(`^(\d+)\s+([dpufa])$`)
+	itemStatusRe = regexp.MustCompile(`^(\d+)\s+(\S+)$`)
 )
 
-var ChangeStatusCommand = cli.Command{
-	Name:      "change-status",
-	Usage:     "Change story status",
-	ArgsUsage: " ",
-	Flags: []cli.Flag{
-		cli.StringFlag{
-			Name:  "s",
-			Usage: fmt.Sprintf("Status - %s", backlog.AllStatusesList()),
+func NewChangeStatusCommand() cli.Command {
+	return cli.Command{
+		Name:      "change-status",
+		Usage:     "Change story status",
+		ArgsUsage: " ",
+		Flags: []cli.Flag{
+			cli.StringFlag{
+				Name:  "s",
+				Usage: fmt.Sprintf("Status - %s", backlog.AllStatusesList()),
+			},
+			queryFlag,
+			backlogFlag,
 		},
-	},
-	Action: func(c *cli.Context) error {
-		var items []*backlog.BacklogItem
-		var err error
-		if items, err = showBacklogItems(c); items == nil {
-			return err
-		}
-		reader := bufio.NewReader(os.Stdin)
-		for {
-			hints := []string{backlog.UnplannedStatus.Hint(), backlog.PlannedStatus.Hint(), backlog.DoingStatus.Hint(), backlog.FinishedStatus.Hint(), "(a)rchive"}
-			fmt.Printf("Enter story # number and status %s or e to exit (example: 1 f changes #1 to finished)\n", strings.Join(hints, ", "))
-			text, _ := reader.ReadString('\n')
-			text = strings.ToLower(strings.TrimSpace(text))
-			if text == "e" {
-				break
+		Action: func(c *cli.Context) error {
+			repo := git.NewExecRepository("")
+			var items []*backlog.BacklogItem
+			var err error
+			if items, err = showBacklogItems(repo, c); items == nil {
+				return err
 			}
-			match := itemStatusRe.FindStringSubmatch(text)
-			if match != nil {
-				itemNo, _ := strconv.Atoi(match[1])
-				statusCode := match[2]
-				itemIndex := itemNo - 1
-				if itemIndex < 0 || itemIndex >= len(items) {
-					fmt.Println("illegal story number")
-					continue
+			reader := bufio.NewReader(os.Stdin)
+			hints := make([]string, 0, len(backlog.AllStatuses)+1)
+			for _, status := range backlog.AllStatuses {
+				hints = append(hints, status.Hint())
+			}
+			hints = append(hints, "(a)rchive")
+			exampleStatus := backlog.DoneStatuses()[0]
+			for {
+				fmt.Printf("Enter story # number and status %s or e to exit (example: 1 %s changes #1 to %s)\n", strings.Join(hints, ", "), exampleStatus.Code, exampleStatus.Name)
+				text, _ := reader.ReadString('\n')
+				text = strings.ToLower(strings.TrimSpace(text))
+				if text == "e" {
+					break
 				}
-				item := items[itemIndex]
-				if statusCode != "a" {
-					item.SetStatus(backlog.StatusByCode(statusCode))
-				} else {
-					item.SetArchived(true)
+				match := itemStatusRe.FindStringSubmatch(text)
+				if match != nil {
+					itemNo, _ := strconv.Atoi(match[1])
+					statusCode := match[2]
+					itemIndex := itemNo - 1
+					if itemIndex < 0 || itemIndex >= len(items) {
+						fmt.Println("illegal story number")
+						continue
+					}
+					if statusCode != "a" && !backlog.IsValidStatusCode(statusCode) {
+						fmt.Printf("illegal status: %s\n", statusCode)
+						continue
+					}
+					item := items[itemIndex]
+					if statusCode != "a" {
+						currentStatus, newStatus := backlog.StatusByName(item.Status()), backlog.StatusByCode(statusCode)
+						if !backlog.IsAllowedTransition(currentStatus, newStatus) {
+							fmt.Printf("illegal status change: %s -> %s\n", currentStatus.Name, newStatus.Name)
+							continue
+						}
+						item.SetStatus(newStatus)
+					} else {
+						item.SetArchived(true)
+					}
+					item.Save()
 				}
-				item.Save()
 			}
-		}
 
-		return nil
-	},
+			return nil
+		},
+	}
 }
@@ -55,10 +55,11 @@ var CreateIdeaCommand = cli.Command{
 			return nil
 		}
 
+		repo := git.NewExecRepository("")
 		currentUser := user
 		if currentUser == "" {
 			var err error
-			currentUser, _, err = git.CurrentUser()
+			currentUser, _, err = repo.CurrentUser()
 			if err != nil {
 				currentUser = "unknown"
 			}
@@ -2,6 +2,7 @@ package commands
 
 import (
 	"github.com/mreider/agilemarkdown/backlog"
+	"github.com/mreider/agilemarkdown/git"
 	"github.com/mreider/agilemarkdown/users"
 	"gopkg.in/urfave/cli.v1"
 	"os"
@@ -35,7 +36,8 @@ var CreateUserCommand = cli.Command{
 			}
 			rootDir = filepath.Dir(rootDir)
 		}
-		userList := users.NewUserList(filepath.Join(rootDir, backlog.UsersDirectoryName))
+		repo := git.NewExecRepository(rootDir)
+		userList := users.NewUserList(repo, filepath.Join(rootDir, backlog.UsersDirectoryName))
 		if userList.AddUser(name, email) {
 			return userList.Save()
 		}
@@ -16,13 +16,17 @@ var CreateBacklogCommand = cli.Command{
 	ArgsUsage: "BACKLOG_NAME",
 	Action: func(c *cli.Context) error {
 		if err := checkIsRootDirectory("."); err != nil {
-			out, statusErr := git.Status()
-			if statusErr != nil && strings.Contains(out, "fatal: not a git repository") {
-				err := git.Init()
+			repo := git.NewExecRepository("")
+			_, statusErr := repo.TopLevelDirectory()
+			if statusErr != nil && strings.Contains(statusErr.Error(), "not a git repository") {
+				err := repo.Init()
 				if err != nil {
 					return err
 				}
-				AddConfigAndGitIgnore(".")
+				AddConfigAndGitIgnore(repo, ".")
+			} else if rootDir := FindRootDirectory("."); statusErr == nil && !existsFile(filepath.Join(rootDir, backlog.IndexFileName)) {
+				// a folder inside a git repository without backlogs becomes the root folder of the backlogs
+				AddConfigAndGitIgnore(repo, ".")
 			} else {
 				return err
 			}
@@ -52,8 +56,6 @@ var CreateBacklogCommand = cli.Command{
 			return nil
 		}
 
-		git.SetUpstream()
-
 		err := os.MkdirAll(backlogDir, 0777)
 		if err != nil {
 			return err
@@ -71,7 +73,7 @@ var CreateBacklogCommand = cli.Command{
 			return err
 		}
 		overview.SetTitle(backlogName)
-		overview.UpdateLinks("archive", filepath.Join(backlogDir, ArchiveFileName), ".", ".")
+		overview.UpdateLinks(".", ".", backlog.PageLink{Title: "archive", Path: filepath.Join(backlogDir, ArchiveFileName)})
 		overview.SetCreated()
 		return overview.Save()
 	},
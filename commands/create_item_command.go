@@ -10,15 +10,6 @@ import (
 	"strings"
 )
 
-const newItemTemplate = `## Problem statement
-
-## Possible solution
-
-## Comments
-
-## Attachments
-`
-
 var CreateItemCommand = cli.Command{
 	Name:      "create-item",
 	Usage:     "Create a new item for the backlog",
@@ -66,10 +57,11 @@ var CreateItemCommand = cli.Command{
 			return nil
 		}
 
+		repo := git.NewExecRepository("")
 		currentUser := user
 		if currentUser == "" {
 			var err error
-			currentUser, _, err = git.CurrentUser()
+			currentUser, _, err = repo.CurrentUser()
 			if err != nil {
 				currentUser = "unknown"
 			}
@@ -84,10 +76,10 @@ var CreateItemCommand = cli.Command{
 		item.SetModified()
 		item.SetTags(nil)
 		item.SetAuthor(currentUser)
-		item.SetStatus(backlog.UnplannedStatus)
+		item.SetStatus(backlog.InitialStatus())
 		item.SetAssigned("")
 		item.SetEstimate("")
-		item.SetDescription(newItemTemplate)
+		item.SetDescription(backlog.NewItemTemplate)
 
 		if !simulate {
 			return item.Save()
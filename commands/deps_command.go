@@ -0,0 +1,54 @@
+package commands
+
+import (
+	"fmt"
+	"github.com/mreider/agilemarkdown/backlog"
+	"gopkg.in/urfave/cli.v1"
+	"strings"
+)
+
+var DepsCommand = cli.Command{
+	Name:      "deps",
+	Usage:     "Show dependencies between stories",
+	ArgsUsage: " ",
+	Flags: []cli.Flag{
+		cli.BoolFlag{
+			Name:  "dot",
+			Usage: "Print the dependency graph in Graphviz DOT format",
+		},
+	},
+	Action: func(c *cli.Context) error {
+		rootDir, err := findRootDirectory()
+		if err != nil {
+			return err
+		}
+		backlogDirs, err := findBacklogDirs(rootDir)
+		if err != nil {
+			return err
+		}
+		items, err := loadAllBacklogItems(backlogDirs)
+		if err != nil {
+			return err
+		}
+		deps := backlog.NewDependencyGraph(rootDir, items)
+
+		if c.Bool("dot") {
+			fmt.Println(strings.Join(deps.Dot(), "\n"))
+			return nil
+		}
+
+		lines := backlog.BacklogView{}.WriteAsciiDependencies(deps)
+		if len(lines) == 0 {
+			fmt.Println("There are no dependencies")
+		} else {
+			fmt.Println(strings.Join(lines, "\n"))
+		}
+		for _, missing := range deps.Missing() {
+			fmt.Printf("Missing: %s depends on '%s'\n", deps.ItemID(missing.Item), missing.Ref)
+		}
+		for _, cycle := range deps.Cycles() {
+			fmt.Printf("Cycle: %s\n", backlog.BacklogView{}.DependencyCycle(deps, cycle))
+		}
+		return nil
+	},
+}
@@ -0,0 +1,36 @@
+package commands
+
+import (
+	"fmt"
+	"github.com/mreider/agilemarkdown/backlog"
+	"gopkg.in/urfave/cli.v1"
+	"strings"
+)
+
+var EpicsCommand = cli.Command{
+	Name:      "epics",
+	Usage:     "List epics and their progress across all backlogs",
+	ArgsUsage: " ",
+	Action: func(c *cli.Context) error {
+		rootDir, err := findRootDirectory()
+		if err != nil {
+			return err
+		}
+		backlogDirs, err := findBacklogDirs(rootDir)
+		if err != nil {
+			return err
+		}
+		items, err := loadAllBacklogItems(backlogDirs)
+		if err != nil {
+			return err
+		}
+
+		epics := backlog.NewEpicList(rootDir, items)
+		if len(epics.Epics()) == 0 {
+			fmt.Println("There are no epics")
+			return nil
+		}
+		fmt.Println(strings.Join(backlog.BacklogView{}.WriteAsciiEpics(epics), "\n"))
+		return nil
+	},
+}
@@ -0,0 +1,75 @@
+package commands
+
+import (
+	"fmt"
+	"github.com/mreider/agilemarkdown/backlog"
+	"github.com/mreider/agilemarkdown/git"
+	"github.com/mreider/agilemarkdown/utils"
+	"gopkg.in/urfave/cli.v1"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+var ExportCommand = cli.Command{
+	Name:  "export",
+	Usage: "Export stories to a CSV, JSON or XLSX file",
+	Flags: []cli.Flag{
+		cli.StringFlag{
+			Name:  "format",
+			Usage: fmt.Sprintf("Export format: %s", strings.Join(backlog.ExportFormats, ", ")),
+			Value: backlog.ExportFormatCSV,
+		},
+		cli.StringFlag{
+			Name:  "o, output",
+			Usage: "Output file, the standard output is used by default",
+		},
+		queryFlag,
+		backlogFlag,
+	},
+	Action: func(c *cli.Context) error {
+		format := strings.ToLower(c.String("format"))
+		if !utils.ContainsStringIgnoreCase(backlog.ExportFormats, format) {
+			fmt.Printf("Unknown format '%s', should be one of: %s\n", format, strings.Join(backlog.ExportFormats, ", "))
+			return nil
+		}
+
+		repo := git.NewExecRepository("")
+		backlogs, err := loadWorkBacklogs(c)
+		if err != nil {
+			fmt.Println(err)
+			return nil
+		}
+		if len(backlogs) == 0 {
+			fmt.Println("No backlogs found")
+			return nil
+		}
+		fields := workBacklogsFields(backlogs)
+		queryFilter, err := parseItemsQuery(repo, c.String("query"), filepath.Dir(backlogs[0].dir), fields)
+		if err != nil {
+			fmt.Println(err)
+			return nil
+		}
+
+		var items []*backlog.BacklogItem
+		for _, bck := range backlogs {
+			for _, item := range bck.backlog.AllItems() {
+				if queryFilter.Match(item) {
+					items = append(items, item)
+				}
+			}
+		}
+
+		var w io.Writer = os.Stdout
+		if output := c.String("output"); output != "" {
+			file, err := os.Create(output)
+			if err != nil {
+				return err
+			}
+			defer file.Close()
+			w = file
+		}
+		return backlog.NewItemsExporter(fields).Export(w, format, items)
+	},
+}
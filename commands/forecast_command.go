@@ -0,0 +1,75 @@
+package commands
+
+import (
+	"fmt"
+	"github.com/mreider/agilemarkdown/backlog"
+	"github.com/mreider/agilemarkdown/git"
+	"gopkg.in/urfave/cli.v1"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+var ForecastCommand = cli.Command{
+	Name:      "forecast",
+	Usage:     "Forecast when planned stories will be done based on the velocity",
+	ArgsUsage: " ",
+	Flags: []cli.Flag{
+		cli.IntFlag{
+			Name:  "weeks",
+			Usage: "Number of the last weeks to take the velocity from",
+			Value: backlog.ForecastWeeks,
+		},
+		cli.IntFlag{
+			Name:  "runs",
+			Usage: "Number of simulation runs",
+			Value: backlog.ForecastRuns,
+		},
+		cli.BoolFlag{
+			Name:  "overview",
+			Usage: "Add the forecast to the project page",
+		},
+	},
+	Action: func(c *cli.Context) error {
+		if err := checkIsBacklogDirectory(); err != nil {
+			fmt.Println(err)
+			return nil
+		}
+		if c.Int("weeks") <= 0 || c.Int("runs") <= 0 {
+			fmt.Println("The number of weeks and runs should be positive")
+			return nil
+		}
+
+		repo := git.NewExecRepository("")
+		backlogDir, _ := filepath.Abs(".")
+		bck, err := backlog.LoadBacklog(backlogDir)
+		if err != nil {
+			return err
+		}
+		overviewPath, ok := findOverviewFileInRootDirectory(backlogDir)
+		if !ok {
+			return fmt.Errorf("the overview file isn't found for %s", backlogDir)
+		}
+		overview, err := backlog.LoadBacklogOverview(overviewPath)
+		if err != nil {
+			return err
+		}
+		history, err := backlog.LoadBacklogHistory(repo, backlogDir, bck.AllItems())
+		if err != nil {
+			return err
+		}
+
+		now := time.Now()
+		items := backlog.ForecastBacklog(bck, history, backlog.NewBacklogItemsSorter(overview), c.Int("weeks"), c.Int("runs"), now)
+		if len(items) == 0 {
+			fmt.Println("There are no planned stories or no finished points in the last weeks")
+			return nil
+		}
+		fmt.Println(strings.Join(backlog.BacklogView{}.WriteAsciiForecast(items, now), "\n"))
+
+		if c.Bool("overview") {
+			overview.UpdateForecast(items, now)
+		}
+		return nil
+	},
+}
@@ -3,6 +3,9 @@ package commands
 import (
 	"fmt"
 	"github.com/mreider/agilemarkdown/backlog"
+	"github.com/mreider/agilemarkdown/config"
+	"github.com/mreider/agilemarkdown/git"
+	"github.com/mreider/agilemarkdown/users"
 	"gopkg.in/urfave/cli.v1"
 	"os"
 	"path/filepath"
@@ -11,40 +14,74 @@ import (
 
 var ImportCommand = cli.Command{
 	Name:      "import",
-	Usage:     "Import an existing Pivotal Tracker story",
-	ArgsUsage: "CSV_FILE",
+	Usage:     "Import stories exported from Pivotal Tracker, Jira, Trello or GitHub Issues",
+	ArgsUsage: "FILE",
+	Flags: []cli.Flag{
+		cli.StringFlag{
+			Name:  "format",
+			Usage: fmt.Sprintf("Import format: %s. It is detected by the file by default", strings.Join(backlog.ImporterNames(), ", ")),
+		},
+	},
 	Action: func(c *cli.Context) error {
 		if err := checkIsBacklogDirectory(); err != nil {
 			fmt.Println(err)
 			return nil
 		}
 		if c.NArg() == 0 {
-			fmt.Println("a csv file should be specified")
+			fmt.Println("a file to import should be specified")
 			return nil
 		}
-		for _, csvPath := range c.Args() {
-			csvPath, err := filepath.Abs(csvPath)
+		var formatImporter backlog.Importer
+		if format := c.String("format"); format != "" {
+			formatImporter = backlog.ImporterByName(format)
+			if formatImporter == nil {
+				fmt.Printf("Unknown format '%s', should be one of: %s\n", format, strings.Join(backlog.ImporterNames(), ", "))
+				return nil
+			}
+		}
+
+		rootDir, err := findRootDirectory()
+		if err != nil {
+			return err
+		}
+		cfg, err := config.LoadConfig(filepath.Join(rootDir, configName))
+		if err != nil {
+			return err
+		}
+		repo := git.NewExecRepository("")
+		userList := users.NewUserList(repo, filepath.Join(rootDir, backlog.UsersDirectoryName))
+
+		for _, importPath := range c.Args() {
+			importPath, err := filepath.Abs(importPath)
 			if err != nil {
-				fmt.Printf("The csv file '%s' is wrong: %v\n", csvPath, err)
+				fmt.Printf("The file '%s' is wrong: %v\n", importPath, err)
 				continue
 			}
-			_, err = os.Stat(csvPath)
+			_, err = os.Stat(importPath)
 			if err != nil {
-				fmt.Printf("The csv file '%s' is wrong: %v\n", csvPath, err)
+				fmt.Printf("The file '%s' is wrong: %v\n", importPath, err)
 				continue
 			}
-			ext := strings.ToLower(filepath.Ext(csvPath))
-			if ext != ".csv" {
-				fmt.Printf("The file '%s' should be a CSV file\n", csvPath)
-				continue
+			importer := formatImporter
+			if importer == nil {
+				importer, err = backlog.DetectImporter(importPath)
+				if err != nil {
+					fmt.Println(err)
+					continue
+				}
 			}
 
-			csvImporter := backlog.NewCsvImporter(csvPath, ".")
-			err = csvImporter.Import()
+			itemsImport := backlog.NewItemsImport(".", userList)
+			if importerCfg := cfg.Importers[importer.Name()]; importerCfg != nil {
+				itemsImport.SetStatusMapping(importerCfg.Statuses)
+				itemsImport.SetUserMapping(importerCfg.Users)
+			}
+			count, err := itemsImport.Import(importer, importPath)
 			if err != nil {
-				fmt.Printf("Import of the csv file '%s' failed: %v\n", csvPath, err)
+				fmt.Printf("Import of the file '%s' failed: %v\n", importPath, err)
 				continue
 			}
+			fmt.Printf("Imported %d stories from '%s' as %s\n", count, importPath, importer.Name())
 		}
 		return nil
 	},
@@ -0,0 +1,156 @@
+package commands
+
+import (
+	"fmt"
+	"github.com/mreider/agilemarkdown/backlog"
+	"github.com/mreider/agilemarkdown/git"
+	"github.com/mreider/agilemarkdown/utils"
+	"gopkg.in/urfave/cli.v1"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	mergeDriverName = "agilemarkdown"
+	mergeDriverCmd  = "am merge-driver %O %A %B %P"
+)
+
+var mergeDriverAttributes = []string{
+	fmt.Sprintf("*/*.md merge=%s", mergeDriverName),
+	fmt.Sprintf("*/%s/*.md merge=%s", backlog.ArchiveDirectoryName, mergeDriverName),
+}
+
+var MergeDriverCommand = cli.Command{
+	Name:      "merge-driver",
+	Usage:     "Merge changes of story files, it is run by git",
+	ArgsUsage: "BASE OURS THEIRS PATH",
+	Flags: []cli.Flag{
+		cli.BoolFlag{
+			Name:  "install",
+			Usage: "Register the merge driver in the git repository",
+		},
+	},
+	Action: func(c *cli.Context) error {
+		if c.Bool("install") {
+			rootDir, err := findRootDirectory()
+			if err != nil {
+				fmt.Println(err)
+				return nil
+			}
+			return installMergeDriver(git.NewExecRepository(rootDir), rootDir)
+		}
+		if c.NArg() < 4 {
+			fmt.Println("base, ours, theirs and path should be specified")
+			return nil
+		}
+
+		var versions []string
+		for _, versionPath := range c.Args()[:3] {
+			data, err := ioutil.ReadFile(versionPath)
+			if err != nil {
+				return err
+			}
+			versions = append(versions, string(data))
+		}
+		// git runs the driver in the root of the work tree, the backlogs can be in a subfolder of it
+		filePath, _ := filepath.Abs(c.Args()[3])
+		rootDir := FindRootDirectory(filepath.Dir(filePath))
+		if rootDir == "" {
+			rootDir, _ = filepath.Abs(".")
+		}
+		relPath, err := filepath.Rel(rootDir, filePath)
+		if err != nil {
+			return err
+		}
+		merged, conflicts := mergeFile(rootDir, filepath.ToSlash(relPath), versions[0], versions[1], versions[2])
+		if err := ioutil.WriteFile(c.Args()[1], []byte(merged), 0644); err != nil {
+			return err
+		}
+		if len(conflicts) > 0 {
+			for _, conflict := range conflicts {
+				fmt.Fprintf(os.Stderr, "CONFLICT in %s, %s\n", c.Args()[3], conflict)
+			}
+			return cli.NewExitError("", 1)
+		}
+		return nil
+	},
+}
+
+func installMergeDriver(repo git.Repository, rootDir string) error {
+	if err := repo.SetConfig(fmt.Sprintf("merge.%s.name", mergeDriverName), "agilemarkdown story merge"); err != nil {
+		return err
+	}
+	if err := repo.SetConfig(fmt.Sprintf("merge.%s.driver", mergeDriverName), mergeDriverCmd); err != nil {
+		return err
+	}
+
+	attributesPath := filepath.Join(rootDir, ".gitattributes")
+	data, err := ioutil.ReadFile(attributesPath)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	lines := strings.Split(strings.TrimRight(string(data), "\n"), "\n")
+	if len(data) == 0 {
+		lines = nil
+	}
+	hasChanges := false
+	for _, attribute := range mergeDriverAttributes {
+		if !utils.ContainsStringIgnoreCase(lines, attribute) {
+			lines = append(lines, attribute)
+			hasChanges = true
+		}
+	}
+	if hasChanges {
+		if err := ioutil.WriteFile(attributesPath, []byte(strings.Join(lines, "\n")+"\n"), 0644); err != nil {
+			return err
+		}
+		if err := repo.Add(attributesPath); err != nil {
+			return err
+		}
+	}
+	fmt.Println("The merge driver is registered")
+	return nil
+}
+
+// mergeFile merges a story with MergeItemContent and other files line by line.
+func mergeFile(rootDir, relPath, base, ours, theirs string) (string, []*backlog.MergeConflict) {
+	if backlogDir, ok := itemBacklogDirectory(rootDir, relPath); ok {
+		fields, err := backlog.LoadBacklogFields(backlogDir)
+		if err == nil {
+			return backlog.MergeItemContent(base, ours, theirs, fields)
+		}
+	}
+
+	merged, conflict := mergeText(base, ours, theirs)
+	if conflict {
+		return merged, []*backlog.MergeConflict{{Field: "Content", Ours: "see the conflict markers", Theirs: "see the conflict markers"}}
+	}
+	return merged, nil
+}
+
+// mergeText merges the files line by line, the conflicting lines are marked like git does.
+func mergeText(base, ours, theirs string) (string, bool) {
+	merged, conflict := utils.Merge3(strings.Split(base, "\n"), strings.Split(ours, "\n"), strings.Split(theirs, "\n"))
+	return strings.Join(merged, "\n"), conflict
+}
+
+func itemBacklogDirectory(rootDir, relPath string) (string, bool) {
+	itemName := strings.TrimSuffix(filepath.Base(relPath), ".md")
+	if !strings.HasSuffix(relPath, ".md") || backlog.IsForbiddenItemName(itemName) {
+		return "", false
+	}
+	dir := filepath.Dir(filepath.FromSlash(relPath))
+	if filepath.Base(dir) == backlog.ArchiveDirectoryName {
+		dir = filepath.Dir(dir)
+	}
+	if dir == "." || filepath.Dir(dir) != "." {
+		return "", false
+	}
+	backlogDir := filepath.Join(rootDir, dir)
+	if _, ok := findOverviewFileInRootDirectory(backlogDir); !ok {
+		return "", false
+	}
+	return backlogDir, true
+}
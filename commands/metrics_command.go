@@ -0,0 +1,66 @@
+package commands
+
+import (
+	"fmt"
+	"github.com/mreider/agilemarkdown/backlog"
+	"github.com/mreider/agilemarkdown/git"
+	"gopkg.in/urfave/cli.v1"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+var MetricsCommand = cli.Command{
+	Name:      "metrics",
+	Usage:     "Show lead time and cycle time of finished stories by backlog, user and tag",
+	ArgsUsage: " ",
+	Action: func(c *cli.Context) error {
+		var backlogDirs []string
+		if err := checkIsBacklogDirectory(); err == nil {
+			backlogDir, _ := filepath.Abs(".")
+			backlogDirs = []string{backlogDir}
+		} else {
+			rootDir, err := findRootDirectory()
+			if err != nil {
+				return err
+			}
+			backlogDirs, err = findBacklogDirs(rootDir)
+			if err != nil {
+				return err
+			}
+		}
+
+		repo := git.NewExecRepository("")
+		var allMetrics []*backlog.ItemMetrics
+		var backlogRows []*backlog.MetricsRow
+		for _, backlogDir := range backlogDirs {
+			bck, err := backlog.LoadBacklog(backlogDir)
+			if err != nil {
+				return err
+			}
+			history, err := backlog.LoadBacklogHistory(repo, backlogDir, bck.AllItems())
+			if err != nil {
+				return err
+			}
+			metrics := backlog.NewItemsMetrics(bck.AllItems(), history)
+			if len(metrics) == 0 {
+				continue
+			}
+			allMetrics = append(allMetrics, metrics...)
+			backlogRows = append(backlogRows, backlog.NewMetricsRow(filepath.Base(backlogDir), metrics))
+		}
+		if len(allMetrics) == 0 {
+			fmt.Println("There are no finished stories")
+			return nil
+		}
+
+		fmt.Println("Lead and cycle time, days")
+		fmt.Println(strings.Join(backlog.BacklogView{}.WriteAsciiMetricsReport(backlogRows, allMetrics), "\n"))
+		if chart := (backlog.BacklogView{}).CycleTimeScatter(allMetrics, time.Now(), 84); chart != "" {
+			fmt.Println("")
+			fmt.Println("Cycle time")
+			fmt.Println(chart)
+		}
+		return nil
+	},
+}
@@ -0,0 +1,97 @@
+package commands
+
+import (
+	"fmt"
+	"github.com/mreider/agilemarkdown/backlog"
+	"gopkg.in/urfave/cli.v1"
+	"path/filepath"
+)
+
+var MigrateMetadataCommand = cli.Command{
+	Name:      "migrate-metadata",
+	Usage:     "Convert the metadata of all stories, ideas, sprints and project pages to YAML front matter",
+	ArgsUsage: " ",
+	Flags: []cli.Flag{
+		cli.BoolFlag{
+			Name:  "legacy",
+			Usage: "Convert the metadata back to 'Key: value' lines",
+		},
+	},
+	Action: func(c *cli.Context) error {
+		rootDir, err := findRootDirectory()
+		if err != nil {
+			return err
+		}
+		frontMatter := !c.Bool("legacy")
+
+		type frontMatterContent interface {
+			SetFrontMatter(frontMatter bool) error
+			SaveUnmodified() error
+		}
+		var contents []frontMatterContent
+
+		backlogDirs, err := findBacklogDirs(rootDir)
+		if err != nil {
+			return err
+		}
+		for _, backlogDir := range backlogDirs {
+			bck, err := backlog.LoadBacklog(backlogDir)
+			if err != nil {
+				return err
+			}
+			for _, item := range bck.AllItems() {
+				contents = append(contents, item)
+			}
+
+			if overviewPath, ok := findOverviewFileInRootDirectory(backlogDir); ok {
+				overview, err := backlog.LoadBacklogOverview(overviewPath)
+				if err != nil {
+					return err
+				}
+				contents = append(contents, overview)
+			}
+			if archivePath, ok := findArchiveFileInDirectory(backlogDir); ok {
+				archive, err := backlog.LoadBacklogOverview(archivePath)
+				if err != nil {
+					return err
+				}
+				contents = append(contents, archive)
+			}
+		}
+
+		ideas, err := backlog.LoadIdeas(filepath.Join(rootDir, backlog.IdeasDirectoryName))
+		if err != nil {
+			return err
+		}
+		for _, idea := range ideas {
+			contents = append(contents, idea)
+		}
+
+		sprints, err := backlog.LoadSprints(filepath.Join(rootDir, backlog.SprintsDirectoryName))
+		if err != nil {
+			return err
+		}
+		for _, sprint := range sprints {
+			contents = append(contents, sprint)
+		}
+
+		var convertErrors int
+		for _, content := range contents {
+			if err := content.SetFrontMatter(frontMatter); err != nil {
+				fmt.Printf("can't convert the metadata of %v\n", err)
+				convertErrors++
+			}
+		}
+		if convertErrors > 0 {
+			return fmt.Errorf("%d file(s) can't be converted, no files are changed", convertErrors)
+		}
+		for _, content := range contents {
+			err := content.SaveUnmodified()
+			if err != nil {
+				return err
+			}
+		}
+		fmt.Printf("%d file(s) are processed\n", len(contents))
+		return nil
+	},
+}
@@ -3,14 +3,40 @@ package commands
 import (
 	"fmt"
 	"github.com/mreider/agilemarkdown/backlog"
+	"github.com/mreider/agilemarkdown/git"
+	"github.com/mreider/agilemarkdown/utils"
 	"gopkg.in/urfave/cli.v1"
+	"path/filepath"
 	"strconv"
+	"time"
 )
 
 var ProgressCommand = cli.Command{
 	Name:      "progress",
 	Usage:     "Show the progress of a backlog over time",
 	ArgsUsage: "NUMBER_OF_WEEKS",
+	Flags: []cli.Flag{
+		cli.BoolFlag{
+			Name:  "burndown",
+			Usage: "Show the burndown chart of a sprint or a date range",
+		},
+		cli.BoolFlag{
+			Name:  "burnup",
+			Usage: "Show the burnup chart of a sprint or a date range",
+		},
+		cli.StringFlag{
+			Name:  "sprint",
+			Usage: "Sprint name, the active sprint by default",
+		},
+		cli.StringFlag{
+			Name:  "from",
+			Usage: "Start date in YYYY-MM-DD format",
+		},
+		cli.StringFlag{
+			Name:  "to",
+			Usage: "End date in YYYY-MM-DD format, today by default",
+		},
+	},
 	Action: func(c *cli.Context) error {
 		var weekCount int
 		if c.NArg() > 0 {
@@ -25,16 +51,77 @@ var ProgressCommand = cli.Command{
 			return nil
 		}
 
+		repo := git.NewExecRepository("")
 		bck, err := backlog.LoadBacklog(".")
 		if err != nil {
 			return err
 		}
-		chart, err := backlog.BacklogView{}.Progress(bck, weekCount, 84)
+
+		history, err := backlog.LoadBacklogHistory(repo, ".", bck.AllItems())
 		if err != nil {
 			return err
 		}
-		fmt.Println(chart)
 
+		if !c.Bool("burndown") && !c.Bool("burnup") {
+			chart, err := backlog.BacklogView{}.Progress(bck, history, weekCount, 84)
+			if err != nil {
+				return err
+			}
+			fmt.Println(chart)
+			return nil
+		}
+
+		var from, to time.Time
+		var sprintName string
+		if c.String("from") != "" {
+			if from, err = time.Parse(backlog.SprintDateLayout, c.String("from")); err != nil {
+				fmt.Println("Invalid date. Should be in YYYY-MM-DD format.")
+				return nil
+			}
+			to = time.Now()
+			if c.String("to") != "" {
+				if to, err = time.Parse(backlog.SprintDateLayout, c.String("to")); err != nil {
+					fmt.Println("Invalid date. Should be in YYYY-MM-DD format.")
+					return nil
+				}
+			}
+		} else {
+			rootDir, _ := filepath.Abs("..")
+			sprints, err := backlog.LoadSprints(filepath.Join(rootDir, backlog.SprintsDirectoryName))
+			if err != nil {
+				return err
+			}
+			var sprint *backlog.Sprint
+			if c.String("sprint") != "" {
+				sprint = backlog.SprintByName(sprints, utils.GetValidFileName(c.String("sprint")))
+			} else {
+				sprint = backlog.ActiveSprint(sprints)
+			}
+			if sprint == nil {
+				fmt.Println("A sprint or a date range should be specified")
+				return nil
+			}
+			from, to, sprintName = sprint.Start(), sprint.End(), sprint.Name()
+			fmt.Printf("Sprint: %s (%s)\n", sprint.Title(), sprint.Dates())
+		}
+		if to.Before(from) {
+			fmt.Println("The end date should be after the start date")
+			return nil
+		}
+
+		days := history.BurnChart(from, to, sprintName)
+		if len(days) == 0 {
+			fmt.Println("No data for the specified dates")
+			return nil
+		}
+		if c.Bool("burnup") {
+			fmt.Println("Burnup")
+			fmt.Println(backlog.BacklogView{}.Burnup(days, 84))
+		}
+		if c.Bool("burndown") {
+			fmt.Println("Burndown")
+			fmt.Println(backlog.BacklogView{}.Burndown(days, 84))
+		}
 		return nil
 	},
 }
@@ -0,0 +1,74 @@
+package commands
+
+import (
+	"fmt"
+	"github.com/mreider/agilemarkdown/search"
+	"gopkg.in/urfave/cli.v1"
+	"strings"
+)
+
+var SearchCommand = cli.Command{
+	Name:      "search",
+	Usage:     "Search stories and ideas",
+	ArgsUsage: "TERMS",
+	Flags: []cli.Flag{
+		cli.IntFlag{
+			Name:  "limit",
+			Usage: "Maximum number of results",
+			Value: 20,
+		},
+		cli.BoolFlag{
+			Name:  "rebuild",
+			Usage: "Rebuild the search index from scratch",
+		},
+	},
+	Action: func(c *cli.Context) error {
+		if c.NArg() == 0 {
+			fmt.Printf("Search terms should be specified. Prefix a term with %s: to search only in that field.\n", strings.Join(search.Fields, ":, "))
+			return nil
+		}
+		rootDir, err := findRootDirectory()
+		if err != nil {
+			return err
+		}
+
+		index, err := search.LoadIndex(rootDir)
+		if err != nil {
+			return err
+		}
+		if c.Bool("rebuild") {
+			index = search.NewIndex(rootDir)
+		}
+		changed, err := index.Update()
+		if err != nil {
+			return err
+		}
+		if changed > 0 {
+			if err := index.Save(); err != nil {
+				return err
+			}
+		}
+
+		results, err := index.Search(strings.Join(c.Args(), " "), c.Int("limit"))
+		if err != nil {
+			fmt.Println(err)
+			return nil
+		}
+		if len(results) == 0 {
+			fmt.Println("Nothing found")
+			return nil
+		}
+		for _, result := range results {
+			doc := result.Document
+			status := doc.Status
+			if doc.Kind == search.IdeaKind {
+				status = search.IdeaKind
+			}
+			fmt.Printf("%s [%s] %s\n", doc.Title, status, doc.Path)
+			if result.Snippet != "" {
+				fmt.Printf("    %s\n", result.Snippet)
+			}
+		}
+		return nil
+	},
+}
@@ -0,0 +1,43 @@
+package commands
+
+import (
+	"fmt"
+	"github.com/mreider/agilemarkdown/git"
+	"github.com/mreider/agilemarkdown/server"
+	"gopkg.in/urfave/cli.v1"
+	"net/http"
+	"os"
+)
+
+var ServeCommand = cli.Command{
+	Name:      "serve",
+	Usage:     "Browse and edit backlogs in a web browser",
+	ArgsUsage: " ",
+	Flags: []cli.Flag{
+		cli.StringFlag{
+			Name:  "address",
+			Value: "localhost:8080",
+			Usage: "Address to listen on",
+		},
+		cli.BoolFlag{
+			Name:  "commit",
+			Usage: "Commit every saved story to git",
+		},
+		cli.StringFlag{
+			Name:  "author",
+			Usage: "Author of the commits",
+		},
+	},
+	Action: func(c *cli.Context) error {
+		rootDir, err := findRootDirectory()
+		if err != nil {
+			return err
+		}
+		if err := os.Chdir(rootDir); err != nil {
+			return err
+		}
+
+		fmt.Printf("Serving %s on http://%s\n", rootDir, c.String("address"))
+		return http.ListenAndServe(c.String("address"), server.NewServer(git.NewExecRepository(rootDir), rootDir, c.Bool("commit"), c.String("author")))
+	},
+}
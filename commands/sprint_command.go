@@ -0,0 +1,286 @@
+package commands
+
+import (
+	"fmt"
+	"github.com/mreider/agilemarkdown/backlog"
+	"github.com/mreider/agilemarkdown/utils"
+	"gopkg.in/urfave/cli.v1"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+var SprintCommand = cli.Command{
+	Name:  "sprint",
+	Usage: "Start, close or show a sprint",
+	Subcommands: []cli.Command{
+		{
+			Name:      "start",
+			Usage:     "Start a sprint",
+			ArgsUsage: "SPRINT_NAME",
+			Flags: []cli.Flag{
+				cli.StringFlag{
+					Name:  "goal",
+					Usage: "Sprint goal",
+				},
+				cli.StringFlag{
+					Name:  "start",
+					Usage: "Start date in YYYY-MM-DD format, today by default",
+				},
+				cli.StringFlag{
+					Name:  "end",
+					Usage: "End date in YYYY-MM-DD format",
+				},
+				cli.IntFlag{
+					Name:  "weeks",
+					Usage: "Sprint length in weeks if the end date isn't specified",
+					Value: 2,
+				},
+			},
+			Action: func(c *cli.Context) error {
+				if c.NArg() == 0 {
+					fmt.Println("A sprint name should be specified")
+					return nil
+				}
+				rootDir, err := findRootDirectory()
+				if err != nil {
+					return err
+				}
+				sprints, err := backlog.LoadSprints(filepath.Join(rootDir, backlog.SprintsDirectoryName))
+				if err != nil {
+					return err
+				}
+
+				sprintTitle := strings.Join(c.Args(), " ")
+				sprint := backlog.SprintByName(sprints, utils.GetValidFileName(sprintTitle))
+				if activeSprint := backlog.ActiveSprint(sprints); activeSprint != nil && activeSprint != sprint {
+					fmt.Printf("The sprint '%s' is active. Close it before starting a new one\n", activeSprint.Title())
+					return nil
+				}
+				if sprint == nil {
+					sprint, err = createSprint(rootDir, sprintTitle)
+					if err != nil {
+						return err
+					}
+				}
+
+				start, end := sprint.Start(), sprint.End()
+				if c.String("start") != "" {
+					if start, err = time.Parse(backlog.SprintDateLayout, c.String("start")); err != nil {
+						fmt.Println("Invalid start date. Should be in YYYY-MM-DD format.")
+						return nil
+					}
+				} else if start.IsZero() {
+					now := time.Now()
+					start = time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+				}
+				if c.String("end") != "" {
+					if end, err = time.Parse(backlog.SprintDateLayout, c.String("end")); err != nil {
+						fmt.Println("Invalid end date. Should be in YYYY-MM-DD format.")
+						return nil
+					}
+				} else if end.IsZero() || c.IsSet("weeks") || c.String("start") != "" {
+					end = start.AddDate(0, 0, 7*c.Int("weeks")-1)
+				}
+				if end.Before(start) {
+					fmt.Println("The end date should be after the start date")
+					return nil
+				}
+
+				sprint.SetStart(start)
+				sprint.SetEnd(end)
+				if c.String("goal") != "" {
+					sprint.SetGoal(c.String("goal"))
+				}
+				sprint.SetState(backlog.SprintStateActive)
+				err = sprint.Save()
+				if err != nil {
+					return err
+				}
+				fmt.Printf("The sprint '%s' is started: %s\n", sprint.Title(), sprint.Dates())
+				return nil
+			},
+		},
+		{
+			Name:      "close",
+			Usage:     "Close the active sprint and carry unfinished items over to the next one",
+			ArgsUsage: " ",
+			Flags: []cli.Flag{
+				cli.StringFlag{
+					Name:  "next",
+					Usage: "The sprint for unfinished items, the next planned sprint by default",
+				},
+			},
+			Action: func(c *cli.Context) error {
+				rootDir, err := findRootDirectory()
+				if err != nil {
+					return err
+				}
+				sprints, err := backlog.LoadSprints(filepath.Join(rootDir, backlog.SprintsDirectoryName))
+				if err != nil {
+					return err
+				}
+				activeSprint := backlog.ActiveSprint(sprints)
+				if activeSprint == nil {
+					fmt.Println("There is no active sprint")
+					return nil
+				}
+
+				items, err := loadAllActiveItems(rootDir)
+				if err != nil {
+					return err
+				}
+				var unfinishedItems []*backlog.BacklogItem
+				for _, item := range backlog.NewBacklog(items).FilteredActiveItems(backlog.NewBacklogItemsSprintFilter(activeSprint.Name())) {
+					if !backlog.IsDoneStatusName(item.Status()) {
+						unfinishedItems = append(unfinishedItems, item)
+					}
+				}
+
+				var nextSprint *backlog.Sprint
+				if nextTitle := c.String("next"); nextTitle != "" {
+					nextSprint = backlog.SprintByName(sprints, utils.GetValidFileName(nextTitle))
+					if nextSprint == activeSprint {
+						fmt.Println("The next sprint should differ from the active one")
+						return nil
+					}
+					if nextSprint == nil {
+						nextSprint, err = createSprint(rootDir, nextTitle)
+						if err != nil {
+							return err
+						}
+						length := activeSprint.End().Sub(activeSprint.Start())
+						nextSprint.SetStart(activeSprint.End().AddDate(0, 0, 1))
+						nextSprint.SetEnd(nextSprint.Start().Add(length))
+						nextSprint.SetState(backlog.SprintStatePlanned)
+					}
+				} else {
+					nextSprint = backlog.NextPlannedSprint(sprints, activeSprint)
+				}
+				if nextSprint == nil && len(unfinishedItems) > 0 {
+					fmt.Printf("There is no planned sprint for %d unfinished item(s). Use the --next option to specify it\n", len(unfinishedItems))
+					return nil
+				}
+
+				for _, item := range unfinishedItems {
+					item.SetSprint(nextSprint.Name())
+					err := item.Save()
+					if err != nil {
+						return err
+					}
+				}
+				if nextSprint != nil {
+					err = nextSprint.Save()
+					if err != nil {
+						return err
+					}
+				}
+				activeSprint.SetState(backlog.SprintStateClosed)
+				err = activeSprint.Save()
+				if err != nil {
+					return err
+				}
+
+				fmt.Printf("The sprint '%s' is closed\n", activeSprint.Title())
+				if len(unfinishedItems) > 0 {
+					fmt.Printf("%d unfinished item(s) are moved to the sprint '%s'\n", len(unfinishedItems), nextSprint.Title())
+				}
+				return nil
+			},
+		},
+		{
+			Name:      "show",
+			Usage:     "Show items of a sprint, the active sprint by default",
+			ArgsUsage: "[SPRINT_NAME]",
+			Action: func(c *cli.Context) error {
+				rootDir, err := findRootDirectory()
+				if err != nil {
+					return err
+				}
+				sprints, err := backlog.LoadSprints(filepath.Join(rootDir, backlog.SprintsDirectoryName))
+				if err != nil {
+					return err
+				}
+				var sprint *backlog.Sprint
+				if c.NArg() > 0 {
+					sprint = backlog.SprintByName(sprints, utils.GetValidFileName(strings.Join(c.Args(), " ")))
+					if sprint == nil {
+						fmt.Printf("The sprint '%s' isn't found\n", strings.Join(c.Args(), " "))
+						return nil
+					}
+				} else {
+					sprint = backlog.ActiveSprint(sprints)
+					if sprint == nil {
+						fmt.Println("There is no active sprint")
+						return nil
+					}
+				}
+
+				var items []*backlog.BacklogItem
+				if err := checkIsBacklogDirectory(); err == nil {
+					bck, err := backlog.LoadBacklog(".")
+					if err != nil {
+						return err
+					}
+					items = bck.ActiveItems()
+				} else {
+					items, err = loadAllActiveItems(rootDir)
+					if err != nil {
+						return err
+					}
+				}
+
+				fmt.Printf("Sprint: %s (%s)\n", sprint.Title(), sprint.State())
+				for _, line := range (backlog.BacklogView{}).SprintSummary(sprint, items, time.Now()) {
+					fmt.Println(line)
+				}
+				fmt.Println("")
+
+				sprintBacklog := backlog.NewBacklog(backlog.NewBacklog(items).FilteredActiveItems(backlog.NewBacklogItemsSprintFilter(sprint.Name())))
+				for _, status := range backlog.AllStatuses {
+					statusItems := sprintBacklog.AllItemsByStatus(status.Code)
+					if len(statusItems) == 0 {
+						continue
+					}
+					lines := backlog.BacklogView{}.WriteAsciiItems(statusItems, fmt.Sprintf("Status: %s", status.Name), false)
+					fmt.Println(strings.Join(lines, "\n"))
+					fmt.Println("")
+				}
+				return nil
+			},
+		},
+	},
+}
+
+func createSprint(rootDir, sprintTitle string) (*backlog.Sprint, error) {
+	sprintsDir := filepath.Join(rootDir, backlog.SprintsDirectoryName)
+	err := os.MkdirAll(sprintsDir, 0777)
+	if err != nil {
+		return nil, err
+	}
+	sprint, err := backlog.LoadSprint(filepath.Join(sprintsDir, fmt.Sprintf("%s.md", utils.GetValidFileName(sprintTitle))))
+	if err != nil {
+		return nil, err
+	}
+	sprint.SetTitle(sprintTitle)
+	sprint.SetCreated()
+	sprint.SetGoal("")
+	return sprint, nil
+}
+
+func loadAllActiveItems(rootDir string) ([]*backlog.BacklogItem, error) {
+	backlogDirs, err := findBacklogDirs(rootDir)
+	if err != nil {
+		return nil, err
+	}
+	var items []*backlog.BacklogItem
+	for _, backlogDir := range backlogDirs {
+		bck, err := backlog.LoadBacklog(backlogDir)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, bck.ActiveItems()...)
+	}
+	return items, nil
+}
@@ -33,23 +33,36 @@ func NewSyncCommand() cli.Command {
 			},
 		},
 		Action: func(c *cli.Context) error {
-			action := &SyncAction{testMode: c.Bool("test"), author: c.String("author")}
+			action := NewSyncAction(git.NewExecRepository(""), "", c.String("author"))
+			action.testMode = c.Bool("test")
 			return action.Execute()
 		},
 	}
 }
 
 type SyncAction struct {
+	repo     git.Repository
+	rootDir  string
 	testMode bool
 	author   string
+	// illegalStatusChanges is the number of stories with a status change that isn't allowed, they aren't committed.
+	illegalStatusChanges int
+}
+
+// NewSyncAction creates the sync of the backlogs in rootDir, the current folder is used when it is empty.
+func NewSyncAction(repo git.Repository, rootDir, author string) *SyncAction {
+	return &SyncAction{repo: repo, rootDir: rootDir, author: author}
 }
 
 func (a *SyncAction) Execute() error {
-	rootDir, _ := filepath.Abs(".")
-	if err := checkIsBacklogDirectory(); err == nil {
-		rootDir = filepath.Dir(rootDir)
-	} else if err := checkIsRootDirectory("."); err != nil {
-		return err
+	rootDir := a.rootDir
+	if rootDir == "" {
+		rootDir, _ = filepath.Abs(".")
+		if err := checkIsBacklogDirectory(); err == nil {
+			rootDir = filepath.Dir(rootDir)
+		} else if err := checkIsRootDirectory("."); err != nil {
+			return err
+		}
 	}
 
 	cfgPath := filepath.Join(rootDir, configName)
@@ -77,11 +90,20 @@ func (a *SyncAction) Execute() error {
 			return err
 		}
 
+		err = a.updateSavedQueries(rootDir)
+		if err != nil {
+			return err
+		}
+
+		if a.illegalStatusChanges > 0 {
+			return fmt.Errorf("%d story(ies) have a status change that isn't allowed, nothing is committed or pushed", a.illegalStatusChanges)
+		}
+
 		if a.testMode {
 			return nil
 		}
 
-		ok, err := a.syncToGit()
+		ok, err := a.syncToGit(rootDir, cfg)
 		if err != nil {
 			return err
 		}
@@ -97,6 +119,11 @@ func (a *SyncAction) updateOverviewsAndIndex(rootDir string, cfg *config.Config)
 	if err != nil {
 		return err
 	}
+	changedFiles, err := a.changedFiles()
+	if err != nil {
+		return err
+	}
+	a.illegalStatusChanges = 0
 	indexPath := filepath.Join(rootDir, backlog.IndexFileName)
 	index, err := backlog.LoadGlobalIndex(indexPath)
 	if err != nil {
@@ -110,6 +137,32 @@ func (a *SyncAction) updateOverviewsAndIndex(rootDir string, cfg *config.Config)
 			"",
 		})
 	}
+	sprints, err := backlog.LoadSprints(filepath.Join(rootDir, backlog.SprintsDirectoryName))
+	if err != nil {
+		return err
+	}
+	for _, sprint := range sprints {
+		sprint.UpdateLinks(rootDir)
+	}
+	activeSprint := backlog.ActiveSprint(sprints)
+
+	for _, backlogDir := range backlogDirs {
+		err = a.renameReservedItems(backlogDir)
+		if err != nil {
+			return err
+		}
+		err = a.moveItemsToActiveAndArchiveDirectory(backlogDir)
+		if err != nil {
+			return err
+		}
+	}
+	allItems, err := loadAllBacklogItems(backlogDirs)
+	if err != nil {
+		return err
+	}
+	deps := backlog.NewDependencyGraph(rootDir, allItems)
+	a.updateEpics(backlog.NewEpicList(rootDir, allItems))
+
 	overviews := make([]*backlog.BacklogOverview, 0, len(backlogDirs))
 	archives := make([]*backlog.BacklogOverview, 0, len(backlogDirs))
 	for _, backlogDir := range backlogDirs {
@@ -118,11 +171,6 @@ func (a *SyncAction) updateOverviewsAndIndex(rootDir string, cfg *config.Config)
 			return fmt.Errorf("the overview file isn't found for %s", backlogDir)
 		}
 
-		err = a.moveItemsToActiveAndArchiveDirectory(backlogDir)
-		if err != nil {
-			return err
-		}
-
 		overview, err := backlog.LoadBacklogOverview(overviewPath)
 		if err != nil {
 			return err
@@ -143,9 +191,20 @@ func (a *SyncAction) updateOverviewsAndIndex(rootDir string, cfg *config.Config)
 		archives = append(archives, archive)
 
 		sorter := backlog.NewBacklogItemsSorter(overview, archive)
+		columns := backlog.ColumnFields(bck.Fields())
+		overview.SetColumns(columns)
+		archive.SetColumns(columns)
+		overview.SetDependencies(deps)
+		archive.SetDependencies(deps)
 
 		activeItems := bck.ActiveItems()
-		overview.UpdateLinks("archive", archivePath, rootDir, rootDir)
+		err = a.updateSprintPage(rootDir, backlogDir, overviewPath, activeSprint, activeItems, sorter, deps, columns)
+		if err != nil {
+			return err
+		}
+
+		sprintPath := filepath.Join(backlogDir, backlog.SprintFileName)
+		overview.UpdateLinks(rootDir, rootDir, backlog.PageLink{Title: "archive", Path: archivePath}, backlog.PageLink{Title: "sprint", Path: sprintPath})
 		overview.Update(activeItems, sorter)
 		a.sendNewComments(cfg, rootDir, overview, activeItems)
 		overview.UpdateClarifications(activeItems)
@@ -153,91 +212,484 @@ func (a *SyncAction) updateOverviewsAndIndex(rootDir string, cfg *config.Config)
 
 		archivedItems := bck.ArchivedItems()
 		archive.SetTitle(fmt.Sprintf("Archive: %s", overview.Title()))
-		archive.UpdateLinks("project page", overviewPath, rootDir, backlogDir)
+		archive.UpdateLinks(rootDir, backlogDir, backlog.PageLink{Title: "project page", Path: overviewPath})
 		archive.Update(archivedItems, sorter)
 		archive.UpdateClarifications(archivedItems)
 		archive.Save()
 
-		err = overview.UpdateProgress(bck)
+		history, err := backlog.LoadBacklogHistory(a.repo, backlogDir, bck.AllItems())
+		if err != nil {
+			return err
+		}
+		err = overview.UpdateProgress(bck, history, activeSprint)
 		if err != nil {
 			return err
 		}
+		if overview.HasForecast() {
+			now := time.Now()
+			overview.UpdateForecast(backlog.ForecastBacklog(bck, history, sorter, backlog.ForecastWeeks, backlog.ForecastRuns, now), now)
+		}
+
+		a.reportIllegalStatusChanges(bck.AllItems(), changedFiles)
+		a.reportInvalidFields(rootDir, bck.AllItems())
 
 		for _, item := range bck.AllItems() {
 			item.SetHeader(fmt.Sprintf("Project: %s", overview.Title()))
 			item.UpdateLinks(rootDir, overviewPath, archivePath)
 		}
 	}
+	a.reportDependencyProblems(deps)
 	index.UpdateBacklogs(overviews, archives, rootDir)
 	index.UpdateLinks(rootDir)
 
 	return nil
 }
 
-func (a *SyncAction) syncToGit() (bool, error) {
-	err := git.AddAll()
+func (a *SyncAction) updateSprintPage(rootDir, backlogDir, overviewPath string, sprint *backlog.Sprint, items []*backlog.BacklogItem, sorter *backlog.BacklogItemsSorter, deps *backlog.DependencyGraph, columns []*backlog.BacklogField) error {
+	sprintPath := filepath.Join(backlogDir, backlog.SprintFileName)
+	if sprint == nil {
+		err := os.Remove(sprintPath)
+		if err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	}
+
+	tagsDir := filepath.Join(rootDir, backlog.TagsDirectoryName)
+	lines := []string{
+		fmt.Sprintf("# Sprint: %s", sprint.Title()),
+		"",
+		utils.JoinMarkdownLinks(
+			backlog.MakeIndexLink(rootDir, backlogDir),
+			backlog.MakeIdeasLink(rootDir, backlogDir),
+			backlog.MakeTagsLink(rootDir, backlogDir),
+			utils.MakeMarkdownLink("project page", overviewPath, backlogDir),
+			utils.MakeMarkdownLink("sprint definition", sprint.Path(), backlogDir)),
+		"",
+	}
+	summary := backlog.BacklogView{}.SprintSummary(sprint, items, time.Now())
+	for _, line := range summary {
+		lines = append(lines, line+"  ")
+	}
+	lines = append(lines, "")
+
+	sprintItems := backlog.NewBacklog(items).FilteredActiveItems(backlog.NewBacklogItemsSprintFilter(sprint.Name()))
+	for _, status := range backlog.AllStatuses {
+		statusItems := backlog.NewBacklog(sprintItems).AllItemsByStatus(status.Code)
+		if len(statusItems) == 0 {
+			continue
+		}
+		sorter.SortItemsByStatus(status, statusItems)
+		lines = append(lines, fmt.Sprintf("## %s", status.CapitalizedName()))
+		lines = append(lines, backlog.BacklogView{}.WriteMarkdownItems(statusItems, backlogDir, tagsDir, deps, columns...)...)
+		lines = append(lines, "")
+	}
+	return ioutil.WriteFile(sprintPath, []byte(strings.Join(lines, "\n")), 0644)
+}
+
+// changedFiles returns the absolute paths of the files changed since the last commit.
+func (a *SyncAction) changedFiles() (map[string]bool, error) {
+	files, err := a.repo.ChangedFiles()
+	if err != nil || len(files) == 0 {
+		return nil, err
+	}
+	topDir, err := a.repo.TopLevelDirectory()
+	if err != nil {
+		return nil, err
+	}
+	result := make(map[string]bool, len(files))
+	for _, file := range files {
+		result[filepath.Join(topDir, filepath.FromSlash(file))] = true
+	}
+	return result, nil
+}
+
+func (a *SyncAction) reportIllegalStatusChanges(items []*backlog.BacklogItem, changedFiles map[string]bool) {
+	for _, item := range items {
+		if !changedFiles[item.Path()] {
+			continue
+		}
+		prevContent, ok, err := a.repo.FileContent("HEAD", item.Path())
+		if err != nil || !ok {
+			continue
+		}
+		prevItem := backlog.NewBacklogItem(item.Name(), prevContent)
+		prevStatus, newStatus := backlog.StatusByName(prevItem.Status()), backlog.StatusByName(item.Status())
+		if !backlog.IsAllowedTransition(prevStatus, newStatus) {
+			fmt.Printf("The status of the item '%s' was changed from '%s' to '%s', but this transition isn't allowed\n", item.Title(), prevStatus.Name, newStatus.Name)
+			a.illegalStatusChanges++
+		}
+	}
+}
+
+func (a *SyncAction) updateEpics(epics *backlog.EpicList) {
+	for _, epic := range epics.Epics() {
+		epic.Item.UpdateEpicProgress(backlog.BacklogView{}.WriteMarkdownEpicProgress(epic, filepath.Dir(epic.Item.Path())))
+	}
+	for _, missing := range epics.Missing() {
+		fmt.Printf("The epic '%s' of the item '%s' isn't found\n", missing.Ref, missing.Item.Title())
+	}
+}
+
+func (a *SyncAction) reportDependencyProblems(deps *backlog.DependencyGraph) {
+	for _, missing := range deps.Missing() {
+		fmt.Printf("The item '%s' depends on the item '%s', but it isn't found\n", missing.Item.Title(), missing.Ref)
+	}
+	for _, cycle := range deps.Cycles() {
+		fmt.Printf("The items have cyclic dependencies: %s\n", backlog.BacklogView{}.DependencyCycle(deps, cycle))
+	}
+}
+
+func (a *SyncAction) reportInvalidFields(rootDir string, items []*backlog.BacklogItem) {
+	userList := users.NewUserList(a.repo, filepath.Join(rootDir, backlog.UsersDirectoryName))
+	isKnownUser := func(user string) bool {
+		return userList.User(user) != nil
+	}
+	for _, item := range items {
+		for _, field := range item.Fields() {
+			value := item.FieldValue(field.Name)
+			if err := field.Validate(value, isKnownUser); err != nil {
+				fmt.Printf("The field '%s' of the item '%s' has an invalid value '%s': %v\n", field.Name, item.Title(), value, err)
+			}
+		}
+	}
+}
+
+func (a *SyncAction) syncToGit(rootDir string, cfg *config.Config) (bool, error) {
+	err := a.repo.AddAll(rootDir)
 	if err != nil {
 		return false, err
 	}
-	git.Commit("sync", a.author) // TODO commit message
-	err = git.Fetch()
+	stagedChanges, err := a.repo.StagedChanges()
+	if err != nil {
+		return false, err
+	}
+	if len(stagedChanges) > 0 {
+		err = a.repo.Commit(a.commitMessage(rootDir), a.author)
+		if err != nil {
+			return false, fmt.Errorf("can't commit: %v", err)
+		}
+	}
+	remote, err := a.gitRemote(cfg)
+	if err != nil {
+		return false, err
+	}
+	err = a.repo.Fetch(remote)
 	if err != nil {
 		return false, fmt.Errorf("can't fetch: %v", err)
 	}
-	mergeOutput, mergeErr := git.Merge()
-	if mergeErr != nil {
-		status, _ := git.Status()
-		if !strings.Contains(status, "Your branch is based on 'origin/master', but the upstream is gone.") {
-			conflictFiles, conflictErr := git.ConflictFiles()
-			hasConflictItems := false
-			for _, fileName := range conflictFiles {
-				if fileName == backlog.TagsFileName || strings.HasPrefix(fileName, backlog.TagsDirectoryName+string(os.PathSeparator)) {
+	branch := a.gitBranch(cfg, remote)
+	if branch == "" {
+		return false, errors.New("can't detect the branch to sync, please set GitBranch in the config file")
+	}
+	head, _ := a.repo.Head()
+	if a.repo.RemoteBranchExists(remote, branch) {
+		mergeOutput, mergeErr := a.repo.Merge(remote, branch)
+		if mergeErr != nil {
+			conflictFiles, conflictErr := a.conflictFiles(rootDir)
+			if conflictErr != nil || len(conflictFiles) == 0 {
+				fmt.Println(mergeOutput)
+				return false, a.abortMerge(fmt.Errorf("can't merge: %v", mergeErr))
+			}
+			hasConflicts := false
+			for _, conflictFile := range conflictFiles {
+				if a.isOverviewFile(rootDir, conflictFile) && a.mergeConflictOverview(rootDir, conflictFile) == nil {
 					continue
 				}
-
-				fileName = strings.TrimSuffix(fileName, string(os.PathSeparator)+ArchiveFileName)
-				if strings.Contains(fileName, "/") {
-					hasConflictItems = true
-					break
+				if a.isGeneratedFile(conflictFile) {
+					conflictPath := filepath.Join(rootDir, filepath.FromSlash(conflictFile))
+					err = a.repo.CheckoutOurVersion(conflictPath)
+					if err == nil {
+						err = a.repo.Add(conflictPath)
+					}
+					if err != nil {
+						return false, a.abortMerge(fmt.Errorf("can't merge %s: %v", conflictFile, err))
+					}
+					continue
+				}
+				var conflicts []*backlog.MergeConflict
+				if backlogDir, ok := itemBacklogDirectory(rootDir, conflictFile); ok {
+					conflicts, err = a.mergeConflictItem(rootDir, backlogDir, conflictFile)
+				} else {
+					conflicts, err = a.mergeConflictText(rootDir, conflictFile)
+				}
+				if err != nil {
+					fmt.Println(mergeOutput)
+					return false, a.abortMerge(fmt.Errorf("can't merge %s: %v", conflictFile, err))
+				}
+				for _, conflict := range conflicts {
+					fmt.Printf("Conflict in %s, %s\n", conflictFile, conflict)
+					hasConflicts = true
 				}
 			}
-			if conflictErr != nil || hasConflictItems {
-				fmt.Println(mergeOutput)
-				git.AbortMerge()
-				return false, fmt.Errorf("can't merge: %v", mergeErr)
+			if hasConflicts {
+				return false, a.abortMerge(fmt.Errorf("can't merge: %v", mergeErr))
 			}
-			for _, conflictFile := range conflictFiles {
-				git.CheckoutOurVersion(conflictFile)
-				git.Add(conflictFile)
-				fmt.Printf("Remote changes to %s are ignored\n", conflictFile)
+			err = a.repo.CommitNoEdit(a.author)
+			if err != nil {
+				return false, a.abortMerge(fmt.Errorf("can't commit the merge: %v", err))
 			}
-			git.CommitNoEdit(a.author)
 			return false, nil
 		}
 	}
-	err = git.Push()
+	if newHead, _ := a.repo.Head(); newHead != head {
+		// remote changes are merged, generated pages should be rebuilt from them before pushing
+		return false, nil
+	}
+	err = a.repo.Push(remote, branch)
 	if err != nil {
 		return false, fmt.Errorf("can't push: %v", err)
 	}
+	if _, _, ok := a.repo.Upstream(); !ok {
+		err = a.repo.SetUpstream(remote, branch)
+		if err != nil {
+			return false, fmt.Errorf("can't set the upstream branch: %v", err)
+		}
+	}
 	return true, nil
 }
 
-func (a *SyncAction) backlogDirs(rootDir string) ([]string, error) {
-	infos, err := ioutil.ReadDir(rootDir)
+// abortMerge aborts the merge in progress and returns the error which caused it.
+func (a *SyncAction) abortMerge(err error) error {
+	if abortErr := a.repo.AbortMerge(); abortErr != nil {
+		return fmt.Errorf("%v, can't abort the merge: %v", err, abortErr)
+	}
+	return err
+}
+
+// commitMessage summarizes the staged changes of stories, so git log reads as the activity of the backlogs.
+func (a *SyncAction) commitMessage(rootDir string) string {
+	topDir, err := a.repo.TopLevelDirectory()
+	if err != nil {
+		return "sync"
+	}
+	stagedChanges, err := a.repo.StagedChanges()
+	if err != nil {
+		return "sync"
+	}
+	var changes []*backlog.ItemChange
+	for _, stagedChange := range stagedChanges {
+		itemPath := filepath.Join(topDir, filepath.FromSlash(stagedChange.Path))
+		relPath, err := filepath.Rel(rootDir, itemPath)
+		if err != nil {
+			continue
+		}
+		if _, ok := itemBacklogDirectory(rootDir, filepath.ToSlash(relPath)); !ok {
+			continue
+		}
+		itemName := strings.TrimSuffix(filepath.Base(itemPath), filepath.Ext(itemPath))
+		change := &backlog.ItemChange{}
+		if stagedChange.Status != "A" {
+			prevPath := itemPath
+			if stagedChange.OldPath != "" {
+				prevPath = filepath.Join(topDir, filepath.FromSlash(stagedChange.OldPath))
+			}
+			if content, ok, _ := a.repo.FileContent("HEAD", prevPath); ok {
+				change.Before = backlog.NewBacklogItem(itemName, content)
+			}
+		}
+		if stagedChange.Status != "D" {
+			if content, ok, _ := a.repo.StagedFileContent(0, itemPath); ok {
+				change.After = backlog.NewBacklogItem(itemName, content)
+			}
+		}
+		if change.Before != nil || change.After != nil {
+			changes = append(changes, change)
+		}
+	}
+
+	subject, body := backlog.CommitSummary(a.commitAuthor(rootDir), changes)
+	if len(body) == 0 {
+		return subject
+	}
+	return fmt.Sprintf("%s\n\n%s", subject, strings.Join(body, "\n"))
+}
+
+// commitAuthor returns the nick of the sync author or of the current git user.
+func (a *SyncAction) commitAuthor(rootDir string) string {
+	name, email := a.author, ""
+	if sepIndex := strings.LastIndexByte(a.author, ' '); sepIndex >= 0 && strings.HasPrefix(a.author[sepIndex+1:], "<") {
+		name, email = strings.TrimSpace(a.author[:sepIndex]), strings.Trim(a.author[sepIndex+1:], "<>")
+	}
+	if a.author == "" {
+		name, email, _ = a.repo.CurrentUser()
+	}
+	userList := users.NewUserList(a.repo, filepath.Join(rootDir, backlog.UsersDirectoryName))
+	for _, user := range []string{email, name} {
+		if user == "" {
+			continue
+		}
+		if u := userList.User(user); u != nil {
+			return u.Nick()
+		}
+	}
+	return name
+}
+
+// gitRemote returns the remote from the config, the remote tracked by the current branch, origin or the only remote.
+func (a *SyncAction) gitRemote(cfg *config.Config) (string, error) {
+	if cfg.GitRemote != "" {
+		return cfg.GitRemote, nil
+	}
+	if remote, _, ok := a.repo.Upstream(); ok {
+		return remote, nil
+	}
+	remotes := a.repo.Remotes()
+	if utils.ContainsStringIgnoreCase(remotes, "origin") {
+		return "origin", nil
+	}
+	if len(remotes) == 1 {
+		return remotes[0], nil
+	}
+	if len(remotes) == 0 {
+		return "", errors.New("can't sync: the git repository has no remote")
+	}
+	return "", errors.New("can't detect the remote to sync, please set GitRemote in the config file")
+}
+
+// gitBranch returns the branch from the config, the branch tracked by the current branch, the default branch
+// of the remote or the current branch.
+func (a *SyncAction) gitBranch(cfg *config.Config, remote string) string {
+	if cfg.GitBranch != "" {
+		return cfg.GitBranch
+	}
+	if upstreamRemote, branch, ok := a.repo.Upstream(); ok && upstreamRemote == remote {
+		return branch
+	}
+	if branch := a.repo.DefaultBranch(remote); branch != "" {
+		return branch
+	}
+	return a.repo.CurrentBranch()
+}
+
+// conflictFiles returns the conflict files relative to the root folder, it can be a subfolder of the work tree.
+func (a *SyncAction) conflictFiles(rootDir string) ([]string, error) {
+	topDir, err := a.repo.TopLevelDirectory()
 	if err != nil {
 		return nil, err
 	}
-	result := make([]string, 0, len(infos))
-	for _, info := range infos {
-		if !info.IsDir() || strings.HasPrefix(info.Name(), ".") || backlog.IsForbiddenBacklogName(info.Name()) {
+	files, err := a.repo.ConflictFiles()
+	if err != nil {
+		return nil, err
+	}
+	result := make([]string, 0, len(files))
+	for _, file := range files {
+		if file == "" {
 			continue
 		}
-		result = append(result, filepath.Join(rootDir, info.Name()))
+		relPath, err := filepath.Rel(rootDir, filepath.Join(topDir, filepath.FromSlash(file)))
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, filepath.ToSlash(relPath))
 	}
-	sort.Strings(result)
 	return result, nil
 }
 
+// isGeneratedFile reports whether the file is rebuilt by sync, so ours version can be taken on a conflict.
+func (a *SyncAction) isGeneratedFile(fileName string) bool {
+	if fileName == backlog.TagsFileName || strings.HasPrefix(fileName, backlog.TagsDirectoryName+"/") {
+		return true
+	}
+	fileName = strings.TrimSuffix(fileName, "/"+ArchiveFileName)
+	return !strings.Contains(fileName, "/")
+}
+
+// isOverviewFile reports whether the file is a project page or an archive page where the order of stories is kept.
+func (a *SyncAction) isOverviewFile(rootDir, fileName string) bool {
+	backlogName := strings.TrimSuffix(strings.TrimSuffix(fileName, "/"+ArchiveFileName), ".md")
+	if strings.Contains(backlogName, "/") || backlog.IsForbiddenBacklogName(backlogName) {
+		return false
+	}
+	info, err := os.Stat(filepath.Join(rootDir, backlogName))
+	return err == nil && info.IsDir()
+}
+
+func (a *SyncAction) conflictVersions(rootDir, fileName string) ([]string, error) {
+	var versions []string
+	for stage := 1; stage <= 3; stage++ {
+		content, exists, err := a.repo.StagedFileContent(stage, filepath.Join(rootDir, filepath.FromSlash(fileName)))
+		if err != nil {
+			return nil, err
+		}
+		if !exists && stage > 1 {
+			return nil, errors.New("the file is deleted on one side")
+		}
+		versions = append(versions, content)
+	}
+	return versions, nil
+}
+
+// mergeConflictItem merges the conflicting versions of a story key by key and stages the result.
+func (a *SyncAction) mergeConflictItem(rootDir, backlogDir, fileName string) ([]*backlog.MergeConflict, error) {
+	versions, err := a.conflictVersions(rootDir, fileName)
+	if err != nil {
+		return nil, err
+	}
+	fields, err := backlog.LoadBacklogFields(backlogDir)
+	if err != nil {
+		return nil, err
+	}
+	merged, conflicts := backlog.MergeItemContent(versions[0], versions[1], versions[2], fields)
+	if len(conflicts) > 0 {
+		return conflicts, nil
+	}
+	return nil, a.writeMergedFile(rootDir, fileName, merged)
+}
+
+// mergeConflictText merges the conflicting versions of an idea, a sprint, a saved query or another file
+// line by line and stages the result. The merge is aborted when the same lines are changed on both sides.
+func (a *SyncAction) mergeConflictText(rootDir, fileName string) ([]*backlog.MergeConflict, error) {
+	versions, err := a.conflictVersions(rootDir, fileName)
+	if err != nil {
+		return nil, err
+	}
+	merged, conflict := mergeText(versions[0], versions[1], versions[2])
+	if !conflict {
+		return nil, a.writeMergedFile(rootDir, fileName, merged)
+	}
+
+	var conflicts []*backlog.MergeConflict
+	var conflictLines *[]string
+	var oursLines, theirsLines []string
+	for _, line := range strings.Split(merged, "\n") {
+		switch {
+		case line == utils.MergeOursMarker:
+			oursLines, theirsLines = nil, nil
+			conflictLines = &oursLines
+		case line == utils.MergeSeparator && conflictLines != nil:
+			conflictLines = &theirsLines
+		case line == utils.MergeTheirsMarker && conflictLines != nil:
+			conflicts = append(conflicts, &backlog.MergeConflict{Field: "Lines", Ours: strings.Join(oursLines, "\\n"), Theirs: strings.Join(theirsLines, "\\n")})
+			conflictLines = nil
+		case conflictLines != nil:
+			*conflictLines = append(*conflictLines, strings.TrimSpace(line))
+		}
+	}
+	return conflicts, nil
+}
+
+// mergeConflictOverview keeps the order of stories changed on both sides, the pages are rebuilt later.
+func (a *SyncAction) mergeConflictOverview(rootDir, fileName string) error {
+	versions, err := a.conflictVersions(rootDir, fileName)
+	if err != nil {
+		return err
+	}
+	return a.writeMergedFile(rootDir, fileName, backlog.MergeOverviewContent(versions[0], versions[1], versions[2]))
+}
+
+func (a *SyncAction) writeMergedFile(rootDir, fileName, content string) error {
+	filePath := filepath.Join(rootDir, filepath.FromSlash(fileName))
+	if err := ioutil.WriteFile(filePath, []byte(content), 0644); err != nil {
+		return err
+	}
+	return a.repo.Add(filePath)
+}
+
+func (a *SyncAction) backlogDirs(rootDir string) ([]string, error) {
+	return findBacklogDirs(rootDir)
+}
+
 func (a *SyncAction) updateIdeas(rootDir string) error {
 	ideasDir := filepath.Join(rootDir, backlog.IdeasDirectoryName)
 	ideas, err := backlog.LoadIdeas(ideasDir)
@@ -283,12 +735,12 @@ func (a *SyncAction) updateIdeas(rootDir string) error {
 
 func (a *SyncAction) updateIdea(rootDir string, idea *backlog.BacklogIdea) error {
 	if !idea.HasMetadata() {
-		author, created, err := git.InitCommitInfo(idea.Path())
+		author, created, err := a.repo.InitCommitInfo(idea.Path())
 		if err != nil {
 			return err
 		}
 		if author == "" {
-			author, _, _ = git.CurrentUser()
+			author, _, _ = a.repo.CurrentUser()
 			created = time.Now()
 		}
 
@@ -309,6 +761,39 @@ func (a *SyncAction) updateIdea(rootDir string, idea *backlog.BacklogIdea) error
 	return nil
 }
 
+// renameReservedItems renames the stories which names are reserved for the generated pages, e.g. a story sprint.md
+// created before the sprint page existed. Otherwise such a story is hidden and then replaced by the sprint page.
+func (a *SyncAction) renameReservedItems(backlogDir string) error {
+	for _, dir := range []string{backlogDir, filepath.Join(backlogDir, backlog.ArchiveDirectoryName)} {
+		itemPath := filepath.Join(dir, backlog.SprintFileName)
+		if _, err := os.Stat(itemPath); err != nil {
+			continue
+		}
+		item, err := backlog.LoadBacklogItem(itemPath)
+		if err != nil {
+			return err
+		}
+		if dir == backlogDir && strings.HasPrefix(item.Title(), "Sprint: ") && item.Status() == "" {
+			continue
+		}
+
+		baseName := strings.TrimSuffix(backlog.SprintFileName, ".md") + "-story"
+		newPath := filepath.Join(dir, baseName+".md")
+		for i := 2; ; i++ {
+			if _, err := os.Stat(newPath); os.IsNotExist(err) {
+				break
+			}
+			newPath = filepath.Join(dir, fmt.Sprintf("%s-%d.md", baseName, i))
+		}
+		err = os.Rename(itemPath, newPath)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("The item '%s' is renamed to %s because the name '%s' is reserved for the sprint page\n", item.Title(), filepath.Base(newPath), backlog.SprintFileName)
+	}
+	return nil
+}
+
 func (a *SyncAction) moveItemsToActiveAndArchiveDirectory(backlogDir string) error {
 	bck, err := backlog.LoadBacklog(backlogDir)
 	if err != nil {
@@ -451,6 +936,82 @@ func (a *SyncAction) updateTagPage(rootDir, tagsDir, tag string, items []*backlo
 	return tagFileName, err
 }
 
+func (a *SyncAction) updateSavedQueries(rootDir string) error {
+	queriesDir := filepath.Join(rootDir, backlog.QueriesDirectoryName)
+	queries, err := backlog.LoadSavedQueries(queriesDir)
+	if err != nil {
+		return err
+	}
+	index, err := backlog.LoadGlobalIndex(filepath.Join(rootDir, backlog.IndexFileName))
+	if err != nil {
+		return err
+	}
+	index.UpdateSavedQueries(queries, rootDir)
+	if len(queries) == 0 {
+		return nil
+	}
+
+	backlogDirs, err := a.backlogDirs(rootDir)
+	if err != nil {
+		return err
+	}
+	var fields []*backlog.BacklogField
+	var activeItems []*backlog.BacklogItem
+	overviews := make(map[*backlog.BacklogItem]*backlog.BacklogOverview)
+	for _, backlogDir := range backlogDirs {
+		overviewPath, ok := findOverviewFileInRootDirectory(backlogDir)
+		if !ok {
+			return fmt.Errorf("the overview file isn't found for %s", backlogDir)
+		}
+		overview, err := backlog.LoadBacklogOverview(overviewPath)
+		if err != nil {
+			return err
+		}
+		bck, err := backlog.LoadBacklog(backlogDir)
+		if err != nil {
+			return err
+		}
+		for _, field := range bck.Fields() {
+			if backlog.FieldByName(fields, field.Name) == nil {
+				fields = append(fields, field)
+			}
+		}
+		for _, item := range bck.ActiveItems() {
+			activeItems = append(activeItems, item)
+			overviews[item] = overview
+		}
+	}
+
+	tagsDir := filepath.Join(rootDir, backlog.TagsDirectoryName)
+	for _, query := range queries {
+		query.UpdateLinks(rootDir)
+		filter, err := query.Filter(fields)
+		if err != nil {
+			fmt.Printf("The saved query '%s' is invalid: %v\n", query.Title(), err)
+			query.Update([]string{"", "## Invalid query", "", err.Error()})
+			continue
+		}
+
+		queryItems := backlog.NewBacklog(activeItems).FilteredActiveItems(filter)
+		lines := []string{""}
+		if len(queryItems) == 0 {
+			lines = append(lines, "No stories match the query.")
+		}
+		for _, status := range backlog.AllStatuses {
+			statusItems := backlog.NewBacklog(queryItems).AllItemsByStatus(status.Code)
+			if len(statusItems) == 0 {
+				continue
+			}
+			backlog.NewBacklogItemsSorter().SortItemsByModifiedDesc(statusItems)
+			lines = append(lines, fmt.Sprintf("## %s", status.CapitalizedName()))
+			lines = append(lines, backlog.BacklogView{}.WriteMarkdownItemsWithProject(overviews, statusItems, queriesDir, tagsDir)...)
+			lines = append(lines, "")
+		}
+		query.Update(lines)
+	}
+	return nil
+}
+
 func (a *SyncAction) updateTagsPage(rootDir, tagsDir string, itemsTags map[string][]*backlog.BacklogItem, ideasTags map[string][]*backlog.BacklogIdea) error {
 	allTagsSet := make(map[string]bool)
 	allTags := make([]string, 0, len(itemsTags)+len(ideasTags))
@@ -478,13 +1039,15 @@ func (a *SyncAction) updateTagsPage(rootDir, tagsDir string, itemsTags map[strin
 }
 
 func (a *SyncAction) sendNewComments(cfg *config.Config, rootDir string, overview *backlog.BacklogOverview, activeItems []*backlog.BacklogItem) {
-	userList := users.NewUserList(filepath.Join(rootDir, backlog.UsersDirectoryName))
+	userList := users.NewUserList(a.repo, filepath.Join(rootDir, backlog.UsersDirectoryName))
 	var mailSender *utils.MailSender
 	if cfg.SmtpServer != "" {
 		mailSender = utils.NewMailSender(cfg.SmtpServer, cfg.SmtpUser, cfg.SmtpPassword, cfg.EmailFrom)
 	}
-	remoteOriginUrl, _ := git.RemoteOriginUrl()
-	remoteOriginUrl = strings.TrimSuffix(remoteOriginUrl, ".git")
+	remote, _ := a.gitRemote(cfg)
+	remoteUrl, _ := a.repo.RemoteUrl(remote)
+	remoteUrl = strings.TrimSuffix(remoteUrl, ".git")
+	branch := a.gitBranch(cfg, remote)
 
 	from := a.author
 	sepIndex := strings.LastIndexByte(from, ' ')
@@ -493,7 +1056,7 @@ func (a *SyncAction) sendNewComments(cfg *config.Config, rootDir string, overvie
 		from = strings.Trim(from, "<>")
 	}
 	if from == "" {
-		from, _, _ = git.CurrentUser()
+		from, _, _ = a.repo.CurrentUser()
 	}
 	overview.SendNewComments(activeItems, func(item *backlog.BacklogItem, to []string, comment []string) (me string, err error) {
 		meUser := userList.User(from)
@@ -513,16 +1076,17 @@ func (a *SyncAction) sendNewComments(cfg *config.Config, rootDir string, overvie
 		}
 
 		msgText := strings.Join(comment, "\n")
-		if remoteOriginUrl != "" {
-			var itemGitUrl string
+		if remoteUrl != "" {
 			itemPath := strings.TrimPrefix(item.Path(), rootDir)
 			itemPath = strings.TrimPrefix(itemPath, string(os.PathSeparator))
 			itemPath = strings.Replace(itemPath, string(os.PathSeparator), "/", -1)
-			if cfg.RemoteGitUrlFormat != "" {
-				itemGitUrl = fmt.Sprintf(cfg.RemoteGitUrlFormat, remoteOriginUrl, itemPath)
-			} else {
-				itemGitUrl = fmt.Sprintf("%s/%s", remoteOriginUrl, itemPath)
+			gitPath := itemPath
+			if topDir, err := a.repo.TopLevelDirectory(); err == nil {
+				if relPath, err := filepath.Rel(topDir, item.Path()); err == nil {
+					gitPath = filepath.ToSlash(relPath)
+				}
 			}
+			itemGitUrl := cfg.RemoteGitUrl(remoteUrl, branch, gitPath)
 			msgText += fmt.Sprintf("\n\nView on Git: %s\n", itemGitUrl)
 			if cfg.RemoteWebUrlFormat != "" {
 				itemWebUrl := fmt.Sprintf(cfg.RemoteWebUrlFormat, itemPath)
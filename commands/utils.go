@@ -4,11 +4,15 @@ import (
 	"errors"
 	"fmt"
 	"github.com/mreider/agilemarkdown/backlog"
+	"github.com/mreider/agilemarkdown/config"
 	"github.com/mreider/agilemarkdown/git"
+	"github.com/mreider/agilemarkdown/users"
+	"github.com/mreider/agilemarkdown/utils"
 	"gopkg.in/urfave/cli.v1"
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 	"unicode"
 )
@@ -23,11 +27,30 @@ const (
   "SmtpUser": "",
   "SmtpPassword": "",
   "EmailFrom": "",
-  "RemoteGitUrlFormat": "%s/blob/master/%s",
-  "RemoteWebUrlFormat": ""
+  "RemoteGitUrlFormat": "%s/blob/%s/%s",
+  "RemoteWebUrlFormat": "",
+  "GitRemote": "",
+  "GitBranch": ""
 }`
 )
 
+var queryFlag = cli.StringFlag{
+	Name:  "query",
+	Usage: "Filter stories with a query, e.g. 'status:doing AND (tag:ui OR tag:api) AND points>=3 AND assigned:@me'",
+}
+
+var backlogFlag = cli.StringSliceFlag{
+	Name:  "backlog",
+	Usage: "Backlog name, can be repeated. All backlogs are used by default in the root folder",
+}
+
+type workBacklog struct {
+	dir      string
+	backlog  *backlog.Backlog
+	overview *backlog.BacklogOverview
+	archive  *backlog.BacklogOverview
+}
+
 func checkIsBacklogDirectory() error {
 	_, ok := findOverviewFileInRootDirectory(".")
 	if !ok {
@@ -71,13 +94,62 @@ func findArchiveFileInDirectory(dir string) (string, bool) {
 	return filepath.Join(dir, ArchiveFileName), false
 }
 
+func findRootDirectory() (string, error) {
+	rootDir, _ := filepath.Abs(".")
+	if err := checkIsBacklogDirectory(); err == nil {
+		return filepath.Dir(rootDir), nil
+	}
+	if err := checkIsRootDirectory("."); err != nil {
+		return "", err
+	}
+	return rootDir, nil
+}
+
+func findBacklogDirs(rootDir string) ([]string, error) {
+	infos, err := ioutil.ReadDir(rootDir)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]string, 0, len(infos))
+	for _, info := range infos {
+		if !info.IsDir() || strings.HasPrefix(info.Name(), ".") || backlog.IsForbiddenBacklogName(info.Name()) {
+			continue
+		}
+		result = append(result, filepath.Join(rootDir, info.Name()))
+	}
+	sort.Strings(result)
+	return result, nil
+}
+
 func checkIsRootDirectory(dir string) error {
-	if !git.IsRootGitDirectory(dir) {
+	dir, _ = filepath.Abs(dir)
+	if FindRootDirectory(dir) != dir {
 		return errors.New("Error, please change directory to a root git folder")
 	}
 	return nil
 }
 
+// FindRootDirectory returns the root folder of the backlogs containing dir. It is the root of the git work tree
+// or a folder inside it that has the config file or the generated index and tag list.
+func FindRootDirectory(dir string) string {
+	gitRootDir := git.GetRootGitDirectory(dir)
+	if gitRootDir == "" {
+		return ""
+	}
+	dir, _ = filepath.Abs(dir)
+	for dir != gitRootDir {
+		if existsFile(filepath.Join(dir, configName)) || existsFile(filepath.Join(dir, backlog.IndexFileName)) && existsFile(filepath.Join(dir, backlog.TagsFileName)) {
+			return dir
+		}
+		parentDir := filepath.Dir(dir)
+		if parentDir == dir {
+			break
+		}
+		dir = parentDir
+	}
+	return gitRootDir
+}
+
 func existsFile(path string) bool {
 	info, err := os.Stat(path)
 	if err != nil {
@@ -86,53 +158,161 @@ func existsFile(path string) bool {
 	return !info.IsDir()
 }
 
-func showBacklogItems(c *cli.Context) ([]*backlog.BacklogItem, error) {
+func loadWorkBacklogs(c *cli.Context) ([]*workBacklog, error) {
+	names := c.StringSlice("backlog")
+	var backlogDirs []string
+	if err := checkIsBacklogDirectory(); err == nil && len(names) == 0 {
+		backlogDir, _ := filepath.Abs(".")
+		backlogDirs = []string{backlogDir}
+	} else {
+		rootDir, err := findRootDirectory()
+		if err != nil {
+			return nil, errors.New("Error, please change directory to a backlog folder or a root git folder")
+		}
+		allBacklogDirs, err := findBacklogDirs(rootDir)
+		if err != nil {
+			return nil, err
+		}
+		var backlogNames []string
+		for _, backlogDir := range allBacklogDirs {
+			if _, ok := findOverviewFileInRootDirectory(backlogDir); !ok {
+				continue
+			}
+			if len(names) == 0 || utils.ContainsStringIgnoreCase(names, filepath.Base(backlogDir)) {
+				backlogDirs = append(backlogDirs, backlogDir)
+				backlogNames = append(backlogNames, filepath.Base(backlogDir))
+			}
+		}
+		for _, name := range names {
+			if !utils.ContainsStringIgnoreCase(backlogNames, name) {
+				return nil, fmt.Errorf("unknown backlog: %s", name)
+			}
+		}
+	}
+
+	result := make([]*workBacklog, 0, len(backlogDirs))
+	for _, backlogDir := range backlogDirs {
+		bck, err := backlog.LoadBacklog(backlogDir)
+		if err != nil {
+			return nil, err
+		}
+
+		overviewPath, ok := findOverviewFileInRootDirectory(backlogDir)
+		if !ok {
+			return nil, fmt.Errorf("the overview file isn't found for %s", backlogDir)
+		}
+		overview, err := backlog.LoadBacklogOverview(overviewPath)
+		if err != nil {
+			return nil, err
+		}
+
+		archivePath, _ := findArchiveFileInDirectory(backlogDir)
+		archive, err := backlog.LoadBacklogOverview(archivePath)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, &workBacklog{dir: backlogDir, backlog: bck, overview: overview, archive: archive})
+	}
+	return result, nil
+}
+
+func workBacklogsFields(backlogs []*workBacklog) []*backlog.BacklogField {
+	var fields []*backlog.BacklogField
+	for _, bck := range backlogs {
+		for _, field := range bck.backlog.Fields() {
+			if backlog.FieldByName(fields, field.Name) == nil {
+				fields = append(fields, field)
+			}
+		}
+	}
+	return fields
+}
+
+// filterWorkBacklogsItems returns the active items with the status that match the filter,
+// archived items are returned too when withArchived is set.
+func filterWorkBacklogsItems(backlogs []*workBacklog, status *backlog.BacklogItemStatus, filter backlog.BacklogItemsFilter, withArchived bool) ([]*backlog.BacklogItem, map[*backlog.BacklogItem]*backlog.BacklogOverview) {
+	var items []*backlog.BacklogItem
+	overviews := make(map[*backlog.BacklogItem]*backlog.BacklogOverview)
+	for _, bck := range backlogs {
+		statusFilter := &backlog.BacklogItemsAndFilter{}
+		statusFilter.And(backlog.NewBacklogItemsStatusCodeFilter(status.Code))
+		statusFilter.And(filter)
+		var backlogItems []*backlog.BacklogItem
+		if withArchived {
+			backlogItems = bck.backlog.FilteredItems(statusFilter)
+		} else {
+			backlogItems = bck.backlog.FilteredActiveItems(statusFilter)
+		}
+		backlog.NewBacklogItemsSorter(bck.overview, bck.archive).SortItemsByStatus(status, backlogItems)
+		for _, item := range backlogItems {
+			overviews[item] = bck.overview
+		}
+		items = append(items, backlogItems...)
+	}
+	return items, overviews
+}
+
+func writeAsciiWorkItems(backlogs []*workBacklog, overviews map[*backlog.BacklogItem]*backlog.BacklogOverview, items []*backlog.BacklogItem, title string, withOrderNumber bool) []string {
+	if len(backlogs) > 1 {
+		return backlog.BacklogView{}.WriteAsciiItemsWithProject(overviews, items, title, withOrderNumber)
+	}
+	return backlog.BacklogView{}.WriteAsciiItems(items, title, withOrderNumber)
+}
+
+func showBacklogItems(repo git.Repository, c *cli.Context) ([]*backlog.BacklogItem, error) {
 	statusCode := c.String("s")
+	query := c.String("query")
 
-	if statusCode == "" {
-		fmt.Println("-s option is required")
+	if statusCode == "" && query == "" {
+		fmt.Println("-s or --query option is required")
 		return nil, nil
 	}
-	if !backlog.IsValidStatusCode(statusCode) {
+	if statusCode != "" && !backlog.IsValidStatusCode(statusCode) {
 		fmt.Printf("illegal status: %s\n", statusCode)
 		return nil, nil
 	}
-	if err := checkIsBacklogDirectory(); err != nil {
+	backlogs, err := loadWorkBacklogs(c)
+	if err != nil {
 		fmt.Println(err)
 		return nil, nil
 	}
-	backlogDir, _ := filepath.Abs(".")
-	bck, err := backlog.LoadBacklog(backlogDir)
-	if err != nil {
-		return nil, err
+	if len(backlogs) == 0 {
+		fmt.Println("No backlogs found")
+		return nil, nil
 	}
 
-	overviewPath, ok := findOverviewFileInRootDirectory(backlogDir)
-	if !ok {
-		return nil, fmt.Errorf("the overview file isn't found for %s", backlogDir)
-	}
-	overview, err := backlog.LoadBacklogOverview(overviewPath)
+	queryFilter, err := parseItemsQuery(repo, query, filepath.Dir(backlogs[0].dir), workBacklogsFields(backlogs))
 	if err != nil {
-		return nil, err
+		fmt.Printf("illegal query: %v\n", err)
+		return nil, nil
 	}
 
-	archivePath, _ := findArchiveFileInDirectory(backlogDir)
-	archive, err := backlog.LoadBacklogOverview(archivePath)
-	if err != nil {
-		return nil, err
+	statuses := backlog.AllStatuses
+	title := fmt.Sprintf("Query: %s", query)
+	if statusCode != "" {
+		statuses = []*backlog.BacklogItemStatus{backlog.StatusByCode(statusCode)}
+		title = fmt.Sprintf("Status: %s", statuses[0].Name)
 	}
 
-	filter := backlog.NewBacklogItemsStatusCodeFilter(statusCode)
-	items := bck.FilteredActiveItems(filter)
-	status := backlog.StatusByCode(statusCode)
+	var items []*backlog.BacklogItem
+	overviews := make(map[*backlog.BacklogItem]*backlog.BacklogOverview)
+	for _, status := range statuses {
+		statusItems, statusOverviews := filterWorkBacklogsItems(backlogs, status, queryFilter, backlog.QueryHasKey(query, backlog.QueryArchivedKey))
+		for item, overview := range statusOverviews {
+			overviews[item] = overview
+		}
+		items = append(items, statusItems...)
+	}
 	if len(items) == 0 {
-		fmt.Printf("No items with status '%s'\n", status.Name)
+		if statusCode != "" {
+			fmt.Printf("No items with status '%s'\n", statuses[0].Name)
+		} else {
+			fmt.Println("No items match the query")
+		}
 		return nil, nil
 	}
 
-	sorter := backlog.NewBacklogItemsSorter(overview, archive)
-	sorter.SortItemsByStatus(status, items)
-	lines := backlog.BacklogView{}.WriteAsciiItems(items, fmt.Sprintf("Status: %s", status.Name), true)
+	lines := writeAsciiWorkItems(backlogs, overviews, items, title, true)
 	for _, line := range lines {
 		fmt.Println(line)
 	}
@@ -140,23 +320,86 @@ func showBacklogItems(c *cli.Context) ([]*backlog.BacklogItem, error) {
 	return items, nil
 }
 
-func AddConfigAndGitIgnore(rootDir string) {
+func parseItemsQuery(repo git.Repository, query, rootDir string, fields []*backlog.BacklogField) (backlog.BacklogItemsFilter, error) {
+	if strings.TrimSpace(query) == "" {
+		return &backlog.BacklogItemsTrueFilter{}, nil
+	}
+	name, email, _ := repo.CurrentUser()
+	me := []string{name, email}
+	userList := users.NewUserList(repo, filepath.Join(rootDir, backlog.UsersDirectoryName))
+	if user := userList.User(email); email != "" && user != nil {
+		me = append(me, user.Nick())
+	}
+	return backlog.ParseItemsQuery(query, fields, me...)
+}
+
+func AddConfigAndGitIgnore(repo git.Repository, rootDir string) {
 	hasChanges := false
 
 	configPath := filepath.Join(rootDir, configName)
 	if _, err := os.Stat(configPath); os.IsNotExist(err) {
 		ioutil.WriteFile(configPath, []byte(strings.TrimLeftFunc(defaultConfig, unicode.IsSpace)), 0644)
-		git.Add(configPath)
+		repo.Add(configPath)
 		hasChanges = true
 	}
 	gitIgnorePath := filepath.Join(rootDir, ".gitignore")
 	if _, err := os.Stat(gitIgnorePath); os.IsNotExist(err) {
 		ioutil.WriteFile(gitIgnorePath, []byte(configName), 0644)
-		git.Add(gitIgnorePath)
+		repo.Add(gitIgnorePath)
 		hasChanges = true
 	}
 
 	if hasChanges {
-		git.Commit("configuration", "")
+		repo.Commit("configuration", "")
+	}
+}
+
+func LoadStatuses(rootDir string) error {
+	cfgPath := filepath.Join(rootDir, configName)
+	cfg, err := config.LoadConfig(cfgPath)
+	if err != nil {
+		return fmt.Errorf("can't load the config file %s: %v", cfgPath, err)
+	}
+
+	statuses := make([]*backlog.BacklogItemStatus, 0, len(cfg.Statuses))
+	for _, statusCfg := range cfg.Statuses {
+		statuses = append(statuses, &backlog.BacklogItemStatus{
+			Code:        statusCfg.Code,
+			Name:        statusCfg.Name,
+			Description: statusCfg.Description,
+			Done:        statusCfg.Done,
+			Initial:     statusCfg.Initial,
+			Started:     statusCfg.Started,
+			From:        statusCfg.From,
+		})
+	}
+	return backlog.SetStatuses(statuses)
+}
+
+func DetectMetadataFormat(rootDir string) {
+	backlogDirs, err := findBacklogDirs(rootDir)
+	if err != nil || len(backlogDirs) == 0 {
+		return
+	}
+	overviewPath, ok := findOverviewFileInRootDirectory(backlogDirs[0])
+	if !ok {
+		return
+	}
+	overview, err := backlog.LoadBacklogOverview(overviewPath)
+	if err != nil {
+		return
 	}
+	backlog.UseFrontMatter = overview.FrontMatter()
+}
+
+func loadAllBacklogItems(backlogDirs []string) ([]*backlog.BacklogItem, error) {
+	var items []*backlog.BacklogItem
+	for _, backlogDir := range backlogDirs {
+		bck, err := backlog.LoadBacklog(backlogDir)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, bck.AllItems()...)
+	}
+	return items, nil
 }
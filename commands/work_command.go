@@ -3,90 +3,111 @@ package commands
 import (
 	"fmt"
 	"github.com/mreider/agilemarkdown/backlog"
+	"github.com/mreider/agilemarkdown/git"
 	"gopkg.in/urfave/cli.v1"
 	"path/filepath"
 	"strings"
 )
 
-var WorkCommand = cli.Command{
-	Name:      "work",
-	Usage:     "Show user work by status",
-	ArgsUsage: " ",
-	Flags: []cli.Flag{
-		cli.StringFlag{
-			Name:  "u",
-			Usage: "User Name",
+func NewWorkCommand() cli.Command {
+	return cli.Command{
+		Name:      "work",
+		Usage:     "Show user work by status",
+		ArgsUsage: " ",
+		Flags: []cli.Flag{
+			cli.StringFlag{
+				Name:  "u",
+				Usage: "User Name",
+			},
+			cli.StringFlag{
+				Name:  "s",
+				Usage: fmt.Sprintf("Status - %s", backlog.AllStatusesList()),
+			},
+			cli.StringFlag{
+				Name:  "t",
+				Usage: "List of Tags",
+			},
+			cli.StringSliceFlag{
+				Name:  "f",
+				Usage: "Custom field filter in NAME=VALUE format, can be repeated",
+			},
+			queryFlag,
+			backlogFlag,
 		},
-		cli.StringFlag{
-			Name:  "s",
-			Usage: fmt.Sprintf("Status - %s", backlog.AllStatusesList()),
-		},
-		cli.StringFlag{
-			Name:  "t",
-			Usage: "List of Tags",
-		},
-	},
-	Action: func(c *cli.Context) error {
-		user := c.String("u")
-		statusCode := c.String("s")
-		tags := c.String("t")
+		Action: func(c *cli.Context) error {
+			repo := git.NewExecRepository("")
+			user := c.String("u")
+			statusCode := c.String("s")
+			tags := c.String("t")
 
-		if c.NArg() > 0 {
-			fmt.Printf("illegal arguments: %s\n", strings.Join(c.Args(), " "))
-			return nil
-		}
+			if c.NArg() > 0 {
+				fmt.Printf("illegal arguments: %s\n", strings.Join(c.Args(), " "))
+				return nil
+			}
 
-		if statusCode != "" && !backlog.IsValidStatusCode(statusCode) {
-			fmt.Printf("illegal status: %s\n", statusCode)
-			return nil
-		}
+			if statusCode != "" && !backlog.IsValidStatusCode(statusCode) {
+				fmt.Printf("illegal status: %s\n", statusCode)
+				return nil
+			}
 
-		if err := checkIsBacklogDirectory(); err != nil {
-			fmt.Println(err)
-			return nil
-		}
-		backlogDir, _ := filepath.Abs(".")
-		bck, err := backlog.LoadBacklog(backlogDir)
-		if err != nil {
-			return err
-		}
+			backlogs, err := loadWorkBacklogs(c)
+			if err != nil {
+				fmt.Println(err)
+				return nil
+			}
+			if len(backlogs) == 0 {
+				fmt.Println("No backlogs found")
+				return nil
+			}
+			fields := workBacklogsFields(backlogs)
 
-		overviewPath, ok := findOverviewFileInRootDirectory(backlogDir)
-		if !ok {
-			return fmt.Errorf("the overview file isn't found for %s", backlogDir)
-		}
-		overview, err := backlog.LoadBacklogOverview(overviewPath)
-		if err != nil {
-			return err
-		}
+			fieldsFilter := &backlog.BacklogItemsAndFilter{}
+			for _, fieldFilter := range c.StringSlice("f") {
+				parts := strings.SplitN(fieldFilter, "=", 2)
+				if len(parts) != 2 {
+					fmt.Printf("illegal field filter: %s\n", fieldFilter)
+					return nil
+				}
+				field := backlog.FieldByName(fields, strings.TrimSpace(parts[0]))
+				if field == nil {
+					fmt.Printf("unknown field: %s\n", strings.TrimSpace(parts[0]))
+					return nil
+				}
+				fieldsFilter.And(backlog.NewBacklogItemsFieldFilter(field, parts[1]))
+			}
 
-		archivePath, _ := findArchiveFileInDirectory(backlogDir)
-		archive, err := backlog.LoadBacklogOverview(archivePath)
-		if err != nil {
-			return err
-		}
+			queryFilter, err := parseItemsQuery(repo, c.String("query"), filepath.Dir(backlogs[0].dir), fields)
+			if err != nil {
+				fmt.Printf("illegal query: %v\n", err)
+				return nil
+			}
 
-		var statuses []*backlog.BacklogItemStatus
-		if statusCode == "" {
-			statuses = []*backlog.BacklogItemStatus{backlog.DoingStatus, backlog.PlannedStatus, backlog.UnplannedStatus}
-		} else {
-			statuses = []*backlog.BacklogItemStatus{backlog.StatusByCode(statusCode)}
-		}
+			var statuses []*backlog.BacklogItemStatus
+			if statusCode == "" && c.String("query") != "" {
+				statuses = backlog.AllStatuses
+			} else if statusCode == "" {
+				statuses = backlog.UnfinishedStatuses()
+			} else {
+				statuses = []*backlog.BacklogItemStatus{backlog.StatusByCode(statusCode)}
+			}
 
-		sorter := backlog.NewBacklogItemsSorter(overview, archive)
-		for _, status := range statuses {
-			filter := &backlog.BacklogItemsAndFilter{}
-			filter.And(backlog.NewBacklogItemsStatusCodeFilter(status.Code))
-			filter.And(backlog.NewBacklogItemsAssignedFilter(user))
-			filter.And(backlog.NewBacklogItemsTagsFilter(tags))
-			items := bck.FilteredActiveItems(filter)
+			for _, status := range statuses {
+				filter := &backlog.BacklogItemsAndFilter{}
+				filter.And(backlog.NewBacklogItemsAssignedFilter(user))
+				filter.And(backlog.NewBacklogItemsTagsFilter(tags))
+				filter.And(fieldsFilter)
+				filter.And(queryFilter)
+				items, overviews := filterWorkBacklogsItems(backlogs, status, filter, backlog.QueryHasKey(c.String("query"), backlog.QueryArchivedKey))
+				if len(items) == 0 && c.String("query") != "" {
+					continue
+				}
 
-			sorter.SortItemsByStatus(status, items)
-			lines := backlog.BacklogView{}.WriteAsciiItems(items, fmt.Sprintf("Status: %s", status.Name), false)
-			fmt.Println(strings.Join(lines, "\n"))
-			fmt.Println("")
-		}
+				lines := writeAsciiWorkItems(backlogs, overviews, items, fmt.Sprintf("Status: %s", status.Name), false)
+				fmt.Println(strings.Join(lines, "\n"))
+				fmt.Println("")
+			}
 
-		return nil
-	},
+			return nil
+		},
+	}
 }
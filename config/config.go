@@ -2,17 +2,63 @@ package config
 
 import (
 	"encoding/json"
+	"fmt"
 	"io/ioutil"
 	"os"
+	"strings"
 )
 
 type Config struct {
-	SmtpServer         string `json:"SmtpServer"`
-	SmtpUser           string `json:"SmtpUser"`
-	SmtpPassword       string `json:"SmtpPassword"`
-	EmailFrom          string `json:"EmailFrom"`
-	RemoteGitUrlFormat string `json:"RemoteGitUrlFormat"`
-	RemoteWebUrlFormat string `json:"RemoteWebUrlFormat"`
+	SmtpServer         string                     `json:"SmtpServer"`
+	SmtpUser           string                     `json:"SmtpUser"`
+	SmtpPassword       string                     `json:"SmtpPassword"`
+	EmailFrom          string                     `json:"EmailFrom"`
+	RemoteGitUrlFormat string                     `json:"RemoteGitUrlFormat"`
+	RemoteWebUrlFormat string                     `json:"RemoteWebUrlFormat"`
+	GitRemote          string                     `json:"GitRemote"`
+	GitBranch          string                     `json:"GitBranch"`
+	Statuses           []*StatusConfig            `json:"Statuses"`
+	Importers          map[string]*ImporterConfig `json:"Importers"`
+}
+
+type ImporterConfig struct {
+	Statuses map[string]string `json:"Statuses"`
+	Users    map[string]string `json:"Users"`
+}
+
+type StatusConfig struct {
+	Code        string   `json:"Code"`
+	Name        string   `json:"Name"`
+	Description string   `json:"Description"`
+	Done        bool     `json:"Done"`
+	Initial     bool     `json:"Initial"`
+	Started     bool     `json:"Started"`
+	From        []string `json:"From"`
+}
+
+type FieldConfig struct {
+	Name   string   `json:"Name"`
+	Type   string   `json:"Type"`
+	Values []string `json:"Values"`
+	Column bool     `json:"Column"`
+}
+
+func LoadFieldsConfig(fieldsPath string) ([]*FieldConfig, error) {
+	content, err := ioutil.ReadFile(fieldsPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var fields []*FieldConfig
+	err = json.Unmarshal(content, &fields)
+	if err != nil {
+		return nil, err
+	}
+
+	return fields, nil
 }
 
 func LoadConfig(configPath string) (*Config, error) {
@@ -36,3 +82,15 @@ func LoadConfig(configPath string) (*Config, error) {
 
 	return config, nil
 }
+
+// RemoteGitUrl formats the link to a file in the remote repository.
+// RemoteGitUrlFormat takes the remote url, the branch and the path, or the remote url and the path only.
+func (config *Config) RemoteGitUrl(remoteUrl, branch, path string) string {
+	switch strings.Count(config.RemoteGitUrlFormat, "%s") {
+	case 3:
+		return fmt.Sprintf(config.RemoteGitUrlFormat, remoteUrl, branch, path)
+	case 2:
+		return fmt.Sprintf(config.RemoteGitUrlFormat, remoteUrl, path)
+	}
+	return fmt.Sprintf("%s/%s", remoteUrl, path)
+}
@@ -0,0 +1,333 @@
+package git
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// ExecRepository runs the git binary in the directory, the current one when it is empty.
+type ExecRepository struct {
+	dir string
+}
+
+func NewExecRepository(dir string) *ExecRepository {
+	return &ExecRepository{dir: dir}
+}
+
+func (repo *ExecRepository) CurrentUser() (name, email string, err error) {
+	name, err = repo.run("config", "user.name")
+	if err != nil {
+		return "", "", err
+	}
+	email, err = repo.run("config", "user.email")
+	if err != nil {
+		return "", "", err
+	}
+	return name, email, nil
+}
+
+func (repo *ExecRepository) KnownUsers() (names, emails []string, err error) {
+	output, err := repo.run("shortlog", "--summary", "-e", "HEAD")
+	if err != nil {
+		return nil, nil, err
+	}
+	lines := strings.Split(output, "\n")
+	userNames := make([]string, 0, len(lines))
+	userEmails := make([]string, 0, len(lines))
+	for _, line := range lines {
+		line = strings.TrimSpace(line)
+		match := usersRe.FindStringSubmatch(line)
+		if match != nil {
+			userNames = append(userNames, match[1])
+			userEmails = append(userEmails, match[2])
+		}
+	}
+	return userNames, userEmails, nil
+}
+
+func (repo *ExecRepository) SetConfig(key, value string) error {
+	_, err := repo.run("config", key, value)
+	return err
+}
+
+func (repo *ExecRepository) Init() error {
+	_, err := repo.run("init")
+	return err
+}
+
+func (repo *ExecRepository) Add(fileName string) error {
+	_, err := repo.run("add", fileName)
+	return err
+}
+
+func (repo *ExecRepository) AddAll(dir string) error {
+	_, err := repo.run("add", "-A", "--", dir)
+	return err
+}
+
+func (repo *ExecRepository) Commit(msg, author string) error {
+	args := []string{"commit", "-m", msg}
+	if author != "" {
+		args = append(args, "--author", author)
+	}
+	_, err := repo.run(args...)
+	return err
+}
+
+func (repo *ExecRepository) CommitNoEdit(author string) error {
+	args := []string{"commit", "--no-edit"}
+	if author != "" {
+		args = append(args, "--author", author)
+	}
+	_, err := repo.run(args...)
+	return err
+}
+
+func (repo *ExecRepository) Head() (string, error) {
+	return repo.run("rev-parse", "HEAD")
+}
+
+func (repo *ExecRepository) StagedChanges() ([]*StagedChange, error) {
+	out, err := repo.runRaw("diff", "--cached", "--name-status", "-M", "-z")
+	if err != nil {
+		return nil, err
+	}
+	var changes []*StagedChange
+	fields := strings.Split(strings.TrimSuffix(out, "\x00"), "\x00")
+	for i := 0; i+1 < len(fields); i += 2 {
+		change := &StagedChange{Status: fields[i][:1], Path: fields[i+1]}
+		if (change.Status == "R" || change.Status == "C") && i+2 < len(fields) {
+			change.OldPath, change.Path = change.Path, fields[i+2]
+			i++
+		}
+		changes = append(changes, change)
+	}
+	return changes, nil
+}
+
+// ChangedFiles returns the files changed since HEAD, the paths are relative to the root of the work tree.
+func (repo *ExecRepository) ChangedFiles() ([]string, error) {
+	if _, err := repo.Head(); err != nil {
+		return nil, nil
+	}
+	out, err := repo.runRaw("diff", "--name-only", "-z", "HEAD")
+	if err != nil {
+		return nil, err
+	}
+	out = strings.TrimSuffix(out, "\x00")
+	if out == "" {
+		return nil, nil
+	}
+	return strings.Split(out, "\x00"), nil
+}
+
+func (repo *ExecRepository) Fetch(remote string) error {
+	_, err := repo.run("fetch", remote)
+	return err
+}
+
+func (repo *ExecRepository) Merge(remote, branch string) (string, error) {
+	return repo.run("merge", "--commit", remote+"/"+branch)
+}
+
+func (repo *ExecRepository) AbortMerge() error {
+	_, err := repo.run("merge", "--abort")
+	return err
+}
+
+func (repo *ExecRepository) Push(remote, branch string) error {
+	_, err := repo.run("push", remote, "HEAD:"+branch)
+	return err
+}
+
+func (repo *ExecRepository) SetUpstream(remote, branch string) error {
+	_, err := repo.run("branch", "--set-upstream-to", remote+"/"+branch)
+	return err
+}
+
+func (repo *ExecRepository) Upstream() (remote, branch string, ok bool) {
+	out, err := repo.run("rev-parse", "--abbrev-ref", "--symbolic-full-name", "@{u}")
+	if err != nil {
+		return "", "", false
+	}
+	remote, err = repo.run("config", "--get", fmt.Sprintf("branch.%s.remote", repo.CurrentBranch()))
+	if err != nil || !strings.HasPrefix(out, remote+"/") {
+		return "", "", false
+	}
+	return remote, strings.TrimPrefix(out, remote+"/"), true
+}
+
+func (repo *ExecRepository) CurrentBranch() string {
+	out, err := repo.run("symbolic-ref", "--short", "HEAD")
+	if err != nil {
+		return ""
+	}
+	return out
+}
+
+func (repo *ExecRepository) Remotes() []string {
+	out, err := repo.run("remote")
+	if err != nil || out == "" {
+		return nil
+	}
+	return strings.Split(out, "\n")
+}
+
+func (repo *ExecRepository) RemoteUrl(remote string) (string, error) {
+	return repo.run("config", "--get", fmt.Sprintf("remote.%s.url", remote))
+}
+
+func (repo *ExecRepository) DefaultBranch(remote string) string {
+	out, err := repo.run("symbolic-ref", "--short", fmt.Sprintf("refs/remotes/%s/HEAD", remote))
+	if err == nil {
+		return strings.TrimPrefix(out, remote+"/")
+	}
+	out, err = repo.run("ls-remote", "--symref", remote, "HEAD")
+	if err != nil {
+		return ""
+	}
+	for _, line := range strings.Split(out, "\n") {
+		if fields := strings.Fields(line); len(fields) == 3 && fields[0] == "ref:" && fields[2] == "HEAD" {
+			return strings.TrimPrefix(fields[1], "refs/heads/")
+		}
+	}
+	return ""
+}
+
+func (repo *ExecRepository) RemoteBranchExists(remote, branch string) bool {
+	_, err := repo.run("rev-parse", "--verify", "--quiet", fmt.Sprintf("refs/remotes/%s/%s", remote, branch))
+	return err == nil
+}
+
+func (repo *ExecRepository) ConflictFiles() ([]string, error) {
+	out, err := repo.run("diff", "--name-only", "--diff-filter=U")
+	if err != nil {
+		return nil, err
+	}
+	if out == "" {
+		return nil, nil
+	}
+	return strings.Split(out, "\n"), nil
+}
+
+func (repo *ExecRepository) CheckoutOurVersion(fileName string) error {
+	_, err := repo.run("checkout", "--ours", fileName)
+	return err
+}
+
+func (repo *ExecRepository) StagedFileContent(stage int, fileName string) (content string, exists bool, err error) {
+	relPath, err := repo.relPath(fileName)
+	if err != nil {
+		return "", false, err
+	}
+	out, errOut, err := repo.runSeparateOutput("show", fmt.Sprintf(":%d:./%s", stage, relPath))
+	if err != nil {
+		if strings.Contains(errOut, "does not exist") || strings.Contains(errOut, "is in the index, but not at stage") {
+			return "", false, nil
+		}
+		return "", false, commandError("show", errOut, err)
+	}
+	return out, true, nil
+}
+
+func (repo *ExecRepository) TopLevelDirectory() (string, error) {
+	out, err := repo.run("rev-parse", "--show-toplevel")
+	if err != nil {
+		return "", err
+	}
+	return filepath.Abs(filepath.FromSlash(out))
+}
+
+func (repo *ExecRepository) FileContent(revision, fileName string) (content string, exists bool, err error) {
+	relPath, err := repo.relPath(fileName)
+	if err != nil {
+		return "", false, err
+	}
+	out, errOut, err := repo.runSeparateOutput("show", fmt.Sprintf("%s:./%s", revision, relPath))
+	if err != nil {
+		if strings.Contains(errOut, "does not exist") || strings.Contains(errOut, "exists on disk, but not in") || strings.Contains(errOut, "invalid object name") {
+			return "", false, nil
+		}
+		return "", false, commandError("show", errOut, err)
+	}
+	return out, true, nil
+}
+
+func (repo *ExecRepository) FileChanges(paths ...string) ([]*FileChange, error) {
+	args := []string{"log", "-p", "-M", fmt.Sprintf("--unified=%d", fullContextLines), "--reverse", "--no-color", "--no-ext-diff", "--format=format:%x00%H|%an|%ai", "--"}
+	args = append(args, paths...)
+	out, errOut, err := repo.runSeparateOutput(args...)
+	if err != nil {
+		if strings.Contains(errOut, "does not have any commits") {
+			return nil, nil
+		}
+		return nil, commandError("log", errOut, err)
+	}
+	return parseFileChanges(out), nil
+}
+
+func (repo *ExecRepository) InitCommitInfo(fileName string) (user string, created time.Time, err error) {
+	out, err := repo.run("log", "--reverse", "--format=format:%an|%ai", "--follow", "--", fileName)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	firstLine := strings.TrimSpace(strings.SplitN(out, "\n", 2)[0])
+	if firstLine == "" {
+		return "", time.Time{}, nil
+	}
+	parts := strings.SplitN(firstLine, "|", 2)
+	user = parts[0]
+	created, _ = time.Parse(gitDateLayout, parts[1])
+	return user, created, nil
+}
+
+// relPath returns the path of the file relative to the directory of the repository.
+func (repo *ExecRepository) relPath(fileName string) (string, error) {
+	dir, _ := filepath.Abs(repo.dir)
+	fileName, _ = filepath.Abs(fileName)
+	relPath, err := filepath.Rel(dir, fileName)
+	if err != nil {
+		return "", err
+	}
+	return filepath.ToSlash(relPath), nil
+}
+
+// run returns the trimmed output, stderr is returned as a part of the error.
+func (repo *ExecRepository) run(args ...string) (string, error) {
+	out, err := repo.runRaw(args...)
+	return strings.TrimSpace(out), err
+}
+
+func (repo *ExecRepository) runRaw(args ...string) (string, error) {
+	out, errOut, err := repo.runSeparateOutput(args...)
+	if err != nil {
+		return out, commandError(args[0], errOut, err)
+	}
+	return out, nil
+}
+
+func (repo *ExecRepository) runSeparateOutput(args ...string) (string, string, error) {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = repo.dir
+	// The messages in stderr are checked to detect missing files, so they shouldn't be translated.
+	cmd.Env = append(os.Environ(), "LC_ALL=C")
+	var out bytes.Buffer
+	var stderr bytes.Buffer
+	cmd.Stdout = &out
+	cmd.Stderr = &stderr
+	err := cmd.Run()
+	return out.String(), stderr.String(), err
+}
+
+func commandError(command, errOut string, err error) error {
+	errOut = strings.TrimSpace(errOut)
+	if errOut == "" {
+		return fmt.Errorf("git %s: %v", command, err)
+	}
+	return fmt.Errorf("git %s: %v: %s", command, err, errOut)
+}
@@ -1,157 +1,43 @@
 package git
 
 import (
-	"bytes"
 	"os"
-	"os/exec"
 	"path/filepath"
 	"regexp"
+	"strconv"
 	"strings"
 	"time"
 )
 
+const (
+	commitLinePrefix = "\x00"
+	gitDateLayout    = "2006-01-02 15:04:05 -0700"
+	// fullContextLines makes the diff of a changed file include all its lines.
+	fullContextLines = 1000000
+)
+
 var (
 	usersRe = regexp.MustCompile(`^\d+\s+(.*)\s+<([^>]+)>$`)
 )
 
-func CurrentUser() (name, email string, err error) {
-	name, err = runGitCommand([]string{"config", "user.name"})
-	if err != nil {
-		return "", "", nil
-	}
-	email, err = runGitCommand([]string{"config", "user.email"})
-	if err != nil {
-		return "", "", nil
-	}
-	return name, email, nil
-}
-
-func KnownUsers() (names, emails []string, err error) {
-	args := []string{"shortlog", "--summary", "-e", "HEAD"}
-	output, err := runGitCommand(args)
-	if err != nil {
-		return nil, nil, err
-	}
-	lines := strings.Split(output, "\n")
-	userNames := make([]string, 0, len(lines))
-	userEmails := make([]string, 0, len(lines))
-	for _, line := range lines {
-		line = strings.TrimSpace(line)
-		match := usersRe.FindStringSubmatch(line)
-		if match != nil {
-			userNames = append(userNames, match[1])
-			userEmails = append(userEmails, match[2])
-		}
-	}
-	return userNames, userEmails, nil
-}
-
-func AddAll() error {
-	args := []string{"add", "-A"}
-	_, err := runGitCommand(args)
-	return err
-}
-
-func Add(fileName string) error {
-	args := []string{"add", fileName}
-	_, err := runGitCommand(args)
-	return err
-}
-
-func Commit(msg, author string) error {
-	args := []string{"commit", "-m", msg}
-	if author != "" {
-		args = append(args, "--author", author)
-	}
-	_, err := runGitCommand(args)
-	return err
-}
-
-func CommitNoEdit(author string) error {
-	args := []string{"commit", "--no-edit"}
-	if author != "" {
-		args = append(args, "--author", author)
-	}
-	_, err := runGitCommand(args)
-	return err
-}
-
-func Fetch() error {
-	args := []string{"fetch"}
-	_, err := runGitCommand(args)
-	return err
-}
-
-func Merge() (string, error) {
-	args := []string{"merge", "--commit"}
-	return runGitCommand(args)
+type FileChange struct {
+	Hash    string
+	Author  string
+	Date    time.Time
+	Path    string
+	OldPath string
+	Deleted bool
+	Added   []string
+	Removed []string
+	// Content is the file after the change, it's empty when the change only renames or deletes the file.
+	Content []string
 }
 
-func AbortMerge() error {
-	args := []string{"merge", "--abort"}
-	_, err := runGitCommand(args)
-	return err
-}
-
-func Push() error {
-	args := []string{"push"}
-	_, err := runGitCommand(args)
-	return err
-}
-
-func SetUpstream() error {
-	args := []string{"branch", "--set-upstream-to", "origin"}
-	_, err := runGitCommand(args)
-	return err
-}
-
-func Status() (string, error) {
-	args := []string{"status"}
-	return runGitCommand(args)
-}
-
-func ConflictFiles() ([]string, error) {
-	args := []string{"diff", "--name-only", "--diff-filter=U"}
-	out, err := runGitCommand(args)
-	if err != nil {
-		return nil, err
-	}
-	return strings.Split(out, "\n"), nil
-}
-
-func CheckoutOurVersion(fileName string) error {
-	args := []string{"checkout", "--ours", fileName}
-	_, err := runGitCommand(args)
-	return err
-}
-
-func InitCommitInfo(fileName string) (user string, created time.Time, err error) {
-	args := []string{"log", "--reverse", "--format=format:%an|%ai", "--follow", "--", fileName}
-	out, err := runGitCommand(args)
-	if err != nil {
-		return "", time.Time{}, err
-	}
-	firstLine := strings.TrimSpace(strings.SplitN(out, "\n", 2)[0])
-	if firstLine == "" {
-		return "", time.Time{}, nil
-	}
-	parts := strings.SplitN(firstLine, "|", 2)
-	user = parts[0]
-	created, _ = time.Parse("2006-01-02 15:04:05 -0700", parts[1])
-	return user, created, nil
-}
-
-func RemoteOriginUrl() (url string, err error) {
-	url, err = runGitCommand([]string{"config", "--get", "remote.origin.url"})
-	if err != nil {
-		return "", nil
-	}
-	return url, nil
-}
-
-func Init() error {
-	_, err := runGitCommand([]string{"init"})
-	return err
+// StagedChange is a file staged for commit. The paths are relative to the root of the work tree.
+type StagedChange struct {
+	Status  string
+	Path    string
+	OldPath string
 }
 
 func GetRootGitDirectory(dir string) string {
@@ -174,19 +60,61 @@ func IsRootGitDirectory(dir string) bool {
 	return err == nil
 }
 
-func runGitCommand(args []string) (string, error) {
-	cmd := exec.Command("git", args...)
-	var out bytes.Buffer
-	var stderr bytes.Buffer
-	cmd.Stdout = &out
-	cmd.Stderr = &stderr
-	err := cmd.Run()
-	lines := make([]string, 0, 2)
-	if out.Len() > 0 {
-		lines = append(lines, out.String())
+func parseFileChanges(out string) []*FileChange {
+	var changes []*FileChange
+	var hash, author string
+	var date time.Time
+	var change *FileChange
+	inHunk := false
+	for _, line := range strings.Split(out, "\n") {
+		switch {
+		case strings.HasPrefix(line, commitLinePrefix):
+			parts := strings.SplitN(strings.TrimPrefix(line, commitLinePrefix), "|", 3)
+			if len(parts) == 3 {
+				hash, author = parts[0], parts[1]
+				date, _ = time.Parse(gitDateLayout, parts[2])
+			}
+			change = nil
+		case strings.HasPrefix(line, "diff --git "):
+			change = &FileChange{Hash: hash, Author: author, Date: date}
+			changes = append(changes, change)
+			inHunk = false
+			header := strings.TrimPrefix(line, "diff --git ")
+			if sepIndex := strings.Index(header, ` "b/`); sepIndex >= 0 {
+				change.OldPath = strings.TrimPrefix(unquoteGitPath(header[:sepIndex]), "a/")
+				change.Path = strings.TrimPrefix(unquoteGitPath(header[sepIndex+1:]), "b/")
+			} else if sepIndex := strings.Index(header, " b/"); sepIndex >= 0 {
+				change.OldPath = strings.TrimPrefix(header[:sepIndex], "a/")
+				change.Path = header[sepIndex+len(" b/"):]
+			}
+		case change == nil:
+		case inHunk && strings.HasPrefix(line, "+"):
+			change.Added = append(change.Added, line[1:])
+			change.Content = append(change.Content, line[1:])
+		case inHunk && strings.HasPrefix(line, "-"):
+			change.Removed = append(change.Removed, line[1:])
+		case strings.HasPrefix(line, "@@"):
+			inHunk = true
+		case inHunk && strings.HasPrefix(line, " "):
+			change.Content = append(change.Content, line[1:])
+		case inHunk:
+		case strings.HasPrefix(line, "rename from "):
+			change.OldPath = unquoteGitPath(strings.TrimPrefix(line, "rename from "))
+		case strings.HasPrefix(line, "rename to "):
+			change.Path = unquoteGitPath(strings.TrimPrefix(line, "rename to "))
+		case strings.HasPrefix(line, "new file mode"):
+			change.OldPath = ""
+		case strings.HasPrefix(line, "deleted file mode"):
+			change.Deleted = true
+		}
 	}
-	if stderr.Len() > 0 {
-		lines = append(lines, stderr.String())
+	return changes
+}
+
+func unquoteGitPath(path string) string {
+	path = strings.TrimSpace(path)
+	if unquoted, err := strconv.Unquote(path); err == nil {
+		return unquoted
 	}
-	return strings.TrimSpace(strings.Join(lines, "\n")), err
+	return path
 }
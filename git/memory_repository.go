@@ -0,0 +1,783 @@
+package git
+
+import (
+	"crypto/sha1"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+var memoryCommitCounter int64
+
+type memoryCommit struct {
+	hash    string
+	seq     int64
+	parents []*memoryCommit
+	author  string
+	email   string
+	date    time.Time
+	message string
+	files   map[string]string
+}
+
+type memoryConflict struct {
+	stages [3]*string
+}
+
+// MemoryRepository keeps commits, the index and remotes in memory while the work tree is a folder on disk.
+// A repository without a folder works as a bare remote. It lets sync be tested without the git binary.
+type MemoryRepository struct {
+	dir            string
+	userName       string
+	userEmail      string
+	branch         string
+	branches       map[string]*memoryCommit
+	index          map[string]string
+	remotes        map[string]*MemoryRepository
+	remoteBranches map[string]*memoryCommit
+	upstream       string
+	merging        *memoryCommit
+	mergingName    string
+	conflicts      map[string]*memoryConflict
+	config         map[string]string
+}
+
+func NewMemoryRepository(dir, userName, userEmail string) *MemoryRepository {
+	if dir != "" {
+		dir, _ = filepath.Abs(dir)
+	}
+	return &MemoryRepository{
+		dir:            dir,
+		userName:       userName,
+		userEmail:      userEmail,
+		branch:         "master",
+		branches:       make(map[string]*memoryCommit),
+		index:          make(map[string]string),
+		remotes:        make(map[string]*MemoryRepository),
+		remoteBranches: make(map[string]*memoryCommit),
+		conflicts:      make(map[string]*memoryConflict),
+		config:         make(map[string]string),
+	}
+}
+
+// Clone creates a repository in dir with the remote as origin and checks out its default branch.
+func (repo *MemoryRepository) Clone(dir, userName, userEmail string) (*MemoryRepository, error) {
+	clone := NewMemoryRepository(dir, userName, userEmail)
+	clone.branch = repo.branch
+	clone.AddRemote("origin", repo)
+	if err := clone.Fetch("origin"); err != nil {
+		return nil, err
+	}
+	if head := repo.branches[repo.branch]; head != nil {
+		clone.branches[clone.branch] = head
+		clone.upstream = "origin/" + clone.branch
+		if err := clone.checkout(head.files); err != nil {
+			return nil, err
+		}
+	}
+	return clone, nil
+}
+
+func (repo *MemoryRepository) AddRemote(name string, remote *MemoryRepository) {
+	repo.remotes[name] = remote
+}
+
+// SetBranch sets the name of the current branch.
+func (repo *MemoryRepository) SetBranch(branch string) {
+	if head := repo.branches[repo.branch]; head != nil {
+		repo.branches[branch] = head
+	}
+	repo.branch = branch
+}
+
+// Log returns the messages of the commits reachable from the branch, the latest first.
+func (repo *MemoryRepository) Log(branch string) []string {
+	var messages []string
+	for _, commit := range reachableCommits(repo.branches[branch]) {
+		messages = append([]string{commit.message}, messages...)
+	}
+	return messages
+}
+
+func (repo *MemoryRepository) CurrentUser() (name, email string, err error) {
+	if repo.userName == "" {
+		return "", "", errors.New("git config user.name isn't set")
+	}
+	return repo.userName, repo.userEmail, nil
+}
+
+func (repo *MemoryRepository) KnownUsers() (names, emails []string, err error) {
+	head := repo.head()
+	if head == nil {
+		return nil, nil, errors.New("the repository doesn't have any commits")
+	}
+	known := make(map[string]bool)
+	for _, commit := range reachableCommits(head) {
+		key := commit.author + "<" + commit.email + ">"
+		if !known[key] {
+			known[key] = true
+			names = append(names, commit.author)
+			emails = append(emails, commit.email)
+		}
+	}
+	return names, emails, nil
+}
+
+func (repo *MemoryRepository) SetConfig(key, value string) error {
+	switch key {
+	case "user.name":
+		repo.userName = value
+	case "user.email":
+		repo.userEmail = value
+	default:
+		repo.config[key] = value
+	}
+	return nil
+}
+
+// Config returns the value set by SetConfig.
+func (repo *MemoryRepository) Config(key string) string {
+	return repo.config[key]
+}
+
+// Init does nothing, a memory repository is created empty.
+func (repo *MemoryRepository) Init() error {
+	return nil
+}
+
+func (repo *MemoryRepository) Add(fileName string) error {
+	relPath, err := repo.relPath(fileName)
+	if err != nil {
+		return err
+	}
+	info, err := os.Stat(filepath.Join(repo.dir, filepath.FromSlash(relPath)))
+	if err == nil && info.IsDir() {
+		return repo.AddAll(fileName)
+	}
+	return repo.addFile(relPath)
+}
+
+func (repo *MemoryRepository) AddAll(dir string) error {
+	relDir, err := repo.relPath(dir)
+	if err != nil {
+		return err
+	}
+	inDir := func(relPath string) bool {
+		return relDir == "." || relPath == relDir || strings.HasPrefix(relPath, relDir+"/")
+	}
+	for relPath := range repo.index {
+		if inDir(relPath) {
+			if err := repo.addFile(relPath); err != nil {
+				return err
+			}
+		}
+	}
+	return filepath.Walk(filepath.Join(repo.dir, filepath.FromSlash(relDir)), func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.Name() == ".git" {
+			return filepath.SkipDir
+		}
+		if info.IsDir() {
+			return nil
+		}
+		relPath, err := repo.relPath(path)
+		if err != nil {
+			return err
+		}
+		return repo.addFile(relPath)
+	})
+}
+
+func (repo *MemoryRepository) Commit(msg, author string) error {
+	if repo.merging == nil && repo.head() != nil && equalFiles(repo.head().files, repo.index) {
+		return errors.New("nothing to commit, working tree clean")
+	}
+	return repo.commit(msg, author)
+}
+
+func (repo *MemoryRepository) CommitNoEdit(author string) error {
+	if repo.merging == nil {
+		return errors.New("there is no merge in progress")
+	}
+	return repo.commit(fmt.Sprintf("Merge remote-tracking branch '%s'", repo.mergingName), author)
+}
+
+func (repo *MemoryRepository) Head() (string, error) {
+	head := repo.head()
+	if head == nil {
+		return "", errors.New("the repository doesn't have any commits")
+	}
+	return head.hash, nil
+}
+
+func (repo *MemoryRepository) StagedChanges() ([]*StagedChange, error) {
+	headFiles := make(map[string]string)
+	if head := repo.head(); head != nil {
+		headFiles = head.files
+	}
+	var added, deleted []string
+	var changes []*StagedChange
+	for _, path := range sortedPaths(headFiles, repo.index) {
+		headContent, inHead := headFiles[path]
+		content, inIndex := repo.index[path]
+		switch {
+		case !inHead:
+			added = append(added, path)
+		case !inIndex:
+			deleted = append(deleted, path)
+		case headContent != content:
+			changes = append(changes, &StagedChange{Status: "M", Path: path})
+		}
+	}
+	for _, path := range added {
+		change := &StagedChange{Status: "A", Path: path}
+		for i, oldPath := range deleted {
+			if headFiles[oldPath] == repo.index[path] {
+				change.Status, change.OldPath = "R", oldPath
+				deleted = append(deleted[:i], deleted[i+1:]...)
+				break
+			}
+		}
+		changes = append(changes, change)
+	}
+	for _, path := range deleted {
+		changes = append(changes, &StagedChange{Status: "D", Path: path})
+	}
+	return changes, nil
+}
+
+func (repo *MemoryRepository) ChangedFiles() ([]string, error) {
+	head := repo.head()
+	if head == nil {
+		return nil, nil
+	}
+	var files []string
+	for _, path := range sortedPaths(head.files) {
+		data, err := ioutil.ReadFile(filepath.Join(repo.dir, filepath.FromSlash(path)))
+		if err != nil && !os.IsNotExist(err) {
+			return nil, err
+		}
+		if err != nil || string(data) != head.files[path] {
+			files = append(files, path)
+		}
+	}
+	return files, nil
+}
+
+func (repo *MemoryRepository) Fetch(remote string) error {
+	remoteRepo := repo.remotes[remote]
+	if remoteRepo == nil {
+		return fmt.Errorf("'%s' does not appear to be a git repository", remote)
+	}
+	for branch, commit := range remoteRepo.branches {
+		repo.remoteBranches[remote+"/"+branch] = commit
+	}
+	return nil
+}
+
+func (repo *MemoryRepository) Merge(remote, branch string) (string, error) {
+	name := remote + "/" + branch
+	theirs := repo.remoteBranches[name]
+	if theirs == nil {
+		return "", fmt.Errorf("merge: %s - not something we can merge", name)
+	}
+	ours := repo.head()
+	switch {
+	case isAncestor(theirs, ours):
+		return "Already up to date.", nil
+	case ours == nil || isAncestor(ours, theirs):
+		if err := repo.checkout(theirs.files); err != nil {
+			return "", err
+		}
+		repo.branches[repo.branch] = theirs
+		return "Fast-forward", nil
+	}
+
+	baseFiles := make(map[string]string)
+	if base := mergeBase(ours, theirs); base != nil {
+		baseFiles = base.files
+	}
+	merged := make(map[string]string)
+	var output []string
+	for _, path := range sortedPaths(baseFiles, ours.files, theirs.files) {
+		stages := [3]*string{fileVersion(baseFiles, path), fileVersion(ours.files, path), fileVersion(theirs.files, path)}
+		switch {
+		case equalVersions(stages[1], stages[2]), equalVersions(stages[0], stages[2]):
+			if stages[1] != nil {
+				merged[path] = *stages[1]
+			}
+		case equalVersions(stages[0], stages[1]):
+			if stages[2] != nil {
+				merged[path] = *stages[2]
+			}
+		default:
+			repo.conflicts[path] = &memoryConflict{stages: stages}
+			if stages[1] != nil {
+				merged[path] = *stages[1]
+			}
+			output = append(output, fmt.Sprintf("CONFLICT (content): Merge conflict in %s", path))
+		}
+	}
+	if err := repo.checkout(merged); err != nil {
+		return "", err
+	}
+	for path := range repo.conflicts {
+		delete(repo.index, path)
+	}
+	repo.merging, repo.mergingName = theirs, name
+	if len(repo.conflicts) > 0 {
+		output = append(output, "Automatic merge failed; fix conflicts and then commit the result.")
+		return strings.Join(output, "\n"), errors.New("merge conflict")
+	}
+	return "Merge made by the 'recursive' strategy.", repo.CommitNoEdit("")
+}
+
+func (repo *MemoryRepository) AbortMerge() error {
+	if repo.merging == nil {
+		return errors.New("there is no merge to abort")
+	}
+	repo.merging, repo.mergingName = nil, ""
+	repo.conflicts = make(map[string]*memoryConflict)
+	headFiles := make(map[string]string)
+	if head := repo.head(); head != nil {
+		headFiles = head.files
+	}
+	return repo.checkout(headFiles)
+}
+
+func (repo *MemoryRepository) Push(remote, branch string) error {
+	remoteRepo := repo.remotes[remote]
+	if remoteRepo == nil {
+		return fmt.Errorf("'%s' does not appear to be a git repository", remote)
+	}
+	head := repo.head()
+	if head == nil {
+		return errors.New("src refspec HEAD does not match any")
+	}
+	if current := remoteRepo.branches[branch]; current != nil && !isAncestor(current, head) {
+		return fmt.Errorf("failed to push some refs to '%s': the remote contains work that you do not have locally", remote)
+	}
+	if len(remoteRepo.branches) == 0 {
+		remoteRepo.branch = branch
+	}
+	remoteRepo.branches[branch] = head
+	repo.remoteBranches[remote+"/"+branch] = head
+	return nil
+}
+
+func (repo *MemoryRepository) SetUpstream(remote, branch string) error {
+	if !repo.RemoteBranchExists(remote, branch) {
+		return fmt.Errorf("the requested upstream branch '%s/%s' does not exist", remote, branch)
+	}
+	repo.upstream = remote + "/" + branch
+	return nil
+}
+
+func (repo *MemoryRepository) Upstream() (remote, branch string, ok bool) {
+	parts := strings.SplitN(repo.upstream, "/", 2)
+	if len(parts) != 2 {
+		return "", "", false
+	}
+	return parts[0], parts[1], true
+}
+
+func (repo *MemoryRepository) CurrentBranch() string {
+	return repo.branch
+}
+
+func (repo *MemoryRepository) Remotes() []string {
+	var remotes []string
+	for name := range repo.remotes {
+		remotes = append(remotes, name)
+	}
+	sort.Strings(remotes)
+	return remotes
+}
+
+func (repo *MemoryRepository) RemoteUrl(remote string) (string, error) {
+	remoteRepo := repo.remotes[remote]
+	if remoteRepo == nil {
+		return "", fmt.Errorf("'%s' does not appear to be a git repository", remote)
+	}
+	return "memory://" + remote, nil
+}
+
+func (repo *MemoryRepository) DefaultBranch(remote string) string {
+	remoteRepo := repo.remotes[remote]
+	if remoteRepo == nil || remoteRepo.branches[remoteRepo.branch] == nil {
+		return ""
+	}
+	return remoteRepo.branch
+}
+
+func (repo *MemoryRepository) RemoteBranchExists(remote, branch string) bool {
+	return repo.remoteBranches[remote+"/"+branch] != nil
+}
+
+func (repo *MemoryRepository) ConflictFiles() ([]string, error) {
+	var files []string
+	for path := range repo.conflicts {
+		files = append(files, path)
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+func (repo *MemoryRepository) CheckoutOurVersion(fileName string) error {
+	relPath, err := repo.relPath(fileName)
+	if err != nil {
+		return err
+	}
+	conflict := repo.conflicts[relPath]
+	if conflict == nil {
+		return fmt.Errorf("path '%s' is not unmerged", relPath)
+	}
+	filePath := filepath.Join(repo.dir, filepath.FromSlash(relPath))
+	if conflict.stages[1] == nil {
+		return os.Remove(filePath)
+	}
+	return ioutil.WriteFile(filePath, []byte(*conflict.stages[1]), 0644)
+}
+
+func (repo *MemoryRepository) StagedFileContent(stage int, fileName string) (content string, exists bool, err error) {
+	relPath, err := repo.relPath(fileName)
+	if err != nil {
+		return "", false, err
+	}
+	if stage == 0 {
+		content, exists = repo.index[relPath]
+		return content, exists, nil
+	}
+	conflict := repo.conflicts[relPath]
+	if conflict == nil || stage > len(conflict.stages) || conflict.stages[stage-1] == nil {
+		return "", false, nil
+	}
+	return *conflict.stages[stage-1], true, nil
+}
+
+func (repo *MemoryRepository) TopLevelDirectory() (string, error) {
+	if repo.dir == "" {
+		return "", errors.New("this operation must be run in a work tree")
+	}
+	return repo.dir, nil
+}
+
+func (repo *MemoryRepository) FileContent(revision, fileName string) (content string, exists bool, err error) {
+	relPath, err := repo.relPath(fileName)
+	if err != nil {
+		return "", false, err
+	}
+	commit := repo.revision(revision)
+	if commit == nil {
+		return "", false, nil
+	}
+	content, exists = commit.files[relPath]
+	return content, exists, nil
+}
+
+func (repo *MemoryRepository) FileChanges(paths ...string) ([]*FileChange, error) {
+	var prefixes []string
+	for _, path := range paths {
+		relPath, err := repo.relPath(path)
+		if err != nil {
+			return nil, err
+		}
+		prefixes = append(prefixes, relPath)
+	}
+	matches := func(path string) bool {
+		if len(prefixes) == 0 {
+			return true
+		}
+		for _, prefix := range prefixes {
+			if prefix == "." || path == prefix || strings.HasPrefix(path, prefix+"/") {
+				return true
+			}
+		}
+		return false
+	}
+
+	var changes []*FileChange
+	for _, commit := range reachableCommits(repo.head()) {
+		if len(commit.parents) > 1 {
+			continue
+		}
+		parentFiles := make(map[string]string)
+		if len(commit.parents) == 1 {
+			parentFiles = commit.parents[0].files
+		}
+		for _, path := range sortedPaths(parentFiles, commit.files) {
+			oldContent, inParent := parentFiles[path]
+			content, inCommit := commit.files[path]
+			if !matches(path) || (inParent && inCommit && oldContent == content) {
+				continue
+			}
+			change := &FileChange{Hash: commit.hash, Author: commit.author, Date: commit.date, Path: path, Deleted: !inCommit}
+			if inParent {
+				change.OldPath = path
+			}
+			change.Added, change.Removed = diffLines(oldContent, content)
+			if inCommit {
+				change.Content = strings.Split(content, "\n")
+			}
+			changes = append(changes, change)
+		}
+	}
+	return changes, nil
+}
+
+func (repo *MemoryRepository) InitCommitInfo(fileName string) (user string, created time.Time, err error) {
+	relPath, err := repo.relPath(fileName)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	for _, commit := range reachableCommits(repo.head()) {
+		if _, ok := commit.files[relPath]; ok {
+			return commit.author, commit.date, nil
+		}
+	}
+	return "", time.Time{}, nil
+}
+
+func (repo *MemoryRepository) head() *memoryCommit {
+	return repo.branches[repo.branch]
+}
+
+func (repo *MemoryRepository) revision(revision string) *memoryCommit {
+	switch {
+	case revision == "HEAD":
+		return repo.head()
+	case repo.branches[revision] != nil:
+		return repo.branches[revision]
+	case repo.remoteBranches[revision] != nil:
+		return repo.remoteBranches[revision]
+	}
+	for _, commit := range reachableCommits(repo.head()) {
+		if strings.HasPrefix(commit.hash, revision) {
+			return commit
+		}
+	}
+	return nil
+}
+
+func (repo *MemoryRepository) commit(msg, author string) error {
+	if len(repo.conflicts) > 0 {
+		return errors.New("committing is not possible because you have unmerged files")
+	}
+	name, email := repo.userName, repo.userEmail
+	if author != "" {
+		name, email = author, ""
+		if sepIndex := strings.LastIndexByte(author, '<'); sepIndex >= 0 {
+			name, email = strings.TrimSpace(author[:sepIndex]), strings.Trim(author[sepIndex:], "<>")
+		}
+	}
+	if name == "" {
+		return errors.New("please tell me who you are")
+	}
+
+	commit := &memoryCommit{
+		seq:     atomic.AddInt64(&memoryCommitCounter, 1),
+		author:  name,
+		email:   email,
+		date:    time.Now(),
+		message: msg,
+		files:   make(map[string]string, len(repo.index)),
+	}
+	for path, content := range repo.index {
+		commit.files[path] = content
+	}
+	if head := repo.head(); head != nil {
+		commit.parents = append(commit.parents, head)
+	}
+	if repo.merging != nil {
+		commit.parents = append(commit.parents, repo.merging)
+	}
+	hash := sha1.New()
+	fmt.Fprintf(hash, "%d\x00%s\x00%s\x00%s", commit.seq, name, email, msg)
+	for _, path := range sortedPaths(commit.files) {
+		fmt.Fprintf(hash, "\x00%s\x00%s", path, commit.files[path])
+	}
+	commit.hash = fmt.Sprintf("%x", hash.Sum(nil))
+
+	repo.branches[repo.branch] = commit
+	repo.merging, repo.mergingName = nil, ""
+	return nil
+}
+
+func (repo *MemoryRepository) addFile(relPath string) error {
+	data, err := ioutil.ReadFile(filepath.Join(repo.dir, filepath.FromSlash(relPath)))
+	switch {
+	case os.IsNotExist(err):
+		delete(repo.index, relPath)
+	case err != nil:
+		return err
+	default:
+		repo.index[relPath] = string(data)
+	}
+	delete(repo.conflicts, relPath)
+	return nil
+}
+
+// checkout writes the files to the work tree and the index and removes the files of the index that aren't among them.
+func (repo *MemoryRepository) checkout(files map[string]string) error {
+	for relPath := range repo.index {
+		if _, ok := files[relPath]; !ok {
+			if err := os.Remove(filepath.Join(repo.dir, filepath.FromSlash(relPath))); err != nil && !os.IsNotExist(err) {
+				return err
+			}
+		}
+	}
+	index := make(map[string]string, len(files))
+	for relPath, content := range files {
+		filePath := filepath.Join(repo.dir, filepath.FromSlash(relPath))
+		if err := os.MkdirAll(filepath.Dir(filePath), 0777); err != nil {
+			return err
+		}
+		if err := ioutil.WriteFile(filePath, []byte(content), 0644); err != nil {
+			return err
+		}
+		index[relPath] = content
+	}
+	repo.index = index
+	return nil
+}
+
+func (repo *MemoryRepository) relPath(fileName string) (string, error) {
+	if repo.dir == "" {
+		return "", errors.New("this operation must be run in a work tree")
+	}
+	if !filepath.IsAbs(fileName) {
+		fileName = filepath.Join(repo.dir, fileName)
+	}
+	relPath, err := filepath.Rel(repo.dir, filepath.Clean(fileName))
+	if err != nil {
+		return "", err
+	}
+	if strings.HasPrefix(relPath, "..") {
+		return "", fmt.Errorf("'%s' is outside repository", fileName)
+	}
+	return filepath.ToSlash(relPath), nil
+}
+
+// reachableCommits returns the commits reachable from the commit, the oldest first.
+func reachableCommits(commit *memoryCommit) []*memoryCommit {
+	var result []*memoryCommit
+	visited := make(map[*memoryCommit]bool)
+	queue := []*memoryCommit{commit}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		if current == nil || visited[current] {
+			continue
+		}
+		visited[current] = true
+		result = append(result, current)
+		queue = append(queue, current.parents...)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].seq < result[j].seq
+	})
+	return result
+}
+
+func isAncestor(ancestor, commit *memoryCommit) bool {
+	if ancestor == nil {
+		return true
+	}
+	for _, reachable := range reachableCommits(commit) {
+		if reachable == ancestor {
+			return true
+		}
+	}
+	return false
+}
+
+func mergeBase(commit1, commit2 *memoryCommit) *memoryCommit {
+	reachable := make(map[*memoryCommit]bool)
+	for _, commit := range reachableCommits(commit1) {
+		reachable[commit] = true
+	}
+	var base *memoryCommit
+	for _, commit := range reachableCommits(commit2) {
+		if reachable[commit] {
+			base = commit
+		}
+	}
+	return base
+}
+
+func sortedPaths(fileSets ...map[string]string) []string {
+	set := make(map[string]bool)
+	for _, files := range fileSets {
+		for path := range files {
+			set[path] = true
+		}
+	}
+	paths := make([]string, 0, len(set))
+	for path := range set {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+func fileVersion(files map[string]string, path string) *string {
+	if content, ok := files[path]; ok {
+		return &content
+	}
+	return nil
+}
+
+func equalVersions(version1, version2 *string) bool {
+	if version1 == nil || version2 == nil {
+		return version1 == version2
+	}
+	return *version1 == *version2
+}
+
+func equalFiles(files1, files2 map[string]string) bool {
+	if len(files1) != len(files2) {
+		return false
+	}
+	for path, content := range files1 {
+		if otherContent, ok := files2[path]; !ok || otherContent != content {
+			return false
+		}
+	}
+	return true
+}
+
+// diffLines returns the lines added and removed in the content, the order of lines isn't taken into account.
+func diffLines(oldContent, content string) (added, removed []string) {
+	counts := make(map[string]int)
+	if oldContent != "" {
+		for _, line := range strings.Split(oldContent, "\n") {
+			counts[line]++
+		}
+	}
+	if content != "" {
+		for _, line := range strings.Split(content, "\n") {
+			if counts[line] > 0 {
+				counts[line]--
+			} else {
+				added = append(added, line)
+			}
+		}
+	}
+	if oldContent != "" {
+		for _, line := range strings.Split(oldContent, "\n") {
+			if counts[line] > 0 {
+				counts[line]--
+				removed = append(removed, line)
+			}
+		}
+	}
+	return added, removed
+}
@@ -0,0 +1,43 @@
+package git
+
+import (
+	"time"
+)
+
+// Repository is a git repository used by the commands. ExecRepository runs the git binary,
+// MemoryRepository keeps the history in memory for tests.
+type Repository interface {
+	CurrentUser() (name, email string, err error)
+	KnownUsers() (names, emails []string, err error)
+	SetConfig(key, value string) error
+	Init() error
+
+	Add(fileName string) error
+	AddAll(dir string) error
+	Commit(msg, author string) error
+	CommitNoEdit(author string) error
+	Head() (string, error)
+	StagedChanges() ([]*StagedChange, error)
+	ChangedFiles() ([]string, error)
+
+	Fetch(remote string) error
+	Merge(remote, branch string) (string, error)
+	AbortMerge() error
+	Push(remote, branch string) error
+	SetUpstream(remote, branch string) error
+	Upstream() (remote, branch string, ok bool)
+	CurrentBranch() string
+	Remotes() []string
+	RemoteUrl(remote string) (string, error)
+	DefaultBranch(remote string) string
+	RemoteBranchExists(remote, branch string) bool
+
+	ConflictFiles() ([]string, error)
+	CheckoutOurVersion(fileName string) error
+	StagedFileContent(stage int, fileName string) (content string, exists bool, err error)
+
+	TopLevelDirectory() (string, error)
+	FileContent(revision, fileName string) (content string, exists bool, err error)
+	FileChanges(paths ...string) ([]*FileChange, error)
+	InitCommitInfo(fileName string) (user string, created time.Time, err error)
+}
@@ -22,11 +22,17 @@ var (
 
 func main() {
 	rootDir, _ := filepath.Abs(".")
-	gitRootDir := git.GetRootGitDirectory(rootDir)
-	if gitRootDir != "" {
-		rootDir = gitRootDir
-		commands.AddConfigAndGitIgnore(rootDir)
-		users.NewUserList(filepath.Join(rootDir, backlog.UsersDirectoryName))
+	backlogRootDir := commands.FindRootDirectory(rootDir)
+	if backlogRootDir != "" {
+		rootDir = backlogRootDir
+		repo := git.NewExecRepository(rootDir)
+		commands.AddConfigAndGitIgnore(repo, rootDir)
+		users.NewUserList(repo, filepath.Join(rootDir, backlog.UsersDirectoryName))
+		err := commands.LoadStatuses(rootDir)
+		if err != nil {
+			fmt.Printf("can't load statuses: %v\n", err)
+		}
+		commands.DetectMetadataFormat(rootDir)
 	}
 	err := setBashAutoComplete()
 	if err != nil {
@@ -51,15 +57,26 @@ func main() {
 		commands.CreateItemCommand,
 		commands.CreateIdeaCommand,
 		commands.NewSyncCommand(),
-		commands.WorkCommand,
-		commands.PointsCommand,
-		commands.AssignUserCommand,
-		commands.ChangeStatusCommand,
+		commands.NewWorkCommand(),
+		commands.NewPointsCommand(),
+		commands.NewAssignUserCommand(),
+		commands.NewChangeStatusCommand(),
 		commands.ProgressCommand,
+		commands.MetricsCommand,
+		commands.ForecastCommand,
 		commands.AliasCommand,
 		commands.ImportCommand,
 		commands.ArchiveCommand,
 		commands.CreateUserCommand,
+		commands.SprintCommand,
+		commands.MigrateMetadataCommand,
+		commands.DepsCommand,
+		commands.EpicsCommand,
+		commands.ServeCommand,
+		commands.APICommand,
+		commands.SearchCommand,
+		commands.ExportCommand,
+		commands.MergeDriverCommand,
 	}
 
 	err = app.Run(os.Args)
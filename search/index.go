@@ -0,0 +1,271 @@
+package search
+
+import (
+	"encoding/json"
+	"github.com/mreider/agilemarkdown/backlog"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+const (
+	IndexDirectoryName = ".am"
+	IndexFileName      = "index"
+	indexVersion       = 1
+
+	StoryKind = "story"
+	IdeaKind  = "idea"
+
+	TitleField       = "title"
+	DescriptionField = "description"
+	CommentField     = "comment"
+	TagField         = "tag"
+)
+
+var (
+	Fields       = []string{TitleField, DescriptionField, CommentField, TagField}
+	fieldWeights = map[string]float64{TitleField: 3, TagField: 2, DescriptionField: 1, CommentField: 1}
+
+	generatedSectionsRe = regexp.MustCompile(`(?m)^#{1,3}\s+(Comments|` + backlog.EpicProgressTitle + `)\s*$`)
+)
+
+type Document struct {
+	Path    string
+	Kind    string
+	Title   string
+	Status  string
+	ModTime int64
+	Size    int64
+	Fields  map[string]string
+}
+
+type Posting struct {
+	Path  string
+	Field string
+	Count int
+}
+
+type Index struct {
+	Version   int
+	Documents map[string]*Document
+	Postings  map[string][]*Posting
+
+	rootDir string
+}
+
+func NewIndex(rootDir string) *Index {
+	rootDir, _ = filepath.Abs(rootDir)
+	return &Index{
+		Version:   indexVersion,
+		Documents: make(map[string]*Document),
+		Postings:  make(map[string][]*Posting),
+		rootDir:   rootDir,
+	}
+}
+
+func LoadIndex(rootDir string) (*Index, error) {
+	index := NewIndex(rootDir)
+	data, err := ioutil.ReadFile(index.path())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return index, nil
+		}
+		return nil, err
+	}
+	loaded := NewIndex(rootDir)
+	if err := json.Unmarshal(data, loaded); err != nil || loaded.Version != indexVersion {
+		return index, nil
+	}
+	return loaded, nil
+}
+
+func (index *Index) path() string {
+	return filepath.Join(index.rootDir, IndexDirectoryName, IndexFileName)
+}
+
+func (index *Index) Save() error {
+	indexDir := filepath.Dir(index.path())
+	if err := os.MkdirAll(indexDir, 0777); err != nil {
+		return err
+	}
+	gitIgnorePath := filepath.Join(indexDir, ".gitignore")
+	if _, err := os.Stat(gitIgnorePath); os.IsNotExist(err) {
+		if err := ioutil.WriteFile(gitIgnorePath, []byte("*\n"), 0644); err != nil {
+			return err
+		}
+	}
+	data, err := json.Marshal(index)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(index.path(), data, 0644)
+}
+
+// Update indexes new and changed stories and ideas and drops removed ones.
+// Files with the same modification time and size as in the index aren't read again.
+func (index *Index) Update() (int, error) {
+	files, err := index.files()
+	if err != nil {
+		return 0, err
+	}
+
+	changed := 0
+	for relPath, kind := range files {
+		info, err := os.Stat(filepath.Join(index.rootDir, filepath.FromSlash(relPath)))
+		if err != nil {
+			return changed, err
+		}
+		doc := index.Documents[relPath]
+		if doc != nil && doc.ModTime == info.ModTime().UnixNano() && doc.Size == info.Size() {
+			continue
+		}
+		doc, err = index.loadDocument(relPath, kind)
+		if err != nil {
+			return changed, err
+		}
+		doc.ModTime, doc.Size = info.ModTime().UnixNano(), info.Size()
+		index.remove(relPath)
+		index.add(doc)
+		changed++
+	}
+	for relPath := range index.Documents {
+		if _, ok := files[relPath]; !ok {
+			index.remove(relPath)
+			changed++
+		}
+	}
+	return changed, nil
+}
+
+func (index *Index) files() (map[string]string, error) {
+	files := make(map[string]string)
+	addFiles := func(dir, kind string) error {
+		infos, err := ioutil.ReadDir(dir)
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+		for _, info := range infos {
+			name := strings.TrimSuffix(info.Name(), ".md")
+			if info.IsDir() || !strings.HasSuffix(info.Name(), ".md") || (kind == StoryKind && backlog.IsForbiddenItemName(name)) {
+				continue
+			}
+			relPath, _ := filepath.Rel(index.rootDir, filepath.Join(dir, info.Name()))
+			files[filepath.ToSlash(relPath)] = kind
+		}
+		return nil
+	}
+
+	infos, err := ioutil.ReadDir(index.rootDir)
+	if err != nil {
+		return nil, err
+	}
+	for _, info := range infos {
+		if !info.IsDir() || strings.HasPrefix(info.Name(), ".") || backlog.IsForbiddenBacklogName(info.Name()) {
+			continue
+		}
+		if _, err := os.Stat(filepath.Join(index.rootDir, info.Name()+".md")); err != nil {
+			continue
+		}
+		backlogDir := filepath.Join(index.rootDir, info.Name())
+		if err := addFiles(backlogDir, StoryKind); err != nil {
+			return nil, err
+		}
+		if err := addFiles(filepath.Join(backlogDir, backlog.ArchiveDirectoryName), StoryKind); err != nil {
+			return nil, err
+		}
+	}
+	if err := addFiles(filepath.Join(index.rootDir, backlog.IdeasDirectoryName), IdeaKind); err != nil {
+		return nil, err
+	}
+	return files, nil
+}
+
+func (index *Index) loadDocument(relPath, kind string) (*Document, error) {
+	fullPath := filepath.Join(index.rootDir, filepath.FromSlash(relPath))
+	doc := &Document{Path: relPath, Kind: kind, Fields: make(map[string]string)}
+	if kind == IdeaKind {
+		idea, err := backlog.LoadBacklogIdea(fullPath)
+		if err != nil {
+			return nil, err
+		}
+		doc.Title = idea.Title()
+		doc.Fields[DescriptionField] = strings.TrimSpace(idea.Text())
+		doc.Fields[TagField] = strings.Join(idea.Tags(), " ")
+	} else {
+		item, err := backlog.LoadBacklogItem(fullPath)
+		if err != nil {
+			return nil, err
+		}
+		doc.Title = item.Title()
+		doc.Status = item.Status()
+		description := item.Description()
+		if loc := generatedSectionsRe.FindStringIndex(description); loc != nil {
+			description = description[:loc[0]]
+		}
+		doc.Fields[DescriptionField] = strings.TrimSpace(description)
+		var comments []string
+		for _, comment := range item.Comments() {
+			comments = append(comments, strings.Join(comment.Text, " "))
+		}
+		doc.Fields[CommentField] = strings.Join(comments, "\n")
+		doc.Fields[TagField] = strings.Join(item.Tags(), " ")
+	}
+	doc.Fields[TitleField] = doc.Title
+	return doc, nil
+}
+
+func (index *Index) add(doc *Document) {
+	index.Documents[doc.Path] = doc
+	for _, field := range Fields {
+		counts := make(map[string]int)
+		for _, token := range tokenize(doc.Fields[field]) {
+			counts[token]++
+		}
+		for token, count := range counts {
+			index.Postings[token] = append(index.Postings[token], &Posting{Path: doc.Path, Field: field, Count: count})
+		}
+	}
+}
+
+func (index *Index) remove(relPath string) {
+	doc := index.Documents[relPath]
+	if doc == nil {
+		return
+	}
+	delete(index.Documents, relPath)
+	for _, field := range Fields {
+		for _, token := range tokenize(doc.Fields[field]) {
+			postings := index.Postings[token]
+			kept := postings[:0]
+			for _, posting := range postings {
+				if posting.Path != relPath {
+					kept = append(kept, posting)
+				}
+			}
+			if len(kept) == 0 {
+				delete(index.Postings, token)
+			} else {
+				index.Postings[token] = kept
+			}
+		}
+	}
+}
+
+func tokenize(text string) []string {
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	tokens := words[:0]
+	for _, word := range words {
+		if len([]rune(word)) > 1 {
+			tokens = append(tokens, word)
+		}
+	}
+	return tokens
+}
@@ -0,0 +1,156 @@
+package search
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+)
+
+const snippetRadius = 40
+
+type Result struct {
+	Document *Document
+	Score    float64
+	Snippet  string
+}
+
+type searchTerm struct {
+	field  string
+	token  string
+	prefix bool
+}
+
+func (index *Index) Search(query string, limit int) ([]*Result, error) {
+	terms, err := parseSearchQuery(query)
+	if err != nil {
+		return nil, err
+	}
+	if len(terms) == 0 {
+		return nil, nil
+	}
+
+	var scores map[string]float64
+	for _, term := range terms {
+		termScores := index.termScores(term)
+		if scores == nil {
+			scores = termScores
+			continue
+		}
+		for path := range scores {
+			if termScore, ok := termScores[path]; ok {
+				scores[path] += termScore
+			} else {
+				delete(scores, path)
+			}
+		}
+	}
+
+	results := make([]*Result, 0, len(scores))
+	for path, score := range scores {
+		doc := index.Documents[path]
+		results = append(results, &Result{Document: doc, Score: score, Snippet: snippet(doc, terms)})
+	}
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Score != results[j].Score {
+			return results[i].Score > results[j].Score
+		}
+		return results[i].Document.Path < results[j].Document.Path
+	})
+	if limit > 0 && len(results) > limit {
+		results = results[:limit]
+	}
+	return results, nil
+}
+
+func (index *Index) termScores(term *searchTerm) map[string]float64 {
+	var tokens []string
+	if term.prefix {
+		for token := range index.Postings {
+			if strings.HasPrefix(token, term.token) {
+				tokens = append(tokens, token)
+			}
+		}
+	} else {
+		tokens = []string{term.token}
+	}
+
+	scores := make(map[string]float64)
+	for _, token := range tokens {
+		postings := index.Postings[token]
+		docs := make(map[string]bool)
+		for _, posting := range postings {
+			docs[posting.Path] = true
+		}
+		idf := math.Log(1 + float64(len(index.Documents))/float64(len(docs)))
+		for _, posting := range postings {
+			if term.field != "" && posting.Field != term.field {
+				continue
+			}
+			scores[posting.Path] += fieldWeights[posting.Field] * (1 + math.Log(float64(posting.Count))) * idf
+		}
+	}
+	return scores
+}
+
+func parseSearchQuery(query string) ([]*searchTerm, error) {
+	var terms []*searchTerm
+	for _, word := range strings.Fields(query) {
+		field := ""
+		if parts := strings.SplitN(word, ":", 2); len(parts) == 2 {
+			field = strings.ToLower(parts[0])
+			if field == "tags" {
+				field = TagField
+			}
+			if _, ok := fieldWeights[field]; !ok {
+				return nil, fmt.Errorf("unknown field '%s', should be one of: %s", parts[0], strings.Join(Fields, ", "))
+			}
+			word = parts[1]
+		}
+		prefix := strings.HasSuffix(word, "*")
+		tokens := tokenize(word)
+		for i, token := range tokens {
+			terms = append(terms, &searchTerm{field: field, token: token, prefix: prefix && i == len(tokens)-1})
+		}
+	}
+	return terms, nil
+}
+
+func snippet(doc *Document, terms []*searchTerm) string {
+	for _, field := range []string{DescriptionField, CommentField} {
+		text := strings.Join(strings.Fields(doc.Fields[field]), " ")
+		lowerText := strings.ToLower(text)
+		for _, term := range terms {
+			if term.field != "" && term.field != field {
+				continue
+			}
+			pos := strings.Index(lowerText, term.token)
+			if pos < 0 {
+				continue
+			}
+			start, end := pos-snippetRadius, pos+len(term.token)+snippetRadius
+			prefix, suffix := "...", "..."
+			if start <= 0 {
+				start, prefix = 0, ""
+			}
+			if end >= len(text) {
+				end, suffix = len(text), ""
+			}
+			if start > end {
+				start = end
+			}
+			for start > 0 && !isRuneStart(text[start]) {
+				start--
+			}
+			for end < len(text) && !isRuneStart(text[end]) {
+				end++
+			}
+			return prefix + text[start:end] + suffix
+		}
+	}
+	return ""
+}
+
+func isRuneStart(b byte) bool {
+	return b&0xC0 != 0x80
+}
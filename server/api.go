@@ -0,0 +1,752 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/mreider/agilemarkdown/backlog"
+	"github.com/mreider/agilemarkdown/git"
+	"github.com/mreider/agilemarkdown/users"
+	"github.com/mreider/agilemarkdown/utils"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const APIPrefix = "/api/"
+
+type API struct {
+	repo     git.Repository
+	rootDir  string
+	author   string
+	onChange func() error
+	mutex    sync.Mutex
+}
+
+type apiError struct {
+	status int
+	msg    string
+}
+
+type APIBacklog struct {
+	Name  string `json:"name"`
+	Title string `json:"title"`
+	Items int    `json:"items"`
+}
+
+type APIItem struct {
+	Backlog     string            `json:"backlog"`
+	Name        string            `json:"name"`
+	Title       string            `json:"title"`
+	Status      string            `json:"status"`
+	Assigned    string            `json:"assigned"`
+	Estimate    string            `json:"estimate"`
+	Tags        []string          `json:"tags"`
+	Sprint      string            `json:"sprint,omitempty"`
+	Epic        string            `json:"epic,omitempty"`
+	BlockedBy   []string          `json:"blockedBy,omitempty"`
+	Blocks      []string          `json:"blocks,omitempty"`
+	Author      string            `json:"author"`
+	Created     time.Time         `json:"created"`
+	Modified    time.Time         `json:"modified"`
+	Archived    bool              `json:"archived"`
+	Fields      map[string]string `json:"fields,omitempty"`
+	Description string            `json:"description"`
+	SyncWarning string            `json:"syncWarning,omitempty"`
+}
+
+type APIItemChange struct {
+	Title       *string            `json:"title"`
+	Status      *string            `json:"status"`
+	Assigned    *string            `json:"assigned"`
+	Estimate    *string            `json:"estimate"`
+	Tags        *[]string          `json:"tags"`
+	Sprint      *string            `json:"sprint"`
+	Epic        *string            `json:"epic"`
+	BlockedBy   *[]string          `json:"blockedBy"`
+	Blocks      *[]string          `json:"blocks"`
+	Author      *string            `json:"author"`
+	Fields      map[string]*string `json:"fields"`
+	Description *string            `json:"description"`
+}
+
+type APIIdea struct {
+	Name        string    `json:"name"`
+	Title       string    `json:"title"`
+	Author      string    `json:"author"`
+	Tags        []string  `json:"tags"`
+	Created     time.Time `json:"created"`
+	Modified    time.Time `json:"modified"`
+	Text        string    `json:"text"`
+	SyncWarning string    `json:"syncWarning,omitempty"`
+}
+
+type APIIdeaChange struct {
+	Title  *string   `json:"title"`
+	Author *string   `json:"author"`
+	Tags   *[]string `json:"tags"`
+	Text   *string   `json:"text"`
+}
+
+// APISyncWarning is the result of a deletion that is saved, but isn't synced.
+type APISyncWarning struct {
+	SyncWarning string `json:"syncWarning"`
+}
+
+type APIUser struct {
+	Name  string `json:"name"`
+	Nick  string `json:"nick"`
+	Email string `json:"email"`
+}
+
+type APITag struct {
+	Tag   string     `json:"tag"`
+	Items []*APIItem `json:"items"`
+	Ideas []*APIIdea `json:"ideas"`
+}
+
+func NewAPI(repo git.Repository, rootDir, author string, onChange func() error) *API {
+	rootDir, _ = filepath.Abs(rootDir)
+	return &API{repo: repo, rootDir: rootDir, author: author, onChange: onChange}
+}
+
+func (e *apiError) Error() string {
+	return e.msg
+}
+
+func newAPIError(status int, format string, args ...interface{}) *apiError {
+	return &apiError{status: status, msg: fmt.Sprintf(format, args...)}
+}
+
+func (api *API) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	api.mutex.Lock()
+	defer api.mutex.Unlock()
+
+	var parts []string
+	if path := strings.Trim(strings.TrimPrefix(r.URL.Path, APIPrefix), "/"); path != "" {
+		parts = strings.Split(path, "/")
+	}
+	for _, part := range parts {
+		if part == "" || strings.HasPrefix(part, ".") {
+			api.writeError(w, newAPIError(http.StatusNotFound, "not found"))
+			return
+		}
+	}
+
+	if r.Method != http.MethodGet && isCrossOriginRequest(r) {
+		api.writeError(w, newAPIError(http.StatusForbidden, "cross-origin requests aren't allowed"))
+		return
+	}
+
+	status := http.StatusOK
+	if r.Method == http.MethodPost {
+		status = http.StatusCreated
+	}
+	result, err := api.route(r, parts)
+	if err == nil && r.Method != http.MethodGet && api.onChange != nil {
+		// The change is saved already, so a failed sync is reported along with the result.
+		if syncErr := api.onChange(); syncErr != nil {
+			result = withSyncWarning(result, syncErr)
+		}
+	}
+	if err != nil {
+		api.writeError(w, err)
+		return
+	}
+	if result == nil {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(result)
+}
+
+func (api *API) route(r *http.Request, parts []string) (interface{}, error) {
+	switch {
+	case len(parts) == 1 && parts[0] == "backlogs" && r.Method == http.MethodGet:
+		return api.listBacklogs()
+	case len(parts) == 3 && parts[0] == "backlogs" && parts[2] == "items":
+		switch r.Method {
+		case http.MethodGet:
+			return api.listItems(parts[1], r)
+		case http.MethodPost:
+			return api.createItem(parts[1], r)
+		}
+	case len(parts) == 4 && parts[0] == "backlogs" && parts[2] == "items":
+		switch r.Method {
+		case http.MethodGet:
+			return api.getItem(parts[1], parts[3])
+		case http.MethodPut, http.MethodPatch:
+			return api.updateItem(parts[1], parts[3], r)
+		case http.MethodDelete:
+			return nil, api.deleteItem(parts[1], parts[3])
+		}
+	case len(parts) == 1 && parts[0] == "ideas":
+		switch r.Method {
+		case http.MethodGet:
+			return api.listIdeas()
+		case http.MethodPost:
+			return api.createIdea(r)
+		}
+	case len(parts) == 2 && parts[0] == "ideas":
+		switch r.Method {
+		case http.MethodGet:
+			return api.getIdea(parts[1])
+		case http.MethodPut, http.MethodPatch:
+			return api.updateIdea(parts[1], r)
+		case http.MethodDelete:
+			return nil, api.deleteIdea(parts[1])
+		}
+	case len(parts) == 1 && parts[0] == "users" && r.Method == http.MethodGet:
+		return api.listUsers(), nil
+	case len(parts) == 1 && parts[0] == "tags" && r.Method == http.MethodGet:
+		return api.listTags()
+	case len(parts) == 2 && parts[0] == "tags" && r.Method == http.MethodGet:
+		return api.getTag(parts[1])
+	default:
+		return nil, newAPIError(http.StatusNotFound, "not found")
+	}
+	return nil, newAPIError(http.StatusMethodNotAllowed, "method %s isn't allowed", r.Method)
+}
+
+func withSyncWarning(result interface{}, err error) interface{} {
+	switch result := result.(type) {
+	case *APIItem:
+		result.SyncWarning = err.Error()
+		return result
+	case *APIIdea:
+		result.SyncWarning = err.Error()
+		return result
+	}
+	return &APISyncWarning{SyncWarning: err.Error()}
+}
+
+func (api *API) writeError(w http.ResponseWriter, err error) {
+	status := http.StatusInternalServerError
+	if apiErr, ok := err.(*apiError); ok {
+		status = apiErr.status
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+}
+
+func (api *API) listBacklogs() ([]*APIBacklog, error) {
+	backlogDirs, err := findBacklogDirs(api.rootDir)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]*APIBacklog, 0, len(backlogDirs))
+	for _, backlogDir := range backlogDirs {
+		bck, err := backlog.LoadBacklog(backlogDir)
+		if err != nil {
+			return nil, err
+		}
+		overview, err := backlog.LoadBacklogOverview(backlogDir + ".md")
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, &APIBacklog{Name: filepath.Base(backlogDir), Title: overview.Title(), Items: len(bck.ActiveItems())})
+	}
+	return result, nil
+}
+
+func (api *API) listItems(backlogName string, r *http.Request) ([]*APIItem, error) {
+	bck, err := api.loadBacklog(backlogName)
+	if err != nil {
+		return nil, err
+	}
+	filter, err := api.itemsFilter(bck, r)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]*APIItem, 0)
+	for _, item := range bck.AllItems() {
+		if filter.Match(item) {
+			result = append(result, newAPIItem(backlogName, item))
+		}
+	}
+	return result, nil
+}
+
+func (api *API) itemsFilter(bck *backlog.Backlog, r *http.Request) (backlog.BacklogItemsFilter, error) {
+	query := r.URL.Query()
+	filter := &backlog.BacklogItemsAndFilter{}
+	switch query.Get("archived") {
+	case "", "false":
+		filter.And(&backlog.BacklogItemsActiveFilter{})
+	case "true":
+		filter.And(&backlog.BacklogItemsArchivedFilter{})
+	case "all":
+	default:
+		return nil, newAPIError(http.StatusBadRequest, "archived should be true, false or all")
+	}
+	if value := query.Get("status"); value != "" {
+		status := statusByNameOrCode(value)
+		if status == nil {
+			return nil, newAPIError(http.StatusBadRequest, "unknown status '%s'", value)
+		}
+		filter.And(backlog.NewBacklogItemsStatusCodeFilter(status.Code))
+	}
+	if value := query.Get("assigned"); value != "" {
+		filter.And(backlog.NewBacklogItemsAssignedFilter(value))
+	}
+	if value := query.Get("tags"); value != "" {
+		filter.And(backlog.NewBacklogItemsTagsFilter(value))
+	}
+	if value := query.Get("sprint"); value != "" {
+		filter.And(backlog.NewBacklogItemsSprintFilter(value))
+	}
+	for _, value := range query["field"] {
+		parts := strings.SplitN(value, "=", 2)
+		field := backlog.FieldByName(bck.Fields(), parts[0])
+		if len(parts) != 2 || field == nil {
+			return nil, newAPIError(http.StatusBadRequest, "invalid field filter '%s'", value)
+		}
+		filter.And(backlog.NewBacklogItemsFieldFilter(field, parts[1]))
+	}
+	return filter, nil
+}
+
+func (api *API) getItem(backlogName, itemName string) (*APIItem, error) {
+	bck, err := api.loadBacklog(backlogName)
+	if err != nil {
+		return nil, err
+	}
+	item, err := findItem(bck, itemName)
+	if err != nil {
+		return nil, err
+	}
+	return newAPIItem(backlogName, item), nil
+}
+
+func (api *API) createItem(backlogName string, r *http.Request) (*APIItem, error) {
+	bck, err := api.loadBacklog(backlogName)
+	if err != nil {
+		return nil, err
+	}
+	var change APIItemChange
+	if err := decodeJSON(r, &change); err != nil {
+		return nil, err
+	}
+	if change.Title == nil || strings.TrimSpace(*change.Title) == "" {
+		return nil, newAPIError(http.StatusBadRequest, "the title should be specified")
+	}
+
+	itemTitle := strings.TrimSpace(*change.Title)
+	itemName := strings.Replace(itemTitle, " ", "-", -1)
+	if backlog.IsForbiddenItemName(itemName) || strings.ContainsAny(itemName, `/\`) || strings.HasPrefix(itemName, ".") {
+		return nil, newAPIError(http.StatusBadRequest, "'%s' can't be used as an item name", itemName)
+	}
+	itemPath := filepath.Join(api.rootDir, backlogName, fmt.Sprintf("%s.md", itemName))
+	if _, err := os.Stat(itemPath); err == nil {
+		return nil, newAPIError(http.StatusConflict, "the item '%s' already exists", itemName)
+	}
+
+	item, err := backlog.LoadBacklogItem(itemPath, bck.Fields()...)
+	if err != nil {
+		return nil, err
+	}
+	item.SetTitle(utils.TitleFirstLetter(itemTitle))
+	item.SetCreated("")
+	item.SetModified()
+	item.SetTags(nil)
+	item.SetAuthor(api.currentUser())
+	item.SetStatus(backlog.InitialStatus())
+	item.SetAssigned("")
+	item.SetEstimate("")
+	item.SetDescription(backlog.NewItemTemplate)
+	change.Title = nil
+	if err := api.applyItemChange(item, &change); err != nil {
+		return nil, err
+	}
+	if err := item.Save(); err != nil {
+		return nil, err
+	}
+	return newAPIItem(backlogName, item), nil
+}
+
+func (api *API) updateItem(backlogName, itemName string, r *http.Request) (*APIItem, error) {
+	bck, err := api.loadBacklog(backlogName)
+	if err != nil {
+		return nil, err
+	}
+	item, err := findItem(bck, itemName)
+	if err != nil {
+		return nil, err
+	}
+	var change APIItemChange
+	if err := decodeJSON(r, &change); err != nil {
+		return nil, err
+	}
+	if err := api.applyItemChange(item, &change); err != nil {
+		return nil, err
+	}
+	item.SetModified()
+	if err := item.Save(); err != nil {
+		return nil, err
+	}
+	return newAPIItem(backlogName, item), nil
+}
+
+func (api *API) applyItemChange(item *backlog.BacklogItem, change *APIItemChange) error {
+	if change.Title != nil && strings.TrimSpace(*change.Title) == "" {
+		return newAPIError(http.StatusBadRequest, "the title can't be empty")
+	}
+	var status *backlog.BacklogItemStatus
+	if change.Status != nil {
+		status = statusByNameOrCode(*change.Status)
+		if status == nil {
+			return newAPIError(http.StatusBadRequest, "unknown status '%s'", *change.Status)
+		}
+		if !backlog.IsAllowedTransition(backlog.StatusByName(item.Status()), status) {
+			return newAPIError(http.StatusBadRequest, "the status can't be changed from '%s' to '%s'", item.Status(), status.Name)
+		}
+	}
+	userList := users.NewUserList(api.repo, filepath.Join(api.rootDir, backlog.UsersDirectoryName))
+	if change.Assigned != nil && *change.Assigned != "" && userList.User(*change.Assigned) == nil {
+		return newAPIError(http.StatusBadRequest, "unknown user '%s'", *change.Assigned)
+	}
+	isKnownUser := func(user string) bool {
+		return userList.User(user) != nil
+	}
+	for name, value := range change.Fields {
+		field := backlog.FieldByName(item.Fields(), name)
+		if field == nil {
+			return newAPIError(http.StatusBadRequest, "unknown field '%s'", name)
+		}
+		if value != nil {
+			if err := field.Validate(*value, isKnownUser); err != nil {
+				return newAPIError(http.StatusBadRequest, "%s: %v", field.Name, err)
+			}
+		}
+	}
+
+	if change.Title != nil {
+		item.SetTitle(strings.TrimSpace(*change.Title))
+	}
+	if status != nil {
+		item.SetStatus(status)
+	}
+	if change.Assigned != nil {
+		item.SetAssigned(*change.Assigned)
+	}
+	if change.Estimate != nil {
+		item.SetEstimate(*change.Estimate)
+	}
+	if change.Tags != nil {
+		item.SetTags(*change.Tags)
+	}
+	if change.Sprint != nil {
+		item.SetSprint(*change.Sprint)
+	}
+	if change.Epic != nil {
+		item.SetEpic(*change.Epic)
+	}
+	if change.BlockedBy != nil {
+		item.SetBlockedBy(*change.BlockedBy)
+	}
+	if change.Blocks != nil {
+		item.SetBlocks(*change.Blocks)
+	}
+	if change.Author != nil {
+		item.SetAuthor(*change.Author)
+	}
+	for name, value := range change.Fields {
+		field := backlog.FieldByName(item.Fields(), name)
+		if value == nil {
+			item.SetFieldValue(field.Name, "")
+		} else {
+			item.SetFieldValue(field.Name, *value)
+		}
+	}
+	if change.Description != nil {
+		item.SetDescription(*change.Description)
+	}
+	return nil
+}
+
+func (api *API) deleteItem(backlogName, itemName string) error {
+	bck, err := api.loadBacklog(backlogName)
+	if err != nil {
+		return err
+	}
+	item, err := findItem(bck, itemName)
+	if err != nil {
+		return err
+	}
+	return os.Remove(item.Path())
+}
+
+func (api *API) listIdeas() ([]*APIIdea, error) {
+	ideas, err := backlog.LoadIdeas(filepath.Join(api.rootDir, backlog.IdeasDirectoryName))
+	if err != nil {
+		return nil, err
+	}
+	result := make([]*APIIdea, 0, len(ideas))
+	for _, idea := range ideas {
+		result = append(result, newAPIIdea(idea))
+	}
+	return result, nil
+}
+
+func (api *API) getIdea(ideaName string) (*APIIdea, error) {
+	idea, err := api.loadIdea(ideaName)
+	if err != nil {
+		return nil, err
+	}
+	return newAPIIdea(idea), nil
+}
+
+func (api *API) createIdea(r *http.Request) (*APIIdea, error) {
+	var change APIIdeaChange
+	if err := decodeJSON(r, &change); err != nil {
+		return nil, err
+	}
+	if change.Title == nil || strings.TrimSpace(*change.Title) == "" {
+		return nil, newAPIError(http.StatusBadRequest, "the title should be specified")
+	}
+
+	ideaTitle := strings.TrimSpace(*change.Title)
+	ideaName := strings.Replace(ideaTitle, " ", "-", -1)
+	if strings.ContainsAny(ideaName, `/\`) || strings.HasPrefix(ideaName, ".") {
+		return nil, newAPIError(http.StatusBadRequest, "'%s' can't be used as an idea name", ideaName)
+	}
+	ideaPath := filepath.Join(api.rootDir, backlog.IdeasDirectoryName, fmt.Sprintf("%s.md", ideaName))
+	if _, err := os.Stat(ideaPath); err == nil {
+		return nil, newAPIError(http.StatusConflict, "the idea '%s' already exists", ideaName)
+	}
+	os.MkdirAll(filepath.Dir(ideaPath), 0777)
+
+	idea, err := backlog.LoadBacklogIdea(ideaPath)
+	if err != nil {
+		return nil, err
+	}
+	idea.SetTitle(utils.TitleFirstLetter(ideaTitle))
+	idea.SetCreated("")
+	idea.SetModified("")
+	idea.SetAuthor(api.currentUser())
+	idea.SetTags(nil)
+	change.Title = nil
+	applyIdeaChange(idea, &change)
+	if err := idea.Save(); err != nil {
+		return nil, err
+	}
+	return newAPIIdea(idea), nil
+}
+
+func (api *API) updateIdea(ideaName string, r *http.Request) (*APIIdea, error) {
+	idea, err := api.loadIdea(ideaName)
+	if err != nil {
+		return nil, err
+	}
+	var change APIIdeaChange
+	if err := decodeJSON(r, &change); err != nil {
+		return nil, err
+	}
+	if change.Title != nil && strings.TrimSpace(*change.Title) == "" {
+		return nil, newAPIError(http.StatusBadRequest, "the title can't be empty")
+	}
+	applyIdeaChange(idea, &change)
+	idea.SetModified("")
+	if err := idea.Save(); err != nil {
+		return nil, err
+	}
+	return newAPIIdea(idea), nil
+}
+
+func applyIdeaChange(idea *backlog.BacklogIdea, change *APIIdeaChange) {
+	if change.Title != nil {
+		idea.SetTitle(strings.TrimSpace(*change.Title))
+	}
+	if change.Author != nil {
+		idea.SetAuthor(*change.Author)
+	}
+	if change.Tags != nil {
+		idea.SetTags(*change.Tags)
+	}
+	if change.Text != nil {
+		idea.SetText(*change.Text)
+	}
+}
+
+func (api *API) deleteIdea(ideaName string) error {
+	idea, err := api.loadIdea(ideaName)
+	if err != nil {
+		return err
+	}
+	return os.Remove(idea.Path())
+}
+
+func (api *API) listUsers() []*APIUser {
+	userList := users.NewUserList(api.repo, filepath.Join(api.rootDir, backlog.UsersDirectoryName))
+	result := make([]*APIUser, 0, len(userList.Users()))
+	for _, user := range userList.Users() {
+		result = append(result, &APIUser{Name: user.Name(), Nick: user.Nick(), Email: user.Email()})
+	}
+	return result
+}
+
+func (api *API) listTags() ([]*APITag, error) {
+	tags, err := api.loadTags()
+	if err != nil {
+		return nil, err
+	}
+	result := make([]*APITag, 0, len(tags))
+	for _, tag := range tags {
+		result = append(result, tag)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Tag < result[j].Tag
+	})
+	return result, nil
+}
+
+func (api *API) getTag(tag string) (*APITag, error) {
+	tags, err := api.loadTags()
+	if err != nil {
+		return nil, err
+	}
+	if result := tags[strings.ToLower(tag)]; result != nil {
+		return result, nil
+	}
+	return nil, newAPIError(http.StatusNotFound, "the tag '%s' isn't found", tag)
+}
+
+func (api *API) loadTags() (map[string]*APITag, error) {
+	tags := make(map[string]*APITag)
+	tagByName := func(name string) *APITag {
+		tag := tags[strings.ToLower(name)]
+		if tag == nil {
+			tag = &APITag{Tag: name, Items: make([]*APIItem, 0), Ideas: make([]*APIIdea, 0)}
+			tags[strings.ToLower(name)] = tag
+		}
+		return tag
+	}
+
+	backlogDirs, err := findBacklogDirs(api.rootDir)
+	if err != nil {
+		return nil, err
+	}
+	for _, backlogDir := range backlogDirs {
+		bck, err := backlog.LoadBacklog(backlogDir)
+		if err != nil {
+			return nil, err
+		}
+		for _, item := range bck.ActiveItems() {
+			for _, tag := range item.Tags() {
+				tagByName(tag).Items = append(tagByName(tag).Items, newAPIItem(filepath.Base(backlogDir), item))
+			}
+		}
+	}
+	ideas, err := backlog.LoadIdeas(filepath.Join(api.rootDir, backlog.IdeasDirectoryName))
+	if err != nil {
+		return nil, err
+	}
+	for _, idea := range ideas {
+		for _, tag := range idea.Tags() {
+			tagByName(tag).Ideas = append(tagByName(tag).Ideas, newAPIIdea(idea))
+		}
+	}
+	return tags, nil
+}
+
+func (api *API) loadBacklog(backlogName string) (*backlog.Backlog, error) {
+	if !isBacklogName(api.rootDir, backlogName) {
+		return nil, newAPIError(http.StatusNotFound, "the backlog '%s' isn't found", backlogName)
+	}
+	return backlog.LoadBacklog(filepath.Join(api.rootDir, backlogName))
+}
+
+func (api *API) loadIdea(ideaName string) (*backlog.BacklogIdea, error) {
+	ideaPath := filepath.Join(api.rootDir, backlog.IdeasDirectoryName, fmt.Sprintf("%s.md", ideaName))
+	if _, err := os.Stat(ideaPath); err != nil {
+		return nil, newAPIError(http.StatusNotFound, "the idea '%s' isn't found", ideaName)
+	}
+	return backlog.LoadBacklogIdea(ideaPath)
+}
+
+func (api *API) currentUser() string {
+	if api.author != "" {
+		return api.author
+	}
+	currentUser, _, err := api.repo.CurrentUser()
+	if err != nil {
+		return "unknown"
+	}
+	return currentUser
+}
+
+func findItem(bck *backlog.Backlog, itemName string) (*backlog.BacklogItem, error) {
+	for _, item := range bck.AllItems() {
+		if item.Name() == itemName {
+			return item, nil
+		}
+	}
+	return nil, newAPIError(http.StatusNotFound, "the item '%s' isn't found", itemName)
+}
+
+func statusByNameOrCode(value string) *backlog.BacklogItemStatus {
+	if status := backlog.StatusByName(value); status != nil {
+		return status
+	}
+	return backlog.StatusByCode(value)
+}
+
+func decodeJSON(r *http.Request, value interface{}) error {
+	if err := json.NewDecoder(r.Body).Decode(value); err != nil {
+		return newAPIError(http.StatusBadRequest, "invalid JSON: %v", err)
+	}
+	return nil
+}
+
+func newAPIItem(backlogName string, item *backlog.BacklogItem) *APIItem {
+	result := &APIItem{
+		Backlog:     backlogName,
+		Name:        item.Name(),
+		Title:       item.Title(),
+		Status:      item.Status(),
+		Assigned:    item.Assigned(),
+		Estimate:    item.Estimate(),
+		Tags:        item.Tags(),
+		Sprint:      item.Sprint(),
+		Epic:        item.Epic(),
+		BlockedBy:   item.BlockedBy(),
+		Blocks:      item.Blocks(),
+		Author:      item.Author(),
+		Created:     item.Created(),
+		Modified:    item.Modified(),
+		Archived:    item.Archived(),
+		Description: item.Description(),
+	}
+	if result.Tags == nil {
+		result.Tags = make([]string, 0)
+	}
+	if len(item.Fields()) > 0 {
+		result.Fields = make(map[string]string, len(item.Fields()))
+		for _, field := range item.Fields() {
+			result.Fields[field.Name] = item.FieldValue(field.Name)
+		}
+	}
+	return result
+}
+
+func newAPIIdea(idea *backlog.BacklogIdea) *APIIdea {
+	result := &APIIdea{
+		Name:     idea.Name(),
+		Title:    idea.Title(),
+		Author:   idea.Author(),
+		Tags:     idea.Tags(),
+		Created:  idea.Created(),
+		Modified: idea.Modified(),
+		Text:     strings.TrimLeft(idea.Text(), "\n"),
+	}
+	if result.Tags == nil {
+		result.Tags = make([]string, 0)
+	}
+	return result
+}
@@ -0,0 +1,162 @@
+package server
+
+import (
+	"fmt"
+	"github.com/mreider/agilemarkdown/backlog"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+type boardData struct {
+	Title   string
+	Path    string
+	Columns []*boardColumn
+}
+
+type boardColumn struct {
+	Status string
+	Title  string
+	Cards  []*boardCard
+}
+
+type boardCard struct {
+	Name     string
+	Title    string
+	Link     string
+	Assigned string
+	Estimate string
+	Blocked  bool
+}
+
+func (s *Server) showBoard(w http.ResponseWriter, overviewPath, urlPath string) {
+	bck, overview, err := s.loadBoard(overviewPath)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	allItems, err := s.loadAllItems()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	deps := backlog.NewDependencyGraph(s.rootDir, allItems)
+
+	data := &boardData{Title: overview.Title(), Path: urlPath}
+	sorter := backlog.NewBacklogItemsSorter(overview)
+	backlogURL := strings.TrimSuffix(urlPath, ".md")
+	for _, status := range backlog.AllStatuses {
+		items := bck.FilteredActiveItems(backlog.NewBacklogItemsStatusCodeFilter(status.Code))
+		sorter.SortItemsByStatus(status, items)
+		column := &boardColumn{Status: status.Name, Title: status.CapitalizedName()}
+		for _, item := range items {
+			column.Cards = append(column.Cards, &boardCard{
+				Name:     item.Name(),
+				Title:    item.Title(),
+				Link:     fmt.Sprintf("%s/%s.md", backlogURL, item.Name()),
+				Assigned: item.Assigned(),
+				Estimate: item.Estimate(),
+				Blocked:  deps.IsBlocked(item),
+			})
+		}
+		data.Columns = append(data.Columns, column)
+	}
+	if err := boardTemplate.Execute(w, data); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+func (s *Server) moveItem(w http.ResponseWriter, r *http.Request, overviewPath string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	bck, overview, err := s.loadBoard(overviewPath)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	var item *backlog.BacklogItem
+	for _, activeItem := range bck.ActiveItems() {
+		if activeItem.Name() == r.PostFormValue("item") {
+			item = activeItem
+			break
+		}
+	}
+	if item == nil {
+		http.Error(w, fmt.Sprintf("unknown story '%s'", r.PostFormValue("item")), http.StatusBadRequest)
+		return
+	}
+	status := backlog.StatusByName(r.PostFormValue("status"))
+	if status == nil {
+		http.Error(w, fmt.Sprintf("unknown status '%s'", r.PostFormValue("status")), http.StatusBadRequest)
+		return
+	}
+	if !backlog.IsAllowedTransition(backlog.StatusByName(item.Status()), status) {
+		http.Error(w, fmt.Sprintf("the status can't be changed from '%s' to '%s'", item.Status(), status.Name), http.StatusBadRequest)
+		return
+	}
+	index, err := strconv.Atoi(r.PostFormValue("index"))
+	if err != nil {
+		http.Error(w, fmt.Sprintf("invalid index '%s'", r.PostFormValue("index")), http.StatusBadRequest)
+		return
+	}
+
+	allItems, err := s.loadAllItems()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if strings.ToLower(item.Status()) != status.Name {
+		item.SetStatus(status)
+		item.SetModified()
+		if err := item.Save(); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	}
+	overview.SetDependencies(backlog.NewDependencyGraph(s.rootDir, allItems))
+	overview.MoveItem(bck.ActiveItems(), item, index)
+
+	if s.commit {
+		if err := s.commitFiles(fmt.Sprintf("Move %s to %s", item.Title(), status.Name), item.Path(), overviewPath); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) loadBoard(overviewPath string) (*backlog.Backlog, *backlog.BacklogOverview, error) {
+	bck, err := backlog.LoadBacklog(strings.TrimSuffix(overviewPath, filepath.Ext(overviewPath)))
+	if err != nil {
+		return nil, nil, err
+	}
+	overview, err := backlog.LoadBacklogOverview(overviewPath)
+	if err != nil {
+		return nil, nil, err
+	}
+	overview.SetColumns(backlog.ColumnFields(bck.Fields()))
+	return bck, overview, nil
+}
+
+func (s *Server) loadAllItems() ([]*backlog.BacklogItem, error) {
+	backlogDirs, err := findBacklogDirs(s.rootDir)
+	if err != nil {
+		return nil, err
+	}
+	var items []*backlog.BacklogItem
+	for _, backlogDir := range backlogDirs {
+		bck, err := backlog.LoadBacklog(backlogDir)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, bck.AllItems()...)
+	}
+	return items, nil
+}
@@ -0,0 +1,140 @@
+package server
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+var (
+	headerRe       = regexp.MustCompile(`^(#{1,6})\s+(.*?)\s*#*\s*$`)
+	listItemRe     = regexp.MustCompile(`^\s*(?:[-*+]|\d+\.)\s+(.*)$`)
+	tableDividerRe = regexp.MustCompile(`^\|?\s*:?-+:?\s*(\|\s*:?-+:?\s*)*\|?$`)
+	imageRe        = regexp.MustCompile(`!\[([^\]]*)\]\(([^)\s]+)\)`)
+	linkRe         = regexp.MustCompile(`\[([^\]]+)\]\(([^)\s]+)\)`)
+	boldRe         = regexp.MustCompile(`\*\*([^*]+)\*\*`)
+	italicRe       = regexp.MustCompile(`\*([^*\s][^*]*)\*`)
+	codeRe         = regexp.MustCompile("`([^`]+)`")
+
+	allowedLinkSchemes = map[string]bool{"http": true, "https": true, "mailto": true}
+)
+
+func markdownToHTML(data string) string {
+	lines := strings.Split(strings.Replace(data, "\r\n", "\n", -1), "\n")
+	result := bytes.NewBuffer(nil)
+
+	if len(lines) > 0 && strings.TrimSpace(lines[0]) == "---" {
+		for i := 1; i < len(lines); i++ {
+			if strings.TrimSpace(lines[i]) == "---" {
+				result.WriteString(fmt.Sprintf("<pre class=\"metadata\">%s</pre>\n", html.EscapeString(strings.Join(lines[1:i], "\n"))))
+				lines = lines[i+1:]
+				break
+			}
+		}
+	}
+
+	var paragraph []string
+	flushParagraph := func() {
+		if len(paragraph) == 0 {
+			return
+		}
+		result.WriteString("<p>")
+		for i, line := range paragraph {
+			result.WriteString(renderInline(strings.TrimSpace(line)))
+			if i < len(paragraph)-1 {
+				if strings.HasSuffix(line, "  ") {
+					result.WriteString("<br>")
+				}
+				result.WriteString("\n")
+			}
+		}
+		result.WriteString("</p>\n")
+		paragraph = nil
+	}
+
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case trimmed == "":
+			flushParagraph()
+		case strings.HasPrefix(trimmed, "```"):
+			flushParagraph()
+			var code []string
+			for i++; i < len(lines) && !strings.HasPrefix(strings.TrimSpace(lines[i]), "```"); i++ {
+				code = append(code, lines[i])
+			}
+			result.WriteString(fmt.Sprintf("<pre><code>%s</code></pre>\n", html.EscapeString(strings.Join(code, "\n"))))
+		case headerRe.MatchString(trimmed):
+			flushParagraph()
+			matches := headerRe.FindStringSubmatch(trimmed)
+			result.WriteString(fmt.Sprintf("<h%d>%s</h%d>\n", len(matches[1]), renderInline(matches[2]), len(matches[1])))
+		case strings.HasPrefix(trimmed, "|") && i+1 < len(lines) && tableDividerRe.MatchString(strings.TrimSpace(lines[i+1])):
+			flushParagraph()
+			result.WriteString("<table>\n<thead><tr>")
+			for _, cell := range splitTableRow(trimmed) {
+				result.WriteString(fmt.Sprintf("<th>%s</th>", renderInline(cell)))
+			}
+			result.WriteString("</tr></thead>\n<tbody>\n")
+			for i += 2; i < len(lines) && strings.HasPrefix(strings.TrimSpace(lines[i]), "|"); i++ {
+				result.WriteString("<tr>")
+				for _, cell := range splitTableRow(strings.TrimSpace(lines[i])) {
+					result.WriteString(fmt.Sprintf("<td>%s</td>", renderInline(cell)))
+				}
+				result.WriteString("</tr>\n")
+			}
+			i--
+			result.WriteString("</tbody>\n</table>\n")
+		case listItemRe.MatchString(line) && len(paragraph) == 0:
+			result.WriteString("<ul>\n")
+			for ; i < len(lines) && listItemRe.MatchString(lines[i]); i++ {
+				result.WriteString(fmt.Sprintf("<li>%s</li>\n", renderInline(listItemRe.FindStringSubmatch(lines[i])[1])))
+			}
+			i--
+			result.WriteString("</ul>\n")
+		default:
+			paragraph = append(paragraph, line)
+		}
+	}
+	flushParagraph()
+	return result.String()
+}
+
+func splitTableRow(row string) []string {
+	row = strings.TrimPrefix(row, "|")
+	row = strings.TrimSuffix(row, "|")
+	cells := strings.Split(row, "|")
+	for i, cell := range cells {
+		cells[i] = strings.TrimSpace(cell)
+	}
+	return cells
+}
+
+func renderInline(text string) string {
+	text = html.EscapeString(text)
+	text = imageRe.ReplaceAllStringFunc(text, func(image string) string {
+		match := imageRe.FindStringSubmatch(image)
+		return fmt.Sprintf(`<img alt="%s" src="%s">`, match[1], safeLink(match[2]))
+	})
+	text = linkRe.ReplaceAllStringFunc(text, func(link string) string {
+		match := linkRe.FindStringSubmatch(link)
+		return fmt.Sprintf(`<a href="%s">%s</a>`, safeLink(match[2]), match[1])
+	})
+	text = boldRe.ReplaceAllString(text, `<strong>$1</strong>`)
+	text = italicRe.ReplaceAllString(text, `<em>$1</em>`)
+	text = codeRe.ReplaceAllString(text, `<code>$1</code>`)
+	return text
+}
+
+// safeLink returns the escaped link if it is relative or uses a scheme allowed on the pages, "#" otherwise.
+func safeLink(escapedLink string) string {
+	link := html.UnescapeString(escapedLink)
+	linkUrl, err := url.Parse(link)
+	if err != nil || (linkUrl.Scheme != "" && !allowedLinkSchemes[linkUrl.Scheme]) {
+		return "#"
+	}
+	return html.EscapeString(link)
+}
@@ -0,0 +1,326 @@
+package server
+
+import (
+	"fmt"
+	"github.com/mreider/agilemarkdown/backlog"
+	"github.com/mreider/agilemarkdown/git"
+	"github.com/mreider/agilemarkdown/users"
+	"html/template"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+type Server struct {
+	repo    git.Repository
+	rootDir string
+	commit  bool
+	author  string
+	mutex   sync.Mutex
+}
+
+type pageData struct {
+	Title    string
+	Path     string
+	Content  template.HTML
+	Editable bool
+	Board    bool
+}
+
+type editData struct {
+	Title       string
+	Path        string
+	Error       string
+	Statuses    []*backlog.BacklogItemStatus
+	Status      string
+	Metadata    []*editValue
+	Fields      []*editValue
+	Description string
+}
+
+type editValue struct {
+	Name   string
+	Value  string
+	Values []string
+}
+
+var editableMetadataKeys = []string{
+	backlog.BacklogItemAssignedMetadataKey, backlog.BacklogItemEstimateMetadataKey, backlog.BacklogItemTagsMetadataKey,
+	backlog.BacklogItemSprintMetadataKey, backlog.BacklogItemTypeMetadataKey, backlog.BacklogItemEpicMetadataKey,
+	backlog.BacklogItemBlockedByMetadataKey, backlog.BacklogItemBlocksMetadataKey,
+}
+
+func NewServer(repo git.Repository, rootDir string, commit bool, author string) *Server {
+	rootDir, _ = filepath.Abs(rootDir)
+	return &Server{repo: repo, rootDir: rootDir, commit: commit, author: author}
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	urlPath := path.Clean("/" + r.URL.Path)
+	if urlPath == "/" {
+		urlPath = "/index.md"
+	}
+	for _, part := range strings.Split(urlPath, "/") {
+		if strings.HasPrefix(part, ".") {
+			http.NotFound(w, r)
+			return
+		}
+	}
+	filePath := filepath.Join(s.rootDir, filepath.FromSlash(urlPath))
+
+	info, err := os.Stat(filePath)
+	if err != nil || info.IsDir() {
+		http.NotFound(w, r)
+		return
+	}
+	if filepath.Ext(filePath) != ".md" {
+		http.ServeFile(w, r, filePath)
+		return
+	}
+
+	if r.Method == http.MethodPost && isCrossOriginRequest(r) {
+		http.Error(w, "Cross-origin requests aren't allowed", http.StatusForbidden)
+		return
+	}
+
+	isItem, isOverview := s.isItemPath(filePath), s.isOverviewPath(filePath)
+	isBoard := r.URL.Query().Get("board") != "" && isOverview
+	switch {
+	case r.Method == http.MethodPost && isBoard:
+		s.moveItem(w, r, filePath)
+	case isBoard:
+		s.showBoard(w, filePath, urlPath)
+	case r.Method == http.MethodPost && isItem:
+		s.saveItem(w, r, filePath, urlPath)
+	case r.Method == http.MethodPost:
+		http.Error(w, "Only stories can be edited", http.StatusMethodNotAllowed)
+	case r.URL.Query().Get("edit") != "" && isItem:
+		s.showEditForm(w, filePath, urlPath, nil)
+	default:
+		s.showPage(w, filePath, urlPath, isItem, isOverview)
+	}
+}
+
+func (s *Server) showPage(w http.ResponseWriter, filePath, urlPath string, isItem, isOverview bool) {
+	data, err := ioutil.ReadFile(filePath)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	page := &pageData{
+		Title:    strings.TrimSuffix(filepath.Base(filePath), ".md"),
+		Path:     urlPath,
+		Content:  template.HTML(markdownToHTML(string(data))),
+		Editable: isItem,
+		Board:    isOverview,
+	}
+	if err := pageTemplate.Execute(w, page); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+func (s *Server) showEditForm(w http.ResponseWriter, filePath, urlPath string, editErr error) {
+	item, err := s.loadItem(filePath)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	data := &editData{
+		Title:       item.Title(),
+		Path:        urlPath,
+		Statuses:    backlog.AllStatuses,
+		Status:      strings.ToLower(item.Status()),
+		Description: item.Description(),
+	}
+	if editErr != nil {
+		data.Error = editErr.Error()
+	}
+	for _, key := range editableMetadataKeys {
+		data.Metadata = append(data.Metadata, &editValue{Name: key, Value: item.FieldValue(key)})
+	}
+	for _, field := range item.Fields() {
+		data.Fields = append(data.Fields, &editValue{Name: field.Name, Value: item.FieldValue(field.Name), Values: field.Values})
+	}
+	if err := editTemplate.Execute(w, data); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+func (s *Server) saveItem(w http.ResponseWriter, r *http.Request, filePath, urlPath string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	item, err := s.loadItem(filePath)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if err := s.updateItem(item, r); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		s.showEditForm(w, filePath, urlPath, err)
+		return
+	}
+	if err := item.Save(); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if s.commit {
+		if err := s.commitItem(item); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	}
+	http.Redirect(w, r, urlPath, http.StatusSeeOther)
+}
+
+func (s *Server) updateItem(item *backlog.BacklogItem, r *http.Request) error {
+	title := strings.TrimSpace(r.PostFormValue("Title"))
+	if title == "" {
+		return fmt.Errorf("the title can't be empty")
+	}
+
+	status := backlog.StatusByName(r.PostFormValue("Status"))
+	if status == nil {
+		return fmt.Errorf("unknown status '%s'", r.PostFormValue("Status"))
+	}
+	if !backlog.IsAllowedTransition(backlog.StatusByName(item.Status()), status) {
+		return fmt.Errorf("the status can't be changed from '%s' to '%s'", item.Status(), status.Name)
+	}
+
+	userList := users.NewUserList(s.repo, filepath.Join(s.rootDir, backlog.UsersDirectoryName))
+	isKnownUser := func(user string) bool {
+		return userList.User(user) != nil
+	}
+	for _, field := range item.Fields() {
+		value := strings.TrimSpace(r.PostFormValue(field.Name))
+		if err := field.Validate(value, isKnownUser); err != nil {
+			return fmt.Errorf("%s: %v", field.Name, err)
+		}
+	}
+
+	item.SetTitle(title)
+	item.SetStatus(status)
+	for _, key := range editableMetadataKeys {
+		value := strings.TrimSpace(r.PostFormValue(key))
+		if value == "" && !item.HasFieldValue(key) {
+			continue
+		}
+		switch key {
+		case backlog.BacklogItemTagsMetadataKey:
+			item.SetTags(strings.Fields(value))
+		case backlog.BacklogItemBlockedByMetadataKey:
+			item.SetBlockedBy(strings.Fields(value))
+		case backlog.BacklogItemBlocksMetadataKey:
+			item.SetBlocks(strings.Fields(value))
+		default:
+			item.SetFieldValue(key, value)
+		}
+	}
+	for _, field := range item.Fields() {
+		value := strings.TrimSpace(r.PostFormValue(field.Name))
+		if value == "" && !item.HasFieldValue(field.Name) {
+			continue
+		}
+		item.SetFieldValue(field.Name, value)
+	}
+	item.SetDescription(strings.Replace(r.PostFormValue("Description"), "\r\n", "\n", -1))
+	return nil
+}
+
+func (s *Server) commitItem(item *backlog.BacklogItem) error {
+	return s.commitFiles(fmt.Sprintf("Edit %s", item.Title()), item.Path())
+}
+
+func (s *Server) commitFiles(msg string, paths ...string) error {
+	for _, path := range paths {
+		if err := s.repo.Add(path); err != nil {
+			return err
+		}
+	}
+	stagedChanges, err := s.repo.StagedChanges()
+	if err != nil {
+		return err
+	}
+	if len(stagedChanges) == 0 {
+		return nil
+	}
+	return s.repo.Commit(msg, s.author)
+}
+
+func (s *Server) loadItem(filePath string) (*backlog.BacklogItem, error) {
+	fields, err := backlog.LoadBacklogFields(s.backlogDir(filePath))
+	if err != nil {
+		return nil, err
+	}
+	return backlog.LoadBacklogItem(filePath, fields...)
+}
+
+func (s *Server) backlogDir(filePath string) string {
+	dir := filepath.Dir(filePath)
+	if filepath.Base(dir) == backlog.ArchiveDirectoryName {
+		dir = filepath.Dir(dir)
+	}
+	return dir
+}
+
+func (s *Server) isItemPath(filePath string) bool {
+	itemName := strings.TrimSuffix(filepath.Base(filePath), ".md")
+	if backlog.IsForbiddenItemName(itemName) {
+		return false
+	}
+	backlogDir := s.backlogDir(filePath)
+	return filepath.Dir(backlogDir) == s.rootDir && isBacklogName(s.rootDir, filepath.Base(backlogDir))
+}
+
+func (s *Server) isOverviewPath(filePath string) bool {
+	return filepath.Dir(filePath) == s.rootDir && isBacklogName(s.rootDir, strings.TrimSuffix(filepath.Base(filePath), ".md"))
+}
+
+func findBacklogDirs(rootDir string) ([]string, error) {
+	infos, err := ioutil.ReadDir(rootDir)
+	if err != nil {
+		return nil, err
+	}
+	var result []string
+	for _, info := range infos {
+		if info.IsDir() && isBacklogName(rootDir, info.Name()) {
+			result = append(result, filepath.Join(rootDir, info.Name()))
+		}
+	}
+	return result, nil
+}
+
+func isBacklogName(rootDir, name string) bool {
+	if strings.HasPrefix(name, ".") || backlog.IsForbiddenBacklogName(name) {
+		return false
+	}
+	if info, err := os.Stat(filepath.Join(rootDir, name)); err != nil || !info.IsDir() {
+		return false
+	}
+	info, err := os.Stat(filepath.Join(rootDir, name+".md"))
+	return err == nil && !info.IsDir()
+}
+
+// isCrossOriginRequest checks that a request changing the files is sent by the pages of the server itself.
+// Browsers set Origin or Referer for such requests, the requests of other clients have neither of them.
+func isCrossOriginRequest(r *http.Request) bool {
+	source := r.Header.Get("Origin")
+	if source == "" {
+		source = r.Referer()
+	}
+	if source == "" {
+		return false
+	}
+	sourceUrl, err := url.Parse(source)
+	return err != nil || !strings.EqualFold(sourceUrl.Host, r.Host)
+}
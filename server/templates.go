@@ -0,0 +1,137 @@
+package server
+
+import (
+	"html/template"
+)
+
+const styles = `
+body { font-family: -apple-system, "Segoe UI", Helvetica, Arial, sans-serif; margin: 2em auto; max-width: 60em; padding: 0 1em; color: #24292e; }
+table { border-collapse: collapse; margin: 1em 0; }
+th, td { border: 1px solid #dfe2e5; padding: 0.3em 0.8em; text-align: left; }
+pre { background: #f6f8fa; padding: 1em; overflow: auto; }
+a { color: #0366d6; text-decoration: none; }
+.toolbar { float: right; }
+.error { color: #cb2431; }
+label { display: block; margin-top: 0.8em; font-weight: bold; }
+input[type=text], select, textarea { width: 100%; box-sizing: border-box; font: inherit; padding: 0.3em; }
+textarea { height: 25em; font-family: monospace; }
+.board { display: flex; align-items: flex-start; overflow-x: auto; }
+.column { flex: 1; min-width: 12em; min-height: 10em; margin-right: 0.8em; padding: 0.5em; background: #f6f8fa; }
+.column h2 { font-size: 1em; margin: 0 0 0.5em 0; }
+.card { background: #fff; border: 1px solid #dfe2e5; margin-bottom: 0.5em; padding: 0.5em; cursor: move; }
+.card .details { color: #586069; font-size: 0.85em; }
+.card.blocked { border-left: 3px solid #cb2431; }
+`
+
+var pageTemplate = template.Must(template.New("page").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+<style>` + styles + `</style>
+</head>
+<body>
+{{if .Editable}}<div class="toolbar"><a href="{{.Path}}?edit=1">Edit</a></div>{{end}}
+{{if .Board}}<div class="toolbar"><a href="{{.Path}}?board=1">Board</a></div>{{end}}
+{{.Content}}
+</body>
+</html>
+`))
+
+var editTemplate = template.Must(template.New("edit").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Edit {{.Title}}</title>
+<style>` + styles + `</style>
+</head>
+<body>
+<div class="toolbar"><a href="{{.Path}}">Cancel</a></div>
+<h1>Edit {{.Title}}</h1>
+{{if .Error}}<p class="error">{{.Error}}</p>{{end}}
+<form method="post" action="{{.Path}}">
+<label for="Title">Title</label>
+<input type="text" id="Title" name="Title" value="{{.Title}}">
+<label for="Status">Status</label>
+<select id="Status" name="Status">
+{{range .Statuses}}<option value="{{.Name}}"{{if eq .Name $.Status}} selected{{end}}>{{.Name}}</option>
+{{end}}</select>
+{{range .Metadata}}<label for="{{.Name}}">{{.Name}}</label>
+<input type="text" id="{{.Name}}" name="{{.Name}}" value="{{.Value}}">
+{{end}}{{range .Fields}}<label for="{{.Name}}">{{.Name}}</label>
+{{if .Values}}{{$value := .Value}}<select id="{{.Name}}" name="{{.Name}}">
+<option value=""></option>
+{{range .Values}}<option value="{{.}}"{{if eq . $value}} selected{{end}}>{{.}}</option>
+{{end}}</select>
+{{else}}<input type="text" id="{{.Name}}" name="{{.Name}}" value="{{.Value}}">
+{{end}}{{end}}<label for="Description">Description</label>
+<textarea id="Description" name="Description">{{.Description}}</textarea>
+<p><input type="submit" value="Save"></p>
+</form>
+</body>
+</html>
+`))
+
+var boardTemplate = template.Must(template.New("board").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+<style>` + styles + `</style>
+</head>
+<body style="max-width: none">
+<div class="toolbar"><a href="{{.Path}}">Project page</a></div>
+<h1>{{.Title}}</h1>
+<div class="board">
+{{range .Columns}}<div class="column" data-status="{{.Status}}">
+<h2>{{.Title}}</h2>
+{{range .Cards}}<div class="card{{if .Blocked}} blocked{{end}}" draggable="true" data-name="{{.Name}}">
+<a href="{{.Link}}">{{.Title}}</a>
+<div class="details">{{.Assigned}}{{if .Estimate}} &middot; {{.Estimate}} points{{end}}{{if .Blocked}} &middot; blocked{{end}}</div>
+</div>
+{{end}}</div>
+{{end}}</div>
+<script>
+var dragged = null;
+document.querySelectorAll(".card").forEach(function(card) {
+  card.addEventListener("dragstart", function(e) {
+    dragged = card;
+    e.dataTransfer.setData("text/plain", card.dataset.name);
+  });
+});
+document.querySelectorAll(".column").forEach(function(column) {
+  column.addEventListener("dragover", function(e) {
+    e.preventDefault();
+  });
+  column.addEventListener("drop", function(e) {
+    e.preventDefault();
+    if (!dragged) {
+      return;
+    }
+    var cards = Array.prototype.filter.call(column.querySelectorAll(".card"), function(card) {
+      return card !== dragged;
+    });
+    var index = cards.length;
+    for (var i = 0; i < cards.length; i++) {
+      var rect = cards[i].getBoundingClientRect();
+      if (e.clientY < rect.top + rect.height / 2) {
+        index = i;
+        break;
+      }
+    }
+    var body = new URLSearchParams({item: dragged.dataset.name, status: column.dataset.status, index: index});
+    fetch(location.pathname + "?board=1", {method: "POST", body: body}).then(function(resp) {
+      if (!resp.ok) {
+        return resp.text().then(function(text) {
+          alert(text);
+        });
+      }
+    }).then(function() {
+      location.reload();
+    });
+  });
+});
+</script>
+</body>
+</html>
+`))
@@ -0,0 +1,134 @@
+package tests
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/mreider/agilemarkdown/backlog"
+	"github.com/mreider/agilemarkdown/git"
+	"github.com/mreider/agilemarkdown/server"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestAPI(t *testing.T) {
+	rootDir, err := ioutil.TempDir("", "api")
+	assert.Nil(t, err)
+	defer os.RemoveAll(rootDir)
+
+	files := map[string]string{
+		"proj.md":            "# Paint the house\n",
+		"proj/prep.md":       "# Prep\n\nStatus: doing\nAssigned: bob\nEstimate: 3\nTags: outside\n",
+		"proj/buy-paint.md":  "# Buy paint\n\nStatus: planned\nTags: shopping outside\n",
+		"proj/old.md":        "# Old\n\nStatus: finished\nArchive: true\n",
+		"ideas/ladder.md":    "# Ladder\n\nTags: shopping\n\nA taller ladder.\n",
+		"users/Bob Smith":    "bob@example.com",
+		"users/Alice Carter": "alice@example.com",
+	}
+	for name, data := range files {
+		filePath := filepath.Join(rootDir, filepath.FromSlash(name))
+		assert.Nil(t, os.MkdirAll(filepath.Dir(filePath), 0755))
+		assert.Nil(t, ioutil.WriteFile(filePath, []byte(data), 0644))
+	}
+	changes := 0
+	var syncErr error
+	api := server.NewAPI(git.NewMemoryRepository(rootDir, "tester", "tester@example.com"), rootDir, "tester", func() error {
+		changes++
+		return syncErr
+	})
+
+	request := func(method, target, body string, result interface{}) int {
+		w := httptest.NewRecorder()
+		api.ServeHTTP(w, httptest.NewRequest(method, target, strings.NewReader(body)))
+		if result != nil {
+			assert.Nil(t, json.Unmarshal(w.Body.Bytes(), result), w.Body.String())
+		}
+		return w.Code
+	}
+
+	var backlogs []*server.APIBacklog
+	assert.Equal(t, http.StatusOK, request(http.MethodGet, "/api/backlogs", "", &backlogs))
+	assert.Equal(t, 1, len(backlogs))
+	assert.Equal(t, "Paint the house", backlogs[0].Title)
+	assert.Equal(t, 2, backlogs[0].Items)
+
+	var items []*server.APIItem
+	assert.Equal(t, http.StatusOK, request(http.MethodGet, "/api/backlogs/proj/items?tags=outside&status=p", "", &items))
+	assert.Equal(t, 1, len(items))
+	assert.Equal(t, "buy-paint", items[0].Name)
+	assert.Equal(t, http.StatusOK, request(http.MethodGet, "/api/backlogs/proj/items?archived=all", "", &items))
+	assert.Equal(t, 3, len(items))
+	assert.Equal(t, http.StatusBadRequest, request(http.MethodGet, "/api/backlogs/proj/items?status=unknown", "", nil))
+	assert.Equal(t, http.StatusNotFound, request(http.MethodGet, "/api/backlogs/users/items", "", nil))
+
+	crossOrigin := httptest.NewRequest(http.MethodDelete, "/api/backlogs/proj/items/prep", nil)
+	crossOrigin.Header.Set("Origin", "http://evil.example.org")
+	w := httptest.NewRecorder()
+	api.ServeHTTP(w, crossOrigin)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	var item server.APIItem
+	assert.Equal(t, http.StatusCreated, request(http.MethodPost, "/api/backlogs/proj/items", `{"title": "paint the fence", "estimate": "5", "tags": ["outside"]}`, &item))
+	assert.Equal(t, "paint-the-fence", item.Name)
+	assert.Equal(t, "Paint the fence", item.Title)
+	assert.Equal(t, backlog.InitialStatus().Name, item.Status)
+	assert.Equal(t, "tester", item.Author)
+	assert.Contains(t, item.Description, "## Problem statement")
+	assert.Equal(t, http.StatusConflict, request(http.MethodPost, "/api/backlogs/proj/items", `{"title": "paint the fence"}`, nil))
+
+	assert.Equal(t, http.StatusOK, request(http.MethodPatch, "/api/backlogs/proj/items/paint-the-fence", `{"status": "d", "assigned": "alice"}`, &item))
+	assert.Equal(t, "doing", item.Status)
+	assert.Equal(t, "alice", item.Assigned)
+	assert.Equal(t, "5", item.Estimate)
+	assert.Equal(t, http.StatusBadRequest, request(http.MethodPatch, "/api/backlogs/proj/items/paint-the-fence", `{"assigned": "nobody"}`, nil))
+	assert.Equal(t, http.StatusBadRequest, request(http.MethodPatch, "/api/backlogs/proj/items/paint-the-fence", `{"status": `, nil))
+
+	saved, err := backlog.LoadBacklogItem(filepath.Join(rootDir, "proj", "paint-the-fence.md"))
+	assert.Nil(t, err)
+	assert.Equal(t, "alice", saved.Assigned())
+
+	assert.Equal(t, http.StatusNoContent, request(http.MethodDelete, "/api/backlogs/proj/items/paint-the-fence", "", nil))
+	assert.Equal(t, http.StatusNotFound, request(http.MethodGet, "/api/backlogs/proj/items/paint-the-fence", "", nil))
+	assert.Equal(t, http.StatusMethodNotAllowed, request(http.MethodDelete, "/api/backlogs/proj/items", "", nil))
+
+	var idea server.APIIdea
+	assert.Equal(t, http.StatusCreated, request(http.MethodPost, "/api/ideas", `{"title": "solar panels", "text": "Cheaper energy."}`, &idea))
+	assert.Equal(t, "solar-panels", idea.Name)
+	assert.Equal(t, http.StatusOK, request(http.MethodPatch, "/api/ideas/solar-panels", `{"tags": ["roof"]}`, &idea))
+	assert.Equal(t, []string{"roof"}, idea.Tags)
+	assert.Equal(t, "Cheaper energy.", idea.Text)
+	var ideas []*server.APIIdea
+	assert.Equal(t, http.StatusOK, request(http.MethodGet, "/api/ideas", "", &ideas))
+	assert.Equal(t, 2, len(ideas))
+
+	var users []*server.APIUser
+	assert.Equal(t, http.StatusOK, request(http.MethodGet, "/api/users", "", &users))
+	// the current user of the repository is added to the users
+	assert.Equal(t, 3, len(users))
+
+	var tags []*server.APITag
+	assert.Equal(t, http.StatusOK, request(http.MethodGet, "/api/tags", "", &tags))
+	assert.Equal(t, []string{"outside", "roof", "shopping"}, []string{tags[0].Tag, tags[1].Tag, tags[2].Tag})
+	var tag server.APITag
+	assert.Equal(t, http.StatusOK, request(http.MethodGet, "/api/tags/shopping", "", &tag))
+	assert.Equal(t, 1, len(tag.Items))
+	assert.Equal(t, 1, len(tag.Ideas))
+
+	assert.Equal(t, 5, changes)
+
+	syncErr = errors.New("can't push")
+	item = server.APIItem{}
+	assert.Equal(t, http.StatusOK, request(http.MethodPatch, "/api/backlogs/proj/items/prep", `{"estimate": "5"}`, &item))
+	assert.Equal(t, "5", item.Estimate)
+	assert.Equal(t, "can't push", item.SyncWarning)
+	var warning server.APISyncWarning
+	assert.Equal(t, http.StatusOK, request(http.MethodDelete, "/api/ideas/solar-panels", "", &warning))
+	assert.Equal(t, "can't push", warning.SyncWarning)
+	assert.Equal(t, http.StatusNotFound, request(http.MethodGet, "/api/ideas/solar-panels", "", nil))
+	assert.Equal(t, 7, changes)
+}
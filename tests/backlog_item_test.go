@@ -3,6 +3,10 @@ package tests
 import (
 	"github.com/mreider/agilemarkdown/backlog"
 	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 )
 
@@ -86,3 +90,24 @@ func TestBacklogItem(t *testing.T) {
 	assert.Equal(t, "What?", comments[4].Text[0])
 	assert.Equal(t, "How to do?", comments[4].Text[1])
 }
+
+func TestBacklogItemFrontMatterKeepsModified(t *testing.T) {
+	rootDir, err := ioutil.TempDir("", "item")
+	assert.Nil(t, err)
+	defer os.RemoveAll(rootDir)
+
+	itemPath := filepath.Join(rootDir, "comments.md")
+	assert.Nil(t, ioutil.WriteFile(itemPath, []byte(itemMarkdownData), 0644))
+	item, err := backlog.LoadBacklogItem(itemPath)
+	assert.Nil(t, err)
+	assert.Nil(t, item.SetFrontMatter(true))
+	assert.Nil(t, item.SaveUnmodified())
+
+	data, err := ioutil.ReadFile(itemPath)
+	assert.Nil(t, err)
+	assert.True(t, strings.HasPrefix(string(data), "---\n"))
+	item, err = backlog.LoadBacklogItem(itemPath)
+	assert.Nil(t, err)
+	assert.Equal(t, "2018-05-03 15:32", item.Created().Format("2006-01-02 15:04"))
+	assert.Equal(t, "2018-05-08 21:18", item.Modified().Format("2006-01-02 15:04"))
+}
@@ -0,0 +1,38 @@
+package tests
+
+import (
+	"github.com/mreider/agilemarkdown/backlog"
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+)
+
+func TestCommitSummary(t *testing.T) {
+	item := func(name, title, status, estimate, assigned string) *backlog.BacklogItem {
+		lines := []string{"# " + title, "", "Status: " + status, "Estimate: " + estimate, "Assigned: " + assigned, "", "Text"}
+		return backlog.NewBacklogItem(name, strings.Join(lines, "\n"))
+	}
+	changes := []*backlog.ItemChange{
+		{Before: item("a", "Login", "doing", "3", "alice"), After: item("a", "Login", "finished", "3", "alice")},
+		{Before: item("b", "Signup", "doing", "2", "alice"), After: item("b", "Signup", "finished", "5", "alice")},
+		{After: item("c", "Reset", "planned", "", "")},
+		{Before: item("d", "Profile", "planned", "1", ""), After: item("d", "Profile", "planned", "1", "bob")},
+		{Before: item("e", "Logout", "planned", "", ""), After: item("e", "Log out", "planned", "", "")},
+		{Before: item("f", "Unused", "planned", "", "")},
+		{Before: item("g", "Same", "planned", "", ""), After: item("g", "Same", "planned", "", "")},
+	}
+	subject, body := backlog.CommitSummary("alice", changes)
+	assert.Equal(t, "alice: 1 new item 'Reset', 2 items → finished, 'Profile' assigned to bob, estimate changed on 'Signup', 'Unused' deleted, 'Log out' edited", subject)
+	assert.Equal(t, []string{
+		"- Login: doing → finished",
+		"- Signup: doing → finished, estimate 2 → 5",
+		"- Reset: new, planned",
+		"- Profile: assigned to bob",
+		"- Log out: renamed from 'Logout'",
+		"- Unused: deleted",
+	}, body)
+
+	subject, body = backlog.CommitSummary("alice", nil)
+	assert.Equal(t, "sync", subject)
+	assert.Nil(t, body)
+}
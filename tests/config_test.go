@@ -0,0 +1,20 @@
+package tests
+
+import (
+	"github.com/mreider/agilemarkdown/config"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestRemoteGitUrl(t *testing.T) {
+	remoteUrl := "https://github.com/mreider/backlog"
+
+	cfg := &config.Config{RemoteGitUrlFormat: "%s/blob/%s/%s"}
+	assert.Equal(t, "https://github.com/mreider/backlog/blob/main/web/login.md", cfg.RemoteGitUrl(remoteUrl, "main", "web/login.md"))
+
+	cfg = &config.Config{RemoteGitUrlFormat: "%s/blob/master/%s"}
+	assert.Equal(t, "https://github.com/mreider/backlog/blob/master/web/login.md", cfg.RemoteGitUrl(remoteUrl, "main", "web/login.md"))
+
+	cfg = &config.Config{}
+	assert.Equal(t, "https://github.com/mreider/backlog/web/login.md", cfg.RemoteGitUrl(remoteUrl, "main", "web/login.md"))
+}
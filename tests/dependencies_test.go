@@ -0,0 +1,75 @@
+package tests
+
+import (
+	"github.com/mreider/agilemarkdown/backlog"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDependencyGraph(t *testing.T) {
+	rootDir, err := ioutil.TempDir("", "deps")
+	assert.Nil(t, err)
+	defer os.RemoveAll(rootDir)
+
+	files := map[string]string{
+		"web/login.md":    "# Login\n\nStatus: doing\nBlockedBy: api, web/design\n",
+		"web/design.md":   "# Design\n\nStatus: finished\n",
+		"web/api.md":      "# Web API\n\nStatus: planned\n",
+		"server/api.md":   "# Server API\n\nStatus: doing\nBlocks: ../web/profile.md, unknown\n",
+		"web/profile.md":  "# Profile\n\nStatus: planned\nBlocks: web/settings\n",
+		"web/settings.md": "# Settings\n\nStatus: planned\nBlocks: profile\n",
+	}
+	var items []*backlog.BacklogItem
+	for name, data := range files {
+		itemPath := filepath.Join(rootDir, filepath.FromSlash(name))
+		assert.Nil(t, os.MkdirAll(filepath.Dir(itemPath), 0755))
+		assert.Nil(t, ioutil.WriteFile(itemPath, []byte(data), 0644))
+	}
+	for name := range files {
+		item, err := backlog.LoadBacklogItem(filepath.Join(rootDir, filepath.FromSlash(name)))
+		assert.Nil(t, err)
+		items = append(items, item)
+	}
+	itemByID := func(deps *backlog.DependencyGraph, id string) *backlog.BacklogItem {
+		for _, item := range items {
+			if deps.ItemID(item) == id {
+				return item
+			}
+		}
+		return nil
+	}
+
+	deps := backlog.NewDependencyGraph(rootDir, items)
+	login := itemByID(deps, "web/login")
+	blockers := deps.BlockedBy(login)
+	assert.Equal(t, 2, len(blockers))
+	assert.Equal(t, "Web API", blockers[0].Title())
+	assert.Equal(t, "Design", blockers[1].Title())
+	assert.True(t, deps.IsBlocked(login))
+	assert.False(t, deps.IsBlocked(itemByID(deps, "web/design")))
+
+	profile := itemByID(deps, "web/profile")
+	assert.Equal(t, 2, len(deps.BlockedBy(profile)))
+	assert.Equal(t, 1, len(deps.Blocks(itemByID(deps, "server/api"))))
+
+	missing := deps.Missing()
+	assert.Equal(t, 1, len(missing))
+	assert.Equal(t, "unknown", missing[0].Ref)
+	assert.Equal(t, "server/api", deps.ItemID(missing[0].Item))
+
+	cycles := deps.Cycles()
+	assert.Equal(t, 1, len(cycles))
+	assert.Equal(t, 2, len(cycles[0]))
+
+	reloaded, err := backlog.LoadBacklogItem(login.Path())
+	assert.Nil(t, err)
+	assert.True(t, deps.IsBlocked(reloaded))
+
+	dot := deps.Dot()
+	assert.Equal(t, "digraph dependencies {", dot[0])
+	assert.Contains(t, dot, `  "web/design" -> "web/login";`)
+	assert.Equal(t, "}", dot[len(dot)-1])
+}
@@ -0,0 +1,73 @@
+package tests
+
+import (
+	"github.com/mreider/agilemarkdown/backlog"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestEpics(t *testing.T) {
+	rootDir, err := ioutil.TempDir("", "epics")
+	assert.Nil(t, err)
+	defer os.RemoveAll(rootDir)
+
+	files := []struct{ name, data string }{
+		{"web/checkout.md", "# Checkout\n\nStatus: doing\nType: epic\n\nThe checkout flow.\n\n## Comments\n\n@bob please review\n"},
+		{"web/cart.md", "# Cart\n\nStatus: finished\nEstimate: 3\nEpic: checkout\n"},
+		{"web/payment.md", "# Payment\n\nStatus: doing\nEstimate: 5\nEpic: checkout\n"},
+		{"server/billing.md", "# Billing\n\nStatus: planned\nEstimate: 2\nEpic: ../web/checkout.md\n"},
+		{"server/reports.md", "# Reports\n\nStatus: planned\nEpic: analytics\n"},
+		{"server/search.md", "# Search\n\nStatus: planned\nType: epic\n"},
+	}
+	var items []*backlog.BacklogItem
+	for _, file := range files {
+		itemPath := filepath.Join(rootDir, filepath.FromSlash(file.name))
+		assert.Nil(t, os.MkdirAll(filepath.Dir(itemPath), 0755))
+		assert.Nil(t, ioutil.WriteFile(itemPath, []byte(file.data), 0644))
+		item, err := backlog.LoadBacklogItem(itemPath)
+		assert.Nil(t, err)
+		items = append(items, item)
+	}
+
+	epics := backlog.NewEpicList(rootDir, items)
+	assert.Equal(t, 2, len(epics.Epics()))
+	assert.Nil(t, epics.Epic(items[1]))
+
+	checkout := epics.Epic(items[0])
+	assert.Equal(t, 3, len(checkout.Children))
+	assert.Equal(t, 1, checkout.FinishedCount())
+	assert.Equal(t, 10.0, checkout.TotalPoints())
+	assert.Equal(t, 3.0, checkout.FinishedPoints())
+	assert.Equal(t, 30.0, checkout.PercentComplete())
+	assert.Equal(t, map[string]int{"finished": 1, "doing": 1, "planned": 1}, checkout.CountByStatus())
+
+	search := epics.Epic(items[5])
+	assert.Equal(t, 0, len(search.Children))
+	assert.Equal(t, 0.0, search.PercentComplete())
+
+	assert.Equal(t, 1, len(epics.Missing()))
+	assert.Equal(t, "analytics", epics.Missing()[0].Ref)
+
+	lines := backlog.BacklogView{}.WriteMarkdownEpicProgress(checkout, filepath.Dir(items[0].Path()))
+	assert.Equal(t, "1 of 3 stories, 3 of 10 points finished, 30% complete", lines[0])
+	assert.Contains(t, lines, "| [Billing](../server/billing.md) | planned | 2 |")
+
+	items[0].UpdateEpicProgress(lines)
+	data, err := ioutil.ReadFile(items[0].Path())
+	assert.Nil(t, err)
+	content := string(data)
+	assert.True(t, strings.Index(content, "The checkout flow.") < strings.Index(content, "## Epic progress"))
+	assert.True(t, strings.Index(content, "## Epic progress") < strings.Index(content, "## Comments"))
+
+	reloaded, err := backlog.LoadBacklogItem(items[0].Path())
+	assert.Nil(t, err)
+	reloaded.UpdateEpicProgress(lines)
+	data, err = ioutil.ReadFile(items[0].Path())
+	assert.Nil(t, err)
+	assert.Equal(t, content, string(data))
+	assert.Equal(t, 1, len(reloaded.Comments()))
+}
@@ -0,0 +1,118 @@
+package tests
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"github.com/mreider/agilemarkdown/backlog"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestExportImportRoundTrip(t *testing.T) {
+	rootDir, err := ioutil.TempDir("", "export")
+	assert.Nil(t, err)
+	defer os.RemoveAll(rootDir)
+
+	sourceDir := filepath.Join(rootDir, "source")
+	assert.Nil(t, os.MkdirAll(filepath.Join(sourceDir, backlog.ArchiveDirectoryName), 0755))
+	assert.Nil(t, ioutil.WriteFile(filepath.Join(sourceDir, backlog.FieldsFileName), []byte(fieldsData), 0644))
+	itemData := "# Login page\n\nCreated: 2018-05-01 10:00 AM\nModified: 2018-05-02 11:00 AM\nAuthor: alice\nStatus: doing\nAssigned: bob\nEstimate: 3\nTags: ui api\nSprint: sprint-1\nEpic: auth\nPriority: high\nCustomer: ACME, Inc.\n\nThe login form, with \"quotes\".\n\n## Comments\n\n@alice what about passwords?\n\n@bob we use OAuth\n"
+	assert.Nil(t, ioutil.WriteFile(filepath.Join(sourceDir, "login.md"), []byte(itemData), 0644))
+	itemData = "# Old signup\n\nCreated: 2018-04-01 09:00 AM\nStatus: finished\nArchive: true\nBlocks: login\n\nNot needed anymore.\n"
+	assert.Nil(t, ioutil.WriteFile(filepath.Join(sourceDir, backlog.ArchiveDirectoryName, "old-signup.md"), []byte(itemData), 0644))
+
+	source, err := backlog.LoadBacklog(sourceDir)
+	assert.Nil(t, err)
+	exporter := backlog.NewItemsExporter(source.Fields())
+	csvPath := filepath.Join(rootDir, "export.csv")
+	csvData := bytes.NewBuffer(nil)
+	assert.Nil(t, exporter.Export(csvData, backlog.ExportFormatCSV, source.AllItems()))
+	assert.Nil(t, ioutil.WriteFile(csvPath, csvData.Bytes(), 0644))
+
+	targetDir := filepath.Join(rootDir, "target")
+	assert.Nil(t, os.MkdirAll(targetDir, 0755))
+	assert.Nil(t, ioutil.WriteFile(filepath.Join(targetDir, backlog.FieldsFileName), []byte(fieldsData), 0644))
+	_, err = backlog.NewItemsImport(targetDir, nil).Import(&backlog.PivotalImporter{}, csvPath)
+	assert.Nil(t, err)
+	_, err = os.Stat(filepath.Join(targetDir, backlog.ArchiveDirectoryName, "old-signup.md"))
+	assert.Nil(t, err)
+
+	target, err := backlog.LoadBacklog(targetDir)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(target.AllItems()))
+	for _, sourceItem := range source.AllItems() {
+		var targetItem *backlog.BacklogItem
+		for _, item := range target.AllItems() {
+			if item.Name() == sourceItem.Name() {
+				targetItem = item
+			}
+		}
+		if !assert.NotNil(t, targetItem, sourceItem.Name()) {
+			continue
+		}
+		assert.Equal(t, sourceItem.Title(), targetItem.Title())
+		assert.Equal(t, sourceItem.Status(), targetItem.Status())
+		assert.Equal(t, sourceItem.Tags(), targetItem.Tags())
+		assert.Equal(t, sourceItem.Estimate(), targetItem.Estimate())
+		assert.Equal(t, sourceItem.Author(), targetItem.Author())
+		assert.Equal(t, sourceItem.Assigned(), targetItem.Assigned())
+		assert.Equal(t, sourceItem.FieldValue(backlog.CreatedMetadataKey), targetItem.FieldValue(backlog.CreatedMetadataKey))
+		if modified := sourceItem.FieldValue(backlog.ModifiedMetadataKey); modified != "" {
+			assert.Equal(t, modified, targetItem.FieldValue(backlog.ModifiedMetadataKey))
+		}
+		assert.Equal(t, sourceItem.Description(), targetItem.Description())
+		assert.Equal(t, sourceItem.Comments(), targetItem.Comments())
+		assert.Equal(t, sourceItem.Archived(), targetItem.Archived())
+		assert.Equal(t, sourceItem.Sprint(), targetItem.Sprint())
+		assert.Equal(t, sourceItem.Epic(), targetItem.Epic())
+		assert.Equal(t, sourceItem.Blocks(), targetItem.Blocks())
+		for _, field := range source.Fields() {
+			assert.Equal(t, sourceItem.FieldValue(field.Name), targetItem.FieldValue(field.Name))
+		}
+	}
+
+	assert.Equal(t, "2018-05-02 11:00 AM", target.ActiveItems()[0].FieldValue(backlog.ModifiedMetadataKey))
+
+	otherDir := filepath.Join(rootDir, "other")
+	assert.Nil(t, os.MkdirAll(otherDir, 0755))
+	assert.Nil(t, ioutil.WriteFile(filepath.Join(otherDir, "other.md"), []byte("# Other\n\nStatus: planned\n"), 0644))
+	other, err := backlog.LoadBacklog(otherDir)
+	assert.Nil(t, err)
+	csvData.Reset()
+	assert.Nil(t, exporter.Export(csvData, backlog.ExportFormatCSV, append(source.AllItems(), other.AllItems()...)))
+	assert.Nil(t, ioutil.WriteFile(csvPath, csvData.Bytes(), 0644))
+	mixedDir := filepath.Join(rootDir, "mixed")
+	assert.Nil(t, os.MkdirAll(mixedDir, 0755))
+	count, err := backlog.NewItemsImport(mixedDir, nil).Import(&backlog.PivotalImporter{}, csvPath)
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "several backlogs (source, other)")
+	assert.Equal(t, 0, count)
+
+	jsonData := bytes.NewBuffer(nil)
+	assert.Nil(t, exporter.Export(jsonData, backlog.ExportFormatJSON, source.ActiveItems()))
+	var objects []map[string]string
+	assert.Nil(t, json.Unmarshal(jsonData.Bytes(), &objects))
+	assert.Equal(t, 1, len(objects))
+	assert.Equal(t, "Login page", objects[0]["Title"])
+	assert.Equal(t, "started", objects[0]["Current State"])
+	assert.Equal(t, "ui, api", objects[0]["Labels"])
+	assert.Equal(t, "ACME, Inc.", objects[0]["Customer"])
+	assert.Equal(t, "The login form, with \"quotes\".", objects[0]["Description"])
+	assert.Equal(t, "@alice what about passwords?\n\n@bob we use OAuth", objects[0]["Comments"])
+
+	xlsxData := bytes.NewBuffer(nil)
+	assert.Nil(t, exporter.Export(xlsxData, backlog.ExportFormatXLSX, source.AllItems()))
+	archive, err := zip.NewReader(bytes.NewReader(xlsxData.Bytes()), int64(xlsxData.Len()))
+	assert.Nil(t, err)
+	var names []string
+	for _, file := range archive.File {
+		names = append(names, file.Name)
+	}
+	assert.Contains(t, names, "xl/worksheets/sheet1.xml")
+
+	assert.NotNil(t, exporter.Export(ioutil.Discard, "pdf", source.AllItems()))
+}
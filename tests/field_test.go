@@ -0,0 +1,84 @@
+package tests
+
+import (
+	"github.com/mreider/agilemarkdown/backlog"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+const fieldsData = `[
+  {"Name": "Priority", "Type": "enum", "Values": ["low", "medium", "high"], "Column": true},
+  {"Name": "Due", "Type": "date"},
+  {"Name": "Cost", "Type": "number"},
+  {"Name": "Reviewer", "Type": "user"},
+  {"Name": "Customer"}
+]`
+
+func TestBacklogFields(t *testing.T) {
+	backlogDir, err := ioutil.TempDir("", "fields")
+	assert.Nil(t, err)
+	defer os.RemoveAll(backlogDir)
+
+	assert.Nil(t, ioutil.WriteFile(filepath.Join(backlogDir, backlog.FieldsFileName), []byte(fieldsData), 0644))
+	itemData := "# Item 1\n\nStatus: doing\nPriority: High\nDue: 2018-05-10\nCost: 2.5\nReviewer: bob\nCustomer: ACME\n"
+	assert.Nil(t, ioutil.WriteFile(filepath.Join(backlogDir, "item1.md"), []byte(itemData), 0644))
+	itemData = "# Item 2\n\nStatus: doing\nPriority: urgent\nDue: tomorrow\n"
+	assert.Nil(t, ioutil.WriteFile(filepath.Join(backlogDir, "item2.md"), []byte(itemData), 0644))
+
+	bck, err := backlog.LoadBacklog(backlogDir)
+	assert.Nil(t, err)
+	assert.Equal(t, 5, len(bck.Fields()))
+	assert.Equal(t, "string", backlog.FieldByName(bck.Fields(), "customer").Type)
+
+	item1, item2 := bck.AllItems()[0], bck.AllItems()[1]
+	priority, err := item1.Field("Priority")
+	assert.Nil(t, err)
+	assert.Equal(t, "high", priority)
+	due, ok := item1.FieldDate("Due")
+	assert.True(t, ok)
+	assert.Equal(t, time.Date(2018, 5, 10, 0, 0, 0, 0, time.UTC), due)
+	cost, ok := item1.FieldNumber("Cost")
+	assert.True(t, ok)
+	assert.Equal(t, 2.5, cost)
+
+	item2.SetFieldValue("Cost", "3")
+	assert.Equal(t, "3", item2.FieldValue("Cost"))
+
+	isKnownUser := func(user string) bool { return user == "bob" }
+	for _, field := range item1.Fields() {
+		assert.Nil(t, field.Validate(item1.FieldValue(field.Name), isKnownUser))
+	}
+	assert.NotNil(t, backlog.FieldByName(bck.Fields(), "Priority").Validate(item2.FieldValue("Priority"), isKnownUser))
+	assert.NotNil(t, backlog.FieldByName(bck.Fields(), "Due").Validate(item2.FieldValue("Due"), isKnownUser))
+	assert.NotNil(t, backlog.FieldByName(bck.Fields(), "Reviewer").Validate("alice", isKnownUser))
+
+	filter := backlog.NewBacklogItemsFieldFilter(backlog.FieldByName(bck.Fields(), "Priority"), "HIGH")
+	assert.Equal(t, []*backlog.BacklogItem{item1}, bck.FilteredActiveItems(filter))
+
+	lines := backlog.BacklogView{}.WriteMarkdownItems([]*backlog.BacklogItem{item1}, backlogDir, backlogDir, nil, backlog.ColumnFields(bck.Fields())...)
+	assert.Equal(t, "| User | Title | Points | Tags | Priority |", lines[0])
+	assert.Equal(t, "|---|---|:---:|---|---|", lines[1])
+	assert.Contains(t, lines[2], "| High |")
+}
+
+func TestInvalidBacklogFields(t *testing.T) {
+	backlogDir, err := ioutil.TempDir("", "fields")
+	assert.Nil(t, err)
+	defer os.RemoveAll(backlogDir)
+
+	for _, data := range []string{
+		`[{"Name": "Status"}]`,
+		`[{"Name": "Priority", "Type": "enum"}]`,
+		`[{"Name": "Due", "Type": "time"}]`,
+		`[{"Name": "Due"}, {"Name": "due"}]`,
+		`{"Name": "Due"}`,
+	} {
+		assert.Nil(t, ioutil.WriteFile(filepath.Join(backlogDir, backlog.FieldsFileName), []byte(data), 0644))
+		_, err := backlog.LoadBacklogFields(backlogDir)
+		assert.NotNil(t, err, data)
+	}
+}
@@ -0,0 +1,37 @@
+package tests
+
+import (
+	"github.com/mreider/agilemarkdown/backlog"
+	"github.com/stretchr/testify/assert"
+	"math/rand"
+	"testing"
+	"time"
+)
+
+func TestForecast(t *testing.T) {
+	items := []*backlog.BacklogItem{
+		createBacklogItem("item1", "Item 1", "planned", "3", ""),
+		createBacklogItem("item2", "Item 2", "planned", "3", ""),
+		createBacklogItem("item3", "Item 3", "planned", "3", ""),
+		createBacklogItem("item4", "Item 4", "planned", "10", ""),
+	}
+
+	forecast := backlog.Forecast(items, []float64{5, 5, 5}, 100, rand.New(rand.NewSource(1)))
+	assert.Equal(t, 4, len(forecast))
+	expectedWeeks := []int{1, 2, 2, 4}
+	for i, week := range expectedWeeks {
+		assert.Equal(t, items[i], forecast[i].Item)
+		assert.Equal(t, week, forecast[i].Week50)
+		assert.Equal(t, week, forecast[i].Week85)
+	}
+
+	forecast = backlog.Forecast(items, []float64{0, 10}, 1000, rand.New(rand.NewSource(1)))
+	assert.True(t, forecast[3].Week50 <= forecast[3].Week85)
+	assert.True(t, forecast[0].Week50 >= 1)
+
+	assert.Nil(t, backlog.Forecast(items, []float64{0, 0}, 100, rand.New(rand.NewSource(1))))
+
+	now := time.Date(2018, 5, 10, 12, 0, 0, 0, time.Local)
+	assert.Equal(t, time.Date(2018, 5, 7, 0, 0, 0, 0, time.Local), backlog.ForecastWeekStart(now, 1))
+	assert.Equal(t, time.Date(2018, 5, 21, 0, 0, 0, 0, time.Local), backlog.ForecastWeekStart(now, 3))
+}
@@ -0,0 +1,94 @@
+package tests
+
+import (
+	"github.com/mreider/agilemarkdown/backlog"
+	"github.com/mreider/agilemarkdown/git"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestBurnChart(t *testing.T) {
+	day := func(n int) time.Time {
+		return time.Date(2018, 5, n, 12, 0, 0, 0, time.Local)
+	}
+	changes := []*git.FileChange{
+		{Date: day(1), Path: "backlog/item1.md", Added: []string{"Status: doing", "Estimate: 3", "Sprint: sprint-1"}},
+		{Date: day(1), Path: "backlog/item2.md", Added: []string{"Status: planned", "Estimate: 5", "Sprint: sprint-1"}},
+		{Date: day(2), Path: "backlog/item3.md", Added: []string{"Status: planned", "Estimate: 8"}},
+		{Date: day(3), Path: "backlog/item1.md", Removed: []string{"Status: doing"}, Added: []string{"Status: finished"}},
+		{Date: day(4), Path: "backlog/item4.md", OldPath: "backlog/item2.md", Removed: []string{"Estimate: 5"}, Added: []string{"Estimate: 2"}},
+		{Date: day(5), Path: "backlog/item4.md", Deleted: true},
+		{Date: day(5), Path: "backlog/overview.md", Added: []string{"Status: finished", "Estimate: 100"}},
+	}
+	history := backlog.NewBacklogHistory("/repo", changes, nil, day(6))
+
+	days := history.BurnChart(day(1), day(5), "Sprint-1")
+	assert.Equal(t, 5, len(days))
+	expected := []struct{ scope, done float64 }{{8, 0}, {8, 0}, {8, 3}, {5, 3}, {3, 3}}
+	for i, expectedDay := range expected {
+		assert.Equal(t, expectedDay.scope, days[i].Scope, "day %d", i+1)
+		assert.Equal(t, expectedDay.done, days[i].Done, "day %d", i+1)
+	}
+
+	days = history.BurnChart(day(1), day(3), "")
+	assert.Equal(t, 16.0, days[2].Scope)
+	assert.Equal(t, 3.0, days[2].Done)
+}
+
+func TestFinishedDate(t *testing.T) {
+	day := func(n int) time.Time {
+		return time.Date(2018, 5, n, 12, 0, 0, 0, time.Local)
+	}
+	changes := []*git.FileChange{
+		{Date: day(1), Author: "alice", Path: "backlog/item1.md", Added: []string{"Status: planned", "Estimate: 3"}},
+		{Date: day(2), Author: "bob", Path: "backlog/item1.md", Removed: []string{"Status: planned"}, Added: []string{"Status: finished", "Assigned: bob"}},
+		{Date: day(3), Author: "bob", Path: "backlog/item1.md", Removed: []string{"Status: finished"}, Added: []string{"Status: doing"}},
+		{Date: day(4), Author: "bob", Path: "backlog/item1.md", Removed: []string{"Status: doing"}, Added: []string{"Status: finished"}},
+		{Date: day(6), Author: "alice", Path: "backlog/item1.md", Removed: []string{"Estimate: 3"}, Added: []string{"Estimate: 5"}},
+		{Date: day(1), Path: "backlog/item2.md", Added: []string{"Status: doing"}},
+	}
+	history := backlog.NewBacklogHistory("/repo", changes, nil, day(7))
+	assert.Equal(t, 2, len(history.Items()))
+
+	item1 := history.Items()[0]
+	finished, ok := item1.FinishedDate()
+	assert.True(t, ok)
+	assert.Equal(t, day(4), finished)
+
+	statusChanges := item1.Changes(backlog.BacklogItemStatusMetadataKey)
+	assert.Equal(t, 4, len(statusChanges))
+	assert.Equal(t, "finished", statusChanges[2].OldValue)
+	assert.Equal(t, "doing", statusChanges[2].NewValue)
+	assert.Equal(t, "bob", statusChanges[2].Author)
+
+	assignedChanges := item1.Changes(backlog.BacklogItemAssignedMetadataKey)
+	assert.Equal(t, 1, len(assignedChanges))
+	assert.Equal(t, day(2), assignedChanges[0].Date)
+
+	_, ok = history.Items()[1].FinishedDate()
+	assert.False(t, ok)
+}
+
+func TestItemHistoryIgnoresTextLines(t *testing.T) {
+	day := func(n int) time.Time {
+		return time.Date(2018, 5, n, 12, 0, 0, 0, time.Local)
+	}
+	changes := []*git.FileChange{
+		{Date: day(1), Author: "alice", Path: "backlog/item1.md", Content: []string{"# Item 1", "", "Status: planned", "Estimate: 3", "", "Text"}},
+		{Date: day(2), Author: "bob", Path: "backlog/item1.md", Content: []string{"# Item 1", "", "Status: planned", "Estimate: 3", "", "Text", "", "## Comments", "", "Status: finished", "Assigned: bob"}},
+		{Date: day(3), Author: "bob", Path: "backlog/item1.md", Content: []string{"---", "Status: doing", "Estimate: 3", "---", "# Item 1", "", "Text", "", "Status: finished"}},
+	}
+	history := backlog.NewBacklogHistory("/repo", changes, nil, day(7))
+	assert.Equal(t, 1, len(history.Items()))
+
+	item1 := history.Items()[0]
+	_, ok := item1.FinishedDate()
+	assert.False(t, ok)
+	statusChanges := item1.Changes(backlog.BacklogItemStatusMetadataKey)
+	assert.Equal(t, 2, len(statusChanges))
+	assert.Equal(t, "planned", statusChanges[1].OldValue)
+	assert.Equal(t, "doing", statusChanges[1].NewValue)
+	assert.Equal(t, day(3), statusChanges[1].Date)
+	assert.Equal(t, 0, len(item1.Changes(backlog.BacklogItemAssignedMetadataKey)))
+}
@@ -0,0 +1,184 @@
+package tests
+
+import (
+	"github.com/mreider/agilemarkdown/backlog"
+	"github.com/mreider/agilemarkdown/git"
+	"github.com/mreider/agilemarkdown/users"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const jiraCsvData = `Summary,Issue key,Issue Type,Status,Assignee,Reporter,Created,Labels,Labels,Description,Custom field (Story Points),Custom field (Epic Link),Comment,Comment
+Login page,WEB-2,Story,In Progress,Bob Smith,alice,01/May/18 10:00 AM,ui,api,"The login form.",3.0,WEB-1,01/May/18 11:00 AM;bob;What about passwords?,"02/May/18 9:00 AM;alice;# Not a header
+@carol too"
+`
+
+const jiraXMLData = `<rss version="0.92"><channel><item>
+<title>[WEB-3] Signup</title><key id="3">WEB-3</key><summary>Signup</summary>
+<type>Bug</type><status>Done</status><assignee accountid="x">Unassigned</assignee><reporter>alice</reporter>
+<created>Tue, 1 May 2018 10:00:00 +0000</created>
+<description>&lt;p&gt;Ask for a password &lt;b&gt;twice&lt;/b&gt;.&lt;/p&gt;</description>
+<labels><label>ui</label></labels>
+<comments><comment id="1" author="bob" created="Wed, 2 May 2018 10:00:00 +0000">&lt;p&gt;Done &amp;amp; tested&lt;/p&gt;</comment></comments>
+<customfields><customfield><customfieldname>Story Points</customfieldname><customfieldvalues><customfieldvalue>5.0</customfieldvalue></customfieldvalues></customfield></customfields>
+</item></channel></rss>`
+
+const trelloData = `{
+  "lists": [{"id": "l1", "name": "Backlog"}, {"id": "l2", "name": "Doing"}, {"id": "l3", "name": "Old", "closed": true}],
+  "members": [{"id": "m1", "fullName": "Bob Smith", "username": "bobs"}],
+  "cards": [
+    {"id": "5ae82f00aaaaaaaaaaaaaaaa", "name": "Card one", "desc": "First card", "idList": "l2", "idMembers": ["m1"], "labels": [{"name": "ui"}, {"name": "", "color": "red"}]},
+    {"id": "5ae82f00bbbbbbbbbbbbbbbb", "name": "Card two", "idList": "l3"}
+  ],
+  "actions": [
+    {"type": "commentCard", "date": "2018-05-02T10:00:00.000Z", "memberCreator": {"fullName": "Bob Smith"}, "data": {"text": "second", "card": {"id": "5ae82f00aaaaaaaaaaaaaaaa"}}},
+    {"type": "commentCard", "date": "2018-05-01T10:00:00.000Z", "memberCreator": {"fullName": "Bob Smith"}, "data": {"text": "first", "card": {"id": "5ae82f00aaaaaaaaaaaaaaaa"}}}
+  ]
+}`
+
+const gitHubData = `[
+  {"number": 7, "title": "Crash on start", "body": "Steps:\r\n1. start", "state": "OPEN", "createdAt": "2018-05-01T10:00:00Z",
+   "author": {"login": "alice"}, "assignees": [{"login": "bobs"}], "labels": [{"name": "bug"}],
+   "comments": [{"author": {"login": "bobs"}, "body": "Confirmed", "createdAt": "2018-05-02T10:00:00Z"}]},
+  {"number": 8, "title": "Old issue", "body": "", "state": "CLOSED", "author": {"login": "alice"}, "assignees": [], "labels": [], "comments": []}
+]`
+
+func TestImporters(t *testing.T) {
+	rootDir, err := ioutil.TempDir("", "import")
+	assert.Nil(t, err)
+	defer os.RemoveAll(rootDir)
+
+	usersDir := filepath.Join(rootDir, backlog.UsersDirectoryName)
+	assert.Nil(t, os.MkdirAll(usersDir, 0755))
+	assert.Nil(t, ioutil.WriteFile(filepath.Join(usersDir, "Bob Smith"), []byte("bob@example.com"), 0644))
+	assert.Nil(t, ioutil.WriteFile(filepath.Join(usersDir, "Alice Brown"), []byte("alice@example.com"), 0644))
+	userList := users.NewUserList(git.NewMemoryRepository(rootDir, "", ""), usersDir)
+
+	files := map[string]string{"jira.csv": jiraCsvData, "jira.xml": jiraXMLData, "board.json": trelloData, "issues.json": gitHubData}
+	for name, data := range files {
+		assert.Nil(t, ioutil.WriteFile(filepath.Join(rootDir, name), []byte(data), 0644))
+	}
+
+	importFile := func(name string, expectedImporter string, expectedCount int, configure func(imp *backlog.ItemsImport)) *backlog.Backlog {
+		backlogDir := filepath.Join(rootDir, name+"-backlog")
+		assert.Nil(t, os.MkdirAll(backlogDir, 0755))
+		importer, err := backlog.DetectImporter(filepath.Join(rootDir, name))
+		assert.Nil(t, err)
+		assert.Equal(t, expectedImporter, importer.Name())
+		itemsImport := backlog.NewItemsImport(backlogDir, userList)
+		if configure != nil {
+			configure(itemsImport)
+		}
+		count, err := itemsImport.Import(importer, filepath.Join(rootDir, name))
+		assert.Nil(t, err)
+		assert.Equal(t, expectedCount, count)
+		count, err = itemsImport.Import(importer, filepath.Join(rootDir, name))
+		assert.Nil(t, err)
+		assert.Equal(t, 0, count)
+		bck, err := backlog.LoadBacklog(backlogDir)
+		assert.Nil(t, err)
+		return bck
+	}
+	itemByName := func(bck *backlog.Backlog, name string) *backlog.BacklogItem {
+		for _, item := range bck.AllItems() {
+			if item.Name() == name {
+				return item
+			}
+		}
+		t.Fatalf("item %s isn't found", name)
+		return nil
+	}
+
+	bck := importFile("jira.csv", "jira", 1, func(imp *backlog.ItemsImport) {
+		imp.SetStatusMapping(map[string]string{"In Progress": "d"})
+		imp.SetUserMapping(map[string]string{"alice": "alice@example.com"})
+	})
+	item := itemByName(bck, "web-2")
+	assert.Equal(t, "Login page", item.Title())
+	assert.Equal(t, "doing", item.Status())
+	assert.Equal(t, "Bob Smith", item.Assigned())
+	assert.Equal(t, "Alice Brown", item.Author())
+	assert.Equal(t, "2018-05-01 10:00 AM", item.FieldValue(backlog.CreatedMetadataKey))
+	assert.Equal(t, []string{"ui", "api"}, item.Tags())
+	assert.Equal(t, "3", item.Estimate())
+	assert.Equal(t, "web-1", item.Epic())
+	assert.Equal(t, "story", item.Type())
+	comments := item.Comments()
+	assert.Equal(t, 2, len(comments))
+	assert.Equal(t, []string{"bob"}, comments[0].Users)
+	assert.True(t, comments[0].Closed)
+	assert.Equal(t, []string{"(2018-05-01 11:00 AM) What about passwords?"}, comments[0].Text)
+	assert.Equal(t, []string{"alice"}, comments[1].Users)
+	assert.Equal(t, []string{`(2018-05-02 09:00 AM) \# Not a header`, `\@carol too`}, comments[1].Text)
+
+	bck = importFile("jira.xml", "jira", 1, nil)
+	item = itemByName(bck, "web-3")
+	assert.Equal(t, "finished", item.Status())
+	assert.Equal(t, "", item.Assigned())
+	assert.Equal(t, "5", item.Estimate())
+	assert.Equal(t, "bug", item.Type())
+	assert.Equal(t, "Ask for a password twice.\n\n## Comments\n\n @bob (2018-05-02 10:00 AM) Done & tested\n", item.Description())
+
+	bck = importFile("board.json", "trello", 2, nil)
+	item = itemByName(bck, "card-one")
+	assert.Equal(t, "doing", item.Status())
+	assert.Equal(t, "Bob Smith", item.Assigned())
+	assert.Equal(t, []string{"ui", "red"}, item.Tags())
+	assert.Equal(t, "2018-05-01 09:10 AM", item.FieldValue(backlog.CreatedMetadataKey))
+	comments = item.Comments()
+	assert.Equal(t, 2, len(comments))
+	assert.Equal(t, []string{"bob"}, comments[0].Users)
+	assert.Equal(t, []string{"(2018-05-01 10:00 AM) first"}, comments[0].Text)
+	item = itemByName(bck, "card-two")
+	assert.True(t, item.Archived())
+	_, err = os.Stat(filepath.Join(rootDir, "board.json-backlog", backlog.ArchiveDirectoryName, "card-two.md"))
+	assert.Nil(t, err)
+
+	bck = importFile("issues.json", "github", 2, func(imp *backlog.ItemsImport) {
+		imp.SetUserMapping(map[string]string{"bobs": "Bob Smith"})
+	})
+	item = itemByName(bck, "issue-7")
+	assert.Equal(t, "unplanned", item.Status())
+	assert.Equal(t, "Bob Smith", item.Assigned())
+	assert.Equal(t, "Alice Brown", item.Author())
+	assert.Equal(t, []string{"bug"}, item.Tags())
+	assert.Equal(t, []string{"bob"}, item.Comments()[0].Users)
+	item = itemByName(bck, "issue-8")
+	assert.Equal(t, "finished", item.Status())
+}
+
+func TestImportRejectsInvalidNames(t *testing.T) {
+	rootDir, err := ioutil.TempDir("", "import-names")
+	assert.Nil(t, err)
+	defer os.RemoveAll(rootDir)
+
+	files := map[string]string{
+		"traversal.csv": "Name,Title\n../../outside,Outside\n",
+		"archive.csv":   "Name,Title\narchive,Archive\n",
+		"sprint.csv":    "Name,Title\n,Sprint\n",
+		"valid.csv":     "Name,Title\nLogin Page,Login\n",
+	}
+	for name, data := range files {
+		assert.Nil(t, ioutil.WriteFile(filepath.Join(rootDir, name), []byte(data), 0644))
+	}
+
+	backlogDir := filepath.Join(rootDir, "proj")
+	assert.Nil(t, os.MkdirAll(backlogDir, 0755))
+	itemsImport := backlog.NewItemsImport(backlogDir, nil)
+	for _, name := range []string{"traversal.csv", "archive.csv", "sprint.csv"} {
+		count, err := itemsImport.Import(&backlog.PivotalImporter{}, filepath.Join(rootDir, name))
+		assert.NotNil(t, err, name)
+		assert.Equal(t, 0, count)
+	}
+	_, err = os.Stat(filepath.Join(rootDir, "outside.md"))
+	assert.True(t, os.IsNotExist(err))
+
+	count, err := itemsImport.Import(&backlog.PivotalImporter{}, filepath.Join(rootDir, "valid.csv"))
+	assert.Nil(t, err)
+	assert.Equal(t, 1, count)
+	_, err = os.Stat(filepath.Join(backlogDir, "Login-Page.md"))
+	assert.Nil(t, err)
+}
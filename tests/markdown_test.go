@@ -32,6 +32,30 @@ Story 3 [link3](link3.md) (points) (assigned)
 Story 8 [link8](link8.md) (points) (assigned)  
 
 [Archived stories](archive.md)`
+
+	frontMatterData = `---
+Created: 2018-05-01 10:00 AM
+Modified: 2018-05-02 11:00 AM
+Tags:
+- ui
+- api
+Status: doing
+Estimate: 3
+Description: |-
+  first line
+  second line
+Links:
+  wiki: https://example.com/wiki
+  issue: 42
+layout: story
+---
+# Test story
+
+Project: test
+
+## Problem statement
+
+Text`
 )
 
 func TestMarkdownLoad(t *testing.T) {
@@ -99,3 +123,45 @@ footer2`
 
 	assert.Equal(t, updatedData, string(content.Content("")))
 }
+
+func TestMarkdownFrontMatter(t *testing.T) {
+	content := backlog.NewMarkdown(frontMatterData, "", []string{"Created", "Modified", "Tags", "Status", "Estimate", "Description", "Links"}, "", nil)
+	assert.True(t, content.FrontMatter())
+	assert.Equal(t, "Test story", content.Title())
+	assert.Equal(t, "Project: test", content.Header())
+	assert.Equal(t, "doing", content.MetadataValue("Status"))
+	assert.Equal(t, "3", content.MetadataValue("Estimate"))
+	assert.Equal(t, "ui api", content.MetadataValue("Tags"))
+	assert.Equal(t, "first line\nsecond line", content.MetadataValue("Description"))
+	assert.Equal(t, "story", content.MetadataValue("layout"))
+	assert.NotNil(t, content.MetadataData("Links"))
+
+	output := string(content.Content("2018-05-02 11:00 AM"))
+	assert.Equal(t, frontMatterData, output)
+
+	content.SetMetadataValue("Status", "finished")
+	content.SetMetadataValues("Tags", []string{"ui"})
+	output = string(content.Content("2018-05-03 11:00 AM"))
+	assert.Contains(t, output, "Status: finished\n")
+	assert.Contains(t, output, "Tags:\n- ui\n")
+	assert.Contains(t, output, "Modified: 2018-05-03 11:00 AM\n")
+
+	err := content.SetFrontMatter(false)
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "'Description' has several lines")
+	assert.True(t, content.FrontMatter())
+	content.SetMetadataValue("Description", "one line")
+	err = content.SetFrontMatter(false)
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "'Links' is a map")
+	content.SetMetadataValue("Links", "https://example.com/wiki")
+
+	assert.Nil(t, content.SetFrontMatter(false))
+	output = string(content.Content("2018-05-03 11:00 AM"))
+	legacy := backlog.NewMarkdown(output, "", []string{"Created", "Modified", "Tags", "Status", "Estimate"}, "", nil)
+	assert.False(t, legacy.FrontMatter())
+	assert.Equal(t, "Test story", legacy.Title())
+	assert.Equal(t, "finished", legacy.MetadataValue("Status"))
+	assert.Equal(t, "ui", legacy.MetadataValue("Tags"))
+	assert.Equal(t, "3", legacy.MetadataValue("Estimate"))
+}
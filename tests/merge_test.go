@@ -0,0 +1,163 @@
+package tests
+
+import (
+	"fmt"
+	"github.com/mreider/agilemarkdown/backlog"
+	"github.com/mreider/agilemarkdown/utils"
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+)
+
+func TestMerge3(t *testing.T) {
+	base := []string{"a", "b", "c", "d"}
+	merged, conflict := utils.Merge3(base, []string{"a", "B", "c", "d"}, []string{"a", "b", "c", "d", "e"})
+	assert.False(t, conflict)
+	assert.Equal(t, []string{"a", "B", "c", "d", "e"}, merged)
+
+	merged, conflict = utils.Merge3(base, []string{"a", "x", "c", "d"}, []string{"a", "y", "c", "d"})
+	assert.True(t, conflict)
+	assert.Equal(t, []string{"a", utils.MergeOursMarker, "x", utils.MergeSeparator, "y", utils.MergeTheirsMarker, "c", "d"}, merged)
+
+	merged, conflict = utils.Merge3(base, []string{"b", "c", "d"}, []string{"a", "b", "c"})
+	assert.False(t, conflict)
+	assert.Equal(t, []string{"b", "c"}, merged)
+}
+
+func TestMergeItemContent(t *testing.T) {
+	base := `# Login page
+
+Created: 2018-05-01 10:00 AM
+Modified: 2018-05-01 10:00 AM
+Status: planned
+Assigned:
+Estimate: 3
+Tags: ui api
+
+The login form.
+
+Remember the user.
+
+## Comments
+
+@bob what about passwords?
+`
+	ours := `# Login page
+
+Created: 2018-05-01 10:00 AM
+Modified: 2018-05-03 10:00 AM
+Status: doing
+Assigned: alice
+Estimate: 3
+Tags: ui api web
+
+The login form with OAuth.
+
+Remember the user.
+
+## Comments
+
+@bob what about passwords?
+sent by @alice at 2018-05-03 10:00 AM
+
+@carol please review
+`
+	theirs := `# Login page
+
+Created: 2018-05-01 10:00 AM
+Modified: 2018-05-02 10:00 AM
+Status: planned
+Assigned:
+Estimate: 5
+Tags: ui
+
+The login form.
+
+Remember the user for a month.
+
+## Comments
+
+@bob what about passwords?
+
+@alice use a cookie
+`
+	merged, conflicts := backlog.MergeItemContent(base, ours, theirs, nil)
+	assert.Equal(t, 0, len(conflicts))
+	item := backlog.NewBacklogItem("login", merged)
+	assert.Equal(t, "doing", item.Status())
+	assert.Equal(t, "alice", item.Assigned())
+	assert.Equal(t, "5", item.Estimate())
+	assert.Equal(t, []string{"ui", "web"}, item.Tags())
+	assert.Equal(t, "2018-05-03 10:00 AM", item.FieldValue(backlog.ModifiedMetadataKey))
+	assert.True(t, strings.HasPrefix(item.Description(), "The login form with OAuth.\n\nRemember the user for a month.\n"))
+	comments := item.Comments()
+	assert.Equal(t, 3, len(comments))
+	assert.True(t, comments[0].Closed)
+	assert.Equal(t, []string{"carol"}, comments[1].Users)
+	assert.Equal(t, []string{"alice"}, comments[2].Users)
+
+	theirs = strings.Replace(theirs, "Status: planned", "Status: finished", 1)
+	theirs = strings.Replace(theirs, "The login form.", "The signup form.", 1)
+	merged, conflicts = backlog.MergeItemContent(base, ours, theirs, nil)
+	assert.Equal(t, 2, len(conflicts))
+	assert.Equal(t, "Status: ours 'doing', theirs 'finished'", conflicts[0].String())
+	assert.Equal(t, "Description", conflicts[1].Field)
+	assert.Equal(t, "doing", backlog.NewBacklogItem("login", merged).Status())
+	assert.Contains(t, merged, utils.MergeOursMarker+"\nThe login form with OAuth.\n"+utils.MergeSeparator+"\nThe signup form.\n"+utils.MergeTheirsMarker)
+}
+
+func TestMergeItemDuplicateComments(t *testing.T) {
+	base := "# Login page\n\nStatus: doing\n\n## Comments\n\n@bob +1\n"
+	ours := "# Login page\n\nStatus: doing\n\n## Comments\n\n@bob +1\n\n@carol please review\n\n@bob +1\n"
+	theirs := "# Login page\n\nStatus: doing\n\n## Comments\n\n@bob +1\n\n@alice done\n\n@alice done\n"
+	merged, conflicts := backlog.MergeItemContent(base, ours, theirs, nil)
+	assert.Equal(t, 0, len(conflicts))
+	var users []string
+	for _, comment := range backlog.NewBacklogItem("login", merged).Comments() {
+		users = append(users, comment.Users...)
+	}
+	assert.Equal(t, []string{"bob", "carol", "bob", "alice", "alice"}, users)
+
+	theirs = "# Login page\n\nStatus: doing\n\n## Comments\n"
+	merged, _ = backlog.MergeItemContent(base, base+"\n@bob +1\n", theirs, nil)
+	comments := backlog.NewBacklogItem("login", merged).Comments()
+	assert.Equal(t, 1, len(comments))
+	assert.Equal(t, []string{"bob"}, comments[0].Users)
+}
+
+func TestMergeItemDeletedKeys(t *testing.T) {
+	base := "# Login page\n\nStatus: doing\nAssigned: bob\nEstimate: 3\nBlockedBy: signup\n\nThe login form.\n"
+	ours := "# Login page\n\nStatus: doing\nAssigned: bob\nEstimate: 5\nBlockedBy: signup\n\nThe login form.\n"
+	theirs := "# Login page\n\nStatus: doing\nEstimate: 3\n\nThe login form.\n"
+	merged, conflicts := backlog.MergeItemContent(base, ours, theirs, nil)
+	assert.Equal(t, 0, len(conflicts))
+	assert.NotContains(t, merged, "Assigned:")
+	assert.NotContains(t, merged, "BlockedBy:")
+	assert.Equal(t, "5", backlog.NewBacklogItem("login", merged).Estimate())
+
+	merged, conflicts = backlog.MergeItemContent(base, theirs, ours, nil)
+	assert.Equal(t, 0, len(conflicts))
+	assert.NotContains(t, merged, "Assigned:")
+
+	ours = strings.Replace(ours, "Assigned: bob", "Assigned: alice", 1)
+	merged, conflicts = backlog.MergeItemContent(base, ours, theirs, nil)
+	assert.Equal(t, 1, len(conflicts))
+	assert.Equal(t, "alice", backlog.NewBacklogItem("login", merged).Assigned())
+}
+
+func TestMergeOverviewContent(t *testing.T) {
+	overview := func(modified string, items ...string) string {
+		lines := []string{"# proj", "", "Created: 2018-05-01 10:00 AM  ", "Modified: " + modified + "  ", "",
+			"### Planned", "| User | Title | Points | Tags | Priority |", "|---|---|:---:|---|---|"}
+		for _, item := range items {
+			lines = append(lines, fmt.Sprintf("|  | [%s](proj/%s.md) |  |  |  |", strings.ToUpper(item), item))
+		}
+		return strings.Join(append(lines, ""), "\n")
+	}
+	base := overview("2018-05-01 10:00 AM", "a", "b", "c", "d")
+	ours := overview("2018-05-02 10:00 AM", "d", "a", "b", "c")
+	theirs := overview("2018-05-03 10:00 AM", "a", "b", "e", "c", "d")
+
+	merged := backlog.MergeOverviewContent(base, ours, theirs)
+	assert.Equal(t, overview("2018-05-03 10:00 AM", "d", "a", "b", "e", "c"), merged)
+}
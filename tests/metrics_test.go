@@ -0,0 +1,76 @@
+package tests
+
+import (
+	"github.com/mreider/agilemarkdown/backlog"
+	"github.com/mreider/agilemarkdown/git"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestTimeStats(t *testing.T) {
+	stats := backlog.NewTimeStats([]float64{4, 1, 3, 2, 10})
+	assert.Equal(t, 5, stats.Count)
+	assert.Equal(t, 4.0, stats.Average)
+	assert.Equal(t, 3.0, stats.Median)
+	assert.Equal(t, 10.0, stats.P85)
+
+	stats = backlog.NewTimeStats([]float64{1, 2, 3, 4})
+	assert.Equal(t, 2.5, stats.Median)
+	assert.Equal(t, 4.0, stats.P85)
+
+	assert.Equal(t, 0, backlog.NewTimeStats(nil).Count)
+}
+
+func TestItemsMetrics(t *testing.T) {
+	day := func(n int) time.Time {
+		return time.Date(2018, 5, n, 12, 0, 0, 0, time.Local)
+	}
+	rootDir, err := ioutil.TempDir("", "metrics")
+	assert.Nil(t, err)
+	defer os.RemoveAll(rootDir)
+	loadItem := func(name, content string) *backlog.BacklogItem {
+		itemPath := filepath.Join(rootDir, name+".md")
+		assert.Nil(t, ioutil.WriteFile(itemPath, []byte(content), 0644))
+		item, err := backlog.LoadBacklogItem(itemPath)
+		assert.Nil(t, err)
+		return item
+	}
+	item1 := loadItem("item1", "# Item 1\n\nStatus: finished\nAssigned: alice\nTags: ui\n")
+	item2 := loadItem("item2", "# Item 2\n\nStatus: finished\nAssigned: bob\nTags: ui api\n")
+	item3 := loadItem("item3", "# Item 3\n\nStatus: doing\nAssigned: bob\n")
+
+	changes := []*git.FileChange{
+		{Date: day(1), Path: "item1.md", Added: []string{"Status: planned"}},
+		{Date: day(3), Path: "item1.md", Removed: []string{"Status: planned"}, Added: []string{"Status: doing"}},
+		{Date: day(7), Path: "item1.md", Removed: []string{"Status: doing"}, Added: []string{"Status: finished"}},
+		{Date: day(2), Path: "item2.md", Added: []string{"Status: planned"}},
+		{Date: day(4), Path: "item2.md", Removed: []string{"Status: planned"}, Added: []string{"Status: finished"}},
+		{Date: day(2), Path: "item3.md", Added: []string{"Status: doing"}},
+	}
+	items := []*backlog.BacklogItem{item1, item2, item3}
+	history := backlog.NewBacklogHistory(rootDir, changes, items, day(10))
+
+	metrics := backlog.NewItemsMetrics(items, history)
+	assert.Equal(t, 2, len(metrics))
+	assert.Equal(t, 6.0, metrics[0].LeadTime())
+	assert.True(t, metrics[0].HasCycleTime())
+	assert.Equal(t, 4.0, metrics[0].CycleTime())
+	assert.Equal(t, 2.0, metrics[1].LeadTime())
+	assert.False(t, metrics[1].HasCycleTime())
+
+	row := backlog.NewMetricsRow("all", metrics)
+	assert.Equal(t, 2, row.Items)
+	assert.Equal(t, 4.0, row.LeadTime.Average)
+	assert.Equal(t, 1, row.CycleTime.Count)
+
+	tagRows := backlog.MetricsRowsByTag(metrics)
+	assert.Equal(t, 2, len(tagRows))
+	assert.Equal(t, "api", tagRows[0].Group)
+	assert.Equal(t, 1, tagRows[0].Items)
+	assert.Equal(t, "ui", tagRows[1].Group)
+	assert.Equal(t, 2, tagRows[1].Items)
+}
@@ -88,6 +88,25 @@ func TestOverviewUpdate(t *testing.T) {
 	assert.Equal(t, updatedOverviewData, updatedData)
 }
 
+func TestWriteAsciiItemsWithProject(t *testing.T) {
+	web := backlog.NewBacklogOverview(backlog.NewMarkdown("# Web site", "", nil, "### ", backlog.OverviewFooterRe))
+	api := backlog.NewBacklogOverview(backlog.NewMarkdown("# API", "", nil, "### ", backlog.OverviewFooterRe))
+	story1 := createBacklogItem("Story1", "Story 1", "planned", "3", "bob")
+	story2 := createBacklogItem("Story2", "Story Two", "planned", "", "alice")
+	overviews := map[*backlog.BacklogItem]*backlog.BacklogOverview{story1: web, story2: api}
+
+	lines := backlog.BacklogView{}.WriteAsciiItemsWithProject(overviews, []*backlog.BacklogItem{story1, story2}, "Status: planned", true)
+	assert.Equal(t, []string{
+		"Status: planned",
+		"----------------------------------------------------",
+		"   # | User  | Project  | Title     | Points | Tags ",
+		"----------------------------------------------------",
+		"   1 | bob   | Web site | Story 1   |      3 |      ",
+		"   2 | alice | API      | Story Two |        |      ",
+		"----------------------------------------------------",
+	}, lines)
+}
+
 func createBacklogItem(name, title, status, points, assigned string) *backlog.BacklogItem {
 	item := backlog.NewBacklogItem(name, "")
 	item.SetTitle(title)
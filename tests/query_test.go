@@ -0,0 +1,76 @@
+package tests
+
+import (
+	"github.com/mreider/agilemarkdown/backlog"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestItemsQuery(t *testing.T) {
+	backlogDir, err := ioutil.TempDir("", "query")
+	assert.Nil(t, err)
+	defer os.RemoveAll(backlogDir)
+
+	assert.Nil(t, ioutil.WriteFile(filepath.Join(backlogDir, backlog.FieldsFileName), []byte(fieldsData), 0644))
+	files := []struct{ name, data string }{
+		{"login.md", "# Login page\n\nStatus: doing\nAssigned: bob\nEstimate: 3\nTags: ui\nModified: 2024-02-10 10:00 AM\nPriority: high\nCost: 10\n"},
+		{"rest.md", "# REST endpoints\n\nStatus: doing\nAssigned: alice\nEstimate: 5\nTags: api backend\nModified: 2023-12-01 10:00 AM\nPriority: low\nDue: 2024-03-01\n"},
+		{"docs.md", "# Write docs\n\nStatus: planned\nAssigned: bob\nEstimate: 1\nTags: ui\nModified: 2024-01-01 09:00 AM\n"},
+		{"deploy.md", "# Deploy\n\nStatus: finished\nEstimate: 8\nModified: 2024-01-15 10:00 AM\nSprint: Sprint 1\n"},
+	}
+	var items []*backlog.BacklogItem
+	for _, file := range files {
+		assert.Nil(t, ioutil.WriteFile(filepath.Join(backlogDir, file.name), []byte(file.data), 0644))
+	}
+	fields, err := backlog.LoadBacklogFields(backlogDir)
+	assert.Nil(t, err)
+	for _, file := range files {
+		item, err := backlog.LoadBacklogItem(filepath.Join(backlogDir, file.name), fields...)
+		assert.Nil(t, err)
+		items = append(items, item)
+	}
+
+	match := func(query string) []string {
+		filter, err := backlog.ParseItemsQuery(query, fields, "Bob Smith", "bob@example.com", "bob")
+		assert.Nil(t, err, query)
+		if err != nil {
+			return nil
+		}
+		var names []string
+		for _, item := range items {
+			if filter.Match(item) {
+				names = append(names, item.Name())
+			}
+		}
+		return names
+	}
+
+	assert.Equal(t, []string{"login", "rest"}, match("status:doing AND (tag:ui OR tag:api) AND points>=3"))
+	assert.Equal(t, []string{"login"}, match("status:d AND tag:ui AND assigned:@me AND modified>2024-01-01"))
+	assert.Equal(t, []string{"login", "docs"}, match("assigned:@me"))
+	assert.Equal(t, []string{"rest", "deploy"}, match("NOT assigned:@me"))
+	assert.Equal(t, []string{"rest", "deploy"}, match("-assigned:bob"))
+	assert.Equal(t, []string{"docs"}, match("modified:2024-01-01"))
+	assert.Equal(t, []string{"login", "docs", "deploy"}, match("modified>=2024-01-01"))
+	assert.Equal(t, []string{"docs", "deploy"}, match("points<3 OR points>5"))
+	assert.Equal(t, []string{"deploy"}, match(`sprint:"Sprint 1"`))
+	assert.Equal(t, []string{"login"}, match("priority:high"))
+	assert.Equal(t, []string{"rest", "docs", "deploy"}, match("priority!=high"))
+	assert.Equal(t, []string{"login"}, match("cost>5"))
+	assert.Equal(t, []string{"rest"}, match("due<2024-04-01"))
+	assert.Equal(t, []string{"login", "docs"}, match("page OR docs"))
+	assert.Equal(t, []string{"rest"}, match(`title:"rest end"`))
+	assert.Equal(t, []string{"login", "rest", "docs", "deploy"}, match(""))
+
+	for _, query := range []string{"status:unknown", "points>many", "color:red", "(tag:ui", "tag:ui OR", "AND tag:ui", `title:"open`, "tag>ui", "archived:maybe"} {
+		_, err := backlog.ParseItemsQuery(query, fields)
+		assert.NotNil(t, err, query)
+	}
+
+	assert.True(t, backlog.QueryHasKey("status:doing AND archived:true", backlog.QueryArchivedKey))
+	assert.True(t, backlog.QueryHasKey("(tag:ui OR -Archived:false)", backlog.QueryArchivedKey))
+	assert.False(t, backlog.QueryHasKey(`title:"archived:true" OR archived`, backlog.QueryArchivedKey))
+}
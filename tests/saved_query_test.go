@@ -0,0 +1,79 @@
+package tests
+
+import (
+	"github.com/mreider/agilemarkdown/backlog"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSavedQueries(t *testing.T) {
+	rootDir, err := ioutil.TempDir("", "queries")
+	assert.Nil(t, err)
+	defer os.RemoveAll(rootDir)
+
+	queriesDir := filepath.Join(rootDir, backlog.QueriesDirectoryName)
+	assert.Nil(t, os.MkdirAll(queriesDir, 0755))
+	assert.Nil(t, ioutil.WriteFile(filepath.Join(queriesDir, "unassigned-bugs.md"), []byte("# All unassigned bugs\n\nQuery: tag:bug AND assigned:\"\"\n"), 0644))
+	assert.Nil(t, ioutil.WriteFile(filepath.Join(queriesDir, "bob-doing.md"), []byte("# Bob doing\n\nQuery: status:doing assigned:bob\n"), 0644))
+	assert.Nil(t, ioutil.WriteFile(filepath.Join(queriesDir, "my-doing.md"), []byte("# My doing\n\nQuery: status:doing assigned:@me\n"), 0644))
+	assert.Nil(t, ioutil.WriteFile(filepath.Join(queriesDir, "bob-work.md"), []byte("# Bob's work\n\nUser: bob\nQuery: status:doing assigned:@me\n"), 0644))
+
+	queries, err := backlog.LoadSavedQueries(queriesDir)
+	assert.Nil(t, err)
+	assert.Equal(t, 4, len(queries))
+	assert.Equal(t, "All unassigned bugs", queries[0].Title())
+	assert.Equal(t, "bob-doing", queries[1].Name())
+	assert.Equal(t, `tag:bug AND assigned:""`, queries[0].Query())
+
+	bugData := "# Crash\n\nStatus: planned\nTags: bug\n"
+	assignedBugData := "# Typo\n\nStatus: doing\nAssigned: bob\nTags: bug\n"
+	var items []*backlog.BacklogItem
+	for name, data := range map[string]string{"crash.md": bugData, "typo.md": assignedBugData} {
+		itemPath := filepath.Join(rootDir, name)
+		assert.Nil(t, ioutil.WriteFile(itemPath, []byte(data), 0644))
+		item, err := backlog.LoadBacklogItem(itemPath)
+		assert.Nil(t, err)
+		items = append(items, item)
+	}
+	filter, err := queries[0].Filter(nil)
+	assert.Nil(t, err)
+	matched := backlog.NewBacklog(items).FilteredActiveItems(filter)
+	assert.Equal(t, 1, len(matched))
+	assert.Equal(t, "Crash", matched[0].Title())
+
+	assert.Equal(t, "bob", queries[2].User())
+	filter, err = queries[2].Filter(nil)
+	assert.Nil(t, err)
+	matched = backlog.NewBacklog(items).FilteredActiveItems(filter)
+	assert.Equal(t, 1, len(matched))
+	assert.Equal(t, "Typo", matched[0].Title())
+
+	_, err = queries[3].Filter(nil)
+	assert.NotNil(t, err)
+
+	queries[1].UpdateLinks(rootDir)
+	queries[1].Update([]string{"", "## Doing", "| User | Project | Title | Points | Tags |"})
+	data, err := ioutil.ReadFile(queries[1].Path())
+	assert.Nil(t, err)
+	assert.Equal(t, "# Bob doing\n\n[home](../index.md) || [idea list](../ideas.md) || [tag list](../tags.md)\n\nQuery: status:doing assigned:bob  \n\n## Doing\n| User | Project | Title | Points | Tags |", string(data))
+
+	reloaded, err := backlog.LoadSavedQuery(queries[1].Path())
+	assert.Nil(t, err)
+	assert.Equal(t, "status:doing assigned:bob", reloaded.Query())
+
+	index, err := backlog.LoadGlobalIndex(filepath.Join(rootDir, backlog.IndexFileName))
+	assert.Nil(t, err)
+	index.UpdateSavedQueries(queries, rootDir)
+	data, err = ioutil.ReadFile(filepath.Join(rootDir, backlog.IndexFileName))
+	assert.Nil(t, err)
+	assert.True(t, strings.Contains(string(data), "## Queries\n[All unassigned bugs](queries/unassigned-bugs.md)  \n"))
+
+	index.UpdateSavedQueries(nil, rootDir)
+	data, err = ioutil.ReadFile(filepath.Join(rootDir, backlog.IndexFileName))
+	assert.Nil(t, err)
+	assert.False(t, strings.Contains(string(data), "Queries"))
+}
@@ -0,0 +1,89 @@
+package tests
+
+import (
+	"github.com/mreider/agilemarkdown/search"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestSearchIndex(t *testing.T) {
+	rootDir, err := ioutil.TempDir("", "search")
+	assert.Nil(t, err)
+	defer os.RemoveAll(rootDir)
+
+	files := []struct{ name, data string }{
+		{"web.md", "# Web\n"},
+		{"web/login.md", "# Login page\n\nStatus: doing\nTags: ui\n\nThe login form should remember the user.\n\n## Comments\n\n@bob what about passwords?\n"},
+		{"web/archive/signup.md", "# Signup\n\nStatus: finished\n\nAsk for a password twice on login.\n"},
+		{"web/sprint.md", "# Sprint: login\n"},
+		{"ideas/passkeys.md", "# Passkeys\n\nTags: security\n\nReplace the password login with passkeys.\n"},
+		{"notes/login.md", "# Login notes\n"},
+	}
+	for _, file := range files {
+		filePath := filepath.Join(rootDir, filepath.FromSlash(file.name))
+		assert.Nil(t, os.MkdirAll(filepath.Dir(filePath), 0755))
+		assert.Nil(t, ioutil.WriteFile(filePath, []byte(file.data), 0644))
+	}
+
+	index, err := search.LoadIndex(rootDir)
+	assert.Nil(t, err)
+	changed, err := index.Update()
+	assert.Nil(t, err)
+	assert.Equal(t, 3, changed)
+
+	results, err := index.Search("login", 0)
+	assert.Nil(t, err)
+	assert.Equal(t, 3, len(results))
+	assert.Equal(t, "web/login.md", results[0].Document.Path)
+	assert.Equal(t, "doing", results[0].Document.Status)
+	assert.Equal(t, "The login form should remember the user.", results[0].Snippet)
+
+	results, err = index.Search("comment:password*", 0)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(results))
+	assert.Equal(t, "what about passwords?", results[0].Snippet)
+
+	results, err = index.Search("password login", 1)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(results))
+	assert.Equal(t, "ideas/passkeys.md", results[0].Document.Path)
+	assert.Equal(t, search.IdeaKind, results[0].Document.Kind)
+
+	results, err = index.Search("title:password", 0)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(results))
+
+	_, err = index.Search("color:red", 0)
+	assert.NotNil(t, err)
+
+	assert.Nil(t, index.Save())
+	index, err = search.LoadIndex(rootDir)
+	assert.Nil(t, err)
+	changed, err = index.Update()
+	assert.Nil(t, err)
+	assert.Equal(t, 0, changed)
+
+	loginPath := filepath.Join(rootDir, "web", "login.md")
+	assert.Nil(t, ioutil.WriteFile(loginPath, []byte("# Login page\n\nStatus: finished\n\nUse OAuth.\n"), 0644))
+	modified := time.Now().Add(time.Minute)
+	assert.Nil(t, os.Chtimes(loginPath, modified, modified))
+	assert.Nil(t, os.Remove(filepath.Join(rootDir, "ideas", "passkeys.md")))
+	changed, err = index.Update()
+	assert.Nil(t, err)
+	assert.Equal(t, 2, changed)
+
+	results, err = index.Search("oauth", 0)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(results))
+	assert.Equal(t, "finished", results[0].Document.Status)
+	results, err = index.Search("passkeys", 0)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(results))
+	results, err = index.Search("remember", 0)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(results))
+}
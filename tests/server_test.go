@@ -0,0 +1,175 @@
+package tests
+
+import (
+	"github.com/mreider/agilemarkdown/backlog"
+	"github.com/mreider/agilemarkdown/git"
+	"github.com/mreider/agilemarkdown/server"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestServer(t *testing.T) {
+	rootDir, err := ioutil.TempDir("", "server")
+	assert.Nil(t, err)
+	defer os.RemoveAll(rootDir)
+
+	files := map[string]string{
+		"index.md":        "# Index\n\n| Backlog |\n|---|\n| [proj](proj.md) |\n",
+		"proj.md":         "# proj\n",
+		"proj/paint.md":   "# Paint\n\nStatus: planned\nEstimate: 3\n\nBuy **paint**.\n",
+		"proj/links.md":   "# Links\n\n[site](https://example.com/?a=1&b=2) [mail](mailto:bob@example.com) [paint](paint.md) [xss](javascript:alert(1)) [data](DATA:text/html,x) ![img](javascript:alert(1))\n",
+		".config.json":    "{}",
+		"proj/notes.json": "{}",
+	}
+	for name, data := range files {
+		filePath := filepath.Join(rootDir, filepath.FromSlash(name))
+		assert.Nil(t, os.MkdirAll(filepath.Dir(filePath), 0755))
+		assert.Nil(t, ioutil.WriteFile(filePath, []byte(data), 0644))
+	}
+	srv := server.NewServer(git.NewMemoryRepository(rootDir, "tester", "tester@example.com"), rootDir, false, "")
+
+	get := func(target string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		srv.ServeHTTP(w, httptest.NewRequest(http.MethodGet, target, nil))
+		return w
+	}
+	postFrom := func(origin, target string, form url.Values) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodPost, target, strings.NewReader(form.Encode()))
+		r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		r.Header.Set("Origin", origin)
+		srv.ServeHTTP(w, r)
+		return w
+	}
+	post := func(target string, form url.Values) *httptest.ResponseRecorder {
+		return postFrom("http://example.com", target, form)
+	}
+
+	w := get("/")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `<td><a href="proj.md">proj</a></td>`)
+	assert.NotContains(t, w.Body.String(), "?edit=1")
+
+	w = get("/proj/paint.md")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "<p>Buy <strong>paint</strong>.</p>")
+	assert.Contains(t, w.Body.String(), `href="/proj/paint.md?edit=1"`)
+
+	w = get("/proj/paint.md?edit=1")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `<option value="planned" selected>`)
+
+	w = get("/proj/links.md")
+	assert.Contains(t, w.Body.String(), `<a href="https://example.com/?a=1&amp;b=2">site</a>`)
+	assert.Contains(t, w.Body.String(), `<a href="mailto:bob@example.com">mail</a>`)
+	assert.Contains(t, w.Body.String(), `<a href="paint.md">paint</a>`)
+	assert.Contains(t, w.Body.String(), `<a href="#">xss</a>`)
+	assert.Contains(t, w.Body.String(), `<a href="#">data</a>`)
+	assert.Contains(t, w.Body.String(), `<img alt="img" src="#">`)
+	assert.NotContains(t, w.Body.String(), "javascript:")
+
+	assert.Equal(t, http.StatusNotFound, get("/.config.json").Code)
+	assert.Equal(t, http.StatusNotFound, get("/../etc/passwd").Code)
+	assert.Equal(t, http.StatusOK, get("/proj/notes.json").Code)
+	assert.Equal(t, http.StatusMethodNotAllowed, post("/proj.md", url.Values{}).Code)
+
+	form := url.Values{"Title": {"Paint the house"}, "Status": {"unknown"}, "Description": {"Buy paint."}}
+	w = post("/proj/paint.md", form)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "unknown status")
+
+	form.Set("Status", "doing")
+	assert.Equal(t, http.StatusForbidden, postFrom("http://evil.example.org", "/proj/paint.md", form).Code)
+	form.Set("Assigned", "bob")
+	form.Set("Tags", "outside paint")
+	form.Set("Description", "Buy paint.\r\nAnd brushes.")
+	w = post("/proj/paint.md", form)
+	assert.Equal(t, http.StatusSeeOther, w.Code)
+
+	item, err := backlog.LoadBacklogItem(filepath.Join(rootDir, "proj", "paint.md"))
+	assert.Nil(t, err)
+	assert.Equal(t, "Paint the house", item.Title())
+	assert.Equal(t, "doing", item.Status())
+	assert.Equal(t, "bob", item.Assigned())
+	assert.Equal(t, "", item.Estimate())
+	assert.Equal(t, []string{"outside", "paint"}, item.Tags())
+	assert.Equal(t, "Buy paint.\nAnd brushes.", item.Description())
+	data, err := ioutil.ReadFile(filepath.Join(rootDir, "proj", "paint.md"))
+	assert.Nil(t, err)
+	assert.Contains(t, string(data), "Estimate: ")
+	for _, key := range []string{"Sprint", "Type", "Epic", "BlockedBy", "Blocks"} {
+		assert.NotContains(t, string(data), key+":")
+	}
+}
+
+func TestServerBoard(t *testing.T) {
+	rootDir, err := ioutil.TempDir("", "board")
+	assert.Nil(t, err)
+	defer os.RemoveAll(rootDir)
+
+	overviewData := `# proj
+
+### Doing
+| User | Title | Points | Tags |
+|---|---|:---:|---|
+| bob | [A](proj/a.md) | 1 |  |
+| bob | [B](proj/b.md) | 2 |  |
+
+### Planned
+| User | Title | Points | Tags |
+|---|---|:---:|---|
+|  | [C](proj/c.md) | 3 |  |
+`
+	files := map[string]string{
+		"proj.md":   overviewData,
+		"proj/a.md": "# A\n\nStatus: doing\nAssigned: bob\nEstimate: 1\n",
+		"proj/b.md": "# B\n\nStatus: doing\nAssigned: bob\nEstimate: 2\n",
+		"proj/c.md": "# C\n\nStatus: planned\nEstimate: 3\n",
+	}
+	for name, data := range files {
+		filePath := filepath.Join(rootDir, filepath.FromSlash(name))
+		assert.Nil(t, os.MkdirAll(filepath.Dir(filePath), 0755))
+		assert.Nil(t, ioutil.WriteFile(filePath, []byte(data), 0644))
+	}
+	repo := git.NewMemoryRepository(rootDir, "tester", "tester@example.com")
+	srv := server.NewServer(repo, rootDir, true, "")
+
+	w := httptest.NewRecorder()
+	srv.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/proj.md?board=1", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	body := w.Body.String()
+	assert.True(t, strings.Index(body, `data-name="a"`) < strings.Index(body, `data-name="b"`))
+	assert.True(t, strings.Index(body, `data-status="planned"`) < strings.Index(body, `data-name="c"`))
+
+	move := func(item, status, index string) int {
+		form := url.Values{"item": {item}, "status": {status}, "index": {index}}
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodPost, "/proj.md?board=1", strings.NewReader(form.Encode()))
+		r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		srv.ServeHTTP(w, r)
+		return w.Code
+	}
+	assert.Equal(t, http.StatusNoContent, move("c", "doing", "1"))
+	assert.Equal(t, http.StatusNoContent, move("b", "doing", "0"))
+	assert.Equal(t, http.StatusNoContent, move("b", "doing", "0"))
+	assert.Equal(t, []string{"Move B to doing", "Move C to doing"}, repo.Log("master"))
+	assert.Equal(t, http.StatusBadRequest, move("d", "doing", "0"))
+	assert.Equal(t, http.StatusBadRequest, move("a", "unknown", "0"))
+
+	item, err := backlog.LoadBacklogItem(filepath.Join(rootDir, "proj", "c.md"))
+	assert.Nil(t, err)
+	assert.Equal(t, "doing", item.Status())
+
+	overview, err := backlog.LoadBacklogOverview(filepath.Join(rootDir, "proj.md"))
+	assert.Nil(t, err)
+	sorted := backlog.NewBacklogItemsSorter(overview).SortedItemsByStatus()
+	assert.Equal(t, []string{"b", "a", "c"}, sorted["doing"])
+	assert.Equal(t, 0, len(sorted["planned"]))
+}
@@ -0,0 +1,78 @@
+package tests
+
+import (
+	"github.com/mreider/agilemarkdown/backlog"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestCustomStatuses(t *testing.T) {
+	err := backlog.SetStatuses([]*backlog.BacklogItemStatus{
+		{Code: "d", Name: "Doing"},
+		{Code: "r", Name: "review"},
+		{Code: "b", Name: "blocked"},
+		{Code: "q", Name: "qa"},
+		{Code: "p", Name: "planned"},
+		{Code: "u", Name: "unplanned", Initial: true},
+		{Code: "f", Name: "finished", Done: true},
+	})
+	assert.Nil(t, err)
+	defer backlog.SetStatuses(nil)
+
+	assert.Equal(t, "doing", backlog.StatusByCode("d").Name)
+	assert.Equal(t, "review", backlog.StatusByCode("r").Name)
+	assert.Equal(t, 3, backlog.StatusIndex(backlog.StatusByName("qa")))
+	assert.Equal(t, "unplanned", backlog.InitialStatus().Name)
+	assert.Equal(t, 6, len(backlog.UnfinishedStatuses()))
+	assert.Equal(t, []*backlog.BacklogItemStatus{backlog.StatusByCode("f")}, backlog.DoneStatuses())
+	assert.False(t, backlog.IsValidStatusCode("x"))
+
+	markdown := backlog.NewMarkdown("# Test backlog", "", []string{"Title"}, "### ", backlog.OverviewFooterRe)
+	overview := backlog.NewBacklogOverview(markdown)
+	sorter := backlog.NewBacklogItemsSorter(overview)
+	overview.Update([]*backlog.BacklogItem{
+		createBacklogItem("Story1", "Story 1", "qa", "3", ""),
+		createBacklogItem("Story2", "Story 2", "review", "5", ""),
+	}, sorter)
+
+	expectedGroups := []string{"Doing", "Review", "Blocked", "Qa", "Planned", "Unplanned", "Finished"}
+	assert.Equal(t, len(expectedGroups), markdown.GroupCount())
+	for _, title := range expectedGroups {
+		assert.NotNil(t, markdown.Group(title), title)
+	}
+	assert.Equal(t, []string{"Story2"}, backlog.NewBacklogItemsSorter(overview).SortedItemsByStatus()["review"])
+}
+
+func TestInvalidStatuses(t *testing.T) {
+	defer backlog.SetStatuses(nil)
+
+	assert.NotNil(t, backlog.SetStatuses([]*backlog.BacklogItemStatus{{Code: "d", Name: "doing"}}))
+	assert.NotNil(t, backlog.SetStatuses([]*backlog.BacklogItemStatus{{Code: "d", Name: "doing"}, {Code: "d", Name: "done", Done: true}}))
+	assert.NotNil(t, backlog.SetStatuses([]*backlog.BacklogItemStatus{{Code: "a", Name: "accepted", Done: true}}))
+	assert.Equal(t, backlog.DefaultStatuses, backlog.AllStatuses)
+}
+
+func TestStatusTransitions(t *testing.T) {
+	err := backlog.SetStatuses([]*backlog.BacklogItemStatus{
+		{Code: "d", Name: "doing", From: []string{"p", "r"}},
+		{Code: "r", Name: "review", From: []string{"d"}},
+		{Code: "p", Name: "planned"},
+		{Code: "u", Name: "unplanned"},
+		{Code: "f", Name: "finished", Done: true, From: []string{"R"}},
+	})
+	assert.Nil(t, err)
+	defer backlog.SetStatuses(nil)
+
+	status := backlog.StatusByCode
+	assert.True(t, backlog.IsAllowedTransition(status("u"), status("p")))
+	assert.True(t, backlog.IsAllowedTransition(status("p"), status("d")))
+	assert.True(t, backlog.IsAllowedTransition(status("r"), status("f")))
+	assert.True(t, backlog.IsAllowedTransition(status("f"), status("f")))
+	assert.True(t, backlog.IsAllowedTransition(nil, status("f")))
+	assert.False(t, backlog.IsAllowedTransition(status("u"), status("f")))
+	assert.False(t, backlog.IsAllowedTransition(status("d"), status("f")))
+	assert.False(t, backlog.IsAllowedTransition(status("u"), status("d")))
+
+	err = backlog.SetStatuses([]*backlog.BacklogItemStatus{{Code: "f", Name: "finished", Done: true, From: []string{"x"}}})
+	assert.NotNil(t, err)
+}
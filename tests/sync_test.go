@@ -0,0 +1,211 @@
+package tests
+
+import (
+	"github.com/mreider/agilemarkdown/backlog"
+	"github.com/mreider/agilemarkdown/commands"
+	"github.com/mreider/agilemarkdown/git"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSyncWithMemoryRepository(t *testing.T) {
+	tempDir, err := ioutil.TempDir("", "sync")
+	assert.Nil(t, err)
+	defer os.RemoveAll(tempDir)
+
+	writeFiles := func(rootDir string, files map[string]string) {
+		for name, data := range files {
+			filePath := filepath.Join(rootDir, filepath.FromSlash(name))
+			assert.Nil(t, os.MkdirAll(filepath.Dir(filePath), 0755))
+			assert.Nil(t, ioutil.WriteFile(filePath, []byte(data), 0644))
+		}
+	}
+	loadItem := func(rootDir string) *backlog.BacklogItem {
+		item, err := backlog.LoadBacklogItem(filepath.Join(rootDir, "proj", "paint.md"))
+		assert.Nil(t, err)
+		return item
+	}
+
+	remote := git.NewMemoryRepository("", "", "")
+
+	aliceDir := filepath.Join(tempDir, "alice")
+	writeFiles(aliceDir, map[string]string{
+		"proj.md":       "# proj\n",
+		"proj/paint.md": "# Paint\n\nStatus: planned\nEstimate: 3\nAssigned:\n\nBuy paint.\n",
+		"users/alice":   "alice@example.com\n",
+	})
+	alice := git.NewMemoryRepository(aliceDir, "alice", "alice@example.com")
+	alice.SetBranch("main")
+	alice.AddRemote("origin", remote)
+	assert.Nil(t, commands.NewSyncAction(alice, aliceDir, "").Execute())
+	assert.Equal(t, "main", remote.CurrentBranch())
+	assert.Equal(t, "alice: 1 new item 'Paint'", strings.SplitN(remote.Log("main")[0], "\n", 2)[0])
+
+	bobDir := filepath.Join(tempDir, "bob")
+	bob, err := remote.Clone(bobDir, "bob", "bob@example.com")
+	assert.Nil(t, err)
+	assert.Equal(t, "3", loadItem(bobDir).Estimate())
+
+	aliceItem := loadItem(aliceDir)
+	aliceItem.SetStatus(backlog.DoingStatus)
+	assert.Nil(t, aliceItem.Save())
+	assert.Nil(t, commands.NewSyncAction(alice, aliceDir, "").Execute())
+	assert.Equal(t, "alice: 'Paint' → doing", strings.SplitN(remote.Log("main")[0], "\n", 2)[0])
+
+	bobItem := loadItem(bobDir)
+	bobItem.SetEstimate("5")
+	assert.Nil(t, bobItem.Save())
+	assert.Nil(t, commands.NewSyncAction(bob, bobDir, "").Execute())
+
+	merged := loadItem(bobDir)
+	assert.Equal(t, "doing", merged.Status())
+	assert.Equal(t, "5", merged.Estimate())
+	overview, err := ioutil.ReadFile(filepath.Join(bobDir, "proj.md"))
+	assert.Nil(t, err)
+	assert.Contains(t, string(overview), "### Doing")
+
+	assert.Nil(t, commands.NewSyncAction(alice, aliceDir, "").Execute())
+	assert.Equal(t, "5", loadItem(aliceDir).Estimate())
+	bobHead, _ := bob.Head()
+	aliceHead, _ := alice.Head()
+	assert.Equal(t, bobHead, aliceHead)
+}
+
+func TestSyncRejectsIllegalStatusChanges(t *testing.T) {
+	err := backlog.SetStatuses([]*backlog.BacklogItemStatus{
+		{Code: "d", Name: "doing"},
+		{Code: "p", Name: "planned"},
+		{Code: "u", Name: "unplanned"},
+		{Code: "f", Name: "finished", Done: true, From: []string{"d"}},
+	})
+	assert.Nil(t, err)
+	defer backlog.SetStatuses(nil)
+
+	rootDir, err := ioutil.TempDir("", "transitions")
+	assert.Nil(t, err)
+	defer os.RemoveAll(rootDir)
+
+	files := map[string]string{
+		"proj.md":       "# proj\n",
+		"proj/paint.md": "# Paint\n\nStatus: planned\n",
+	}
+	for name, data := range files {
+		filePath := filepath.Join(rootDir, filepath.FromSlash(name))
+		assert.Nil(t, os.MkdirAll(filepath.Dir(filePath), 0755))
+		assert.Nil(t, ioutil.WriteFile(filePath, []byte(data), 0644))
+	}
+	remote := git.NewMemoryRepository("", "", "")
+	repo := git.NewMemoryRepository(rootDir, "alice", "alice@example.com")
+	repo.AddRemote("origin", remote)
+	assert.Nil(t, commands.NewSyncAction(repo, rootDir, "").Execute())
+	assert.Equal(t, 1, len(remote.Log("master")))
+
+	item, err := backlog.LoadBacklogItem(filepath.Join(rootDir, "proj", "paint.md"))
+	assert.Nil(t, err)
+	item.SetStatus(backlog.StatusByCode("f"))
+	assert.Nil(t, item.Save())
+	err = commands.NewSyncAction(repo, rootDir, "").Execute()
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "nothing is committed or pushed")
+	assert.Equal(t, 1, len(repo.Log("master")))
+	assert.Equal(t, 1, len(remote.Log("master")))
+
+	item.SetStatus(backlog.StatusByCode("d"))
+	assert.Nil(t, item.Save())
+	assert.Nil(t, commands.NewSyncAction(repo, rootDir, "").Execute())
+	assert.Equal(t, 2, len(remote.Log("master")))
+}
+
+func TestSyncMergesIdeasByLines(t *testing.T) {
+	tempDir, err := ioutil.TempDir("", "sync-ideas")
+	assert.Nil(t, err)
+	defer os.RemoveAll(tempDir)
+
+	remote := git.NewMemoryRepository("", "", "")
+	aliceDir := filepath.Join(tempDir, "alice")
+	files := map[string]string{
+		"proj.md":        "# proj\n",
+		"proj/paint.md":  "# Paint\n\nStatus: planned\n",
+		"ideas/solar.md": "# Solar\n\nTags: roof\n\nCheaper energy.\n\nMaybe next year.\n",
+		"users/alice":    "alice@example.com\n",
+	}
+	for name, data := range files {
+		filePath := filepath.Join(aliceDir, filepath.FromSlash(name))
+		assert.Nil(t, os.MkdirAll(filepath.Dir(filePath), 0755))
+		assert.Nil(t, ioutil.WriteFile(filePath, []byte(data), 0644))
+	}
+	alice := git.NewMemoryRepository(aliceDir, "alice", "alice@example.com")
+	alice.AddRemote("origin", remote)
+	assert.Nil(t, commands.NewSyncAction(alice, aliceDir, "").Execute())
+	bobDir := filepath.Join(tempDir, "bob")
+	bob, err := remote.Clone(bobDir, "bob", "bob@example.com")
+	assert.Nil(t, err)
+
+	ideaPath := func(rootDir string) string {
+		return filepath.Join(rootDir, "ideas", "solar.md")
+	}
+	replaceInIdea := func(rootDir, old, new string) {
+		data, err := ioutil.ReadFile(ideaPath(rootDir))
+		assert.Nil(t, err)
+		assert.Contains(t, string(data), old)
+		assert.Nil(t, ioutil.WriteFile(ideaPath(rootDir), []byte(strings.Replace(string(data), old, new, 1)), 0644))
+	}
+	replaceInIdea(aliceDir, "Cheaper energy.", "Cheaper and cleaner energy.")
+	assert.Nil(t, commands.NewSyncAction(alice, aliceDir, "").Execute())
+	replaceInIdea(bobDir, "Maybe next year.", "Ask for quotes.")
+	assert.Nil(t, commands.NewSyncAction(bob, bobDir, "").Execute())
+	data, err := ioutil.ReadFile(ideaPath(bobDir))
+	assert.Nil(t, err)
+	assert.Contains(t, string(data), "Cheaper and cleaner energy.")
+	assert.Contains(t, string(data), "Ask for quotes.")
+
+	assert.Nil(t, commands.NewSyncAction(alice, aliceDir, "").Execute())
+	replaceInIdea(aliceDir, "Tags: roof", "Tags: roof solar")
+	assert.Nil(t, commands.NewSyncAction(alice, aliceDir, "").Execute())
+	replaceInIdea(bobDir, "Tags: roof", "Tags: garage")
+	err = commands.NewSyncAction(bob, bobDir, "").Execute()
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "can't merge")
+	data, err = ioutil.ReadFile(ideaPath(bobDir))
+	assert.Nil(t, err)
+	assert.Contains(t, string(data), "Tags: garage")
+	assert.NotContains(t, string(data), "<<<<<<<")
+}
+
+func TestSyncRenamesReservedItems(t *testing.T) {
+	rootDir, err := ioutil.TempDir("", "reserved")
+	assert.Nil(t, err)
+	defer os.RemoveAll(rootDir)
+
+	files := map[string]string{
+		"proj.md":                "# proj\n",
+		"proj/sprint.md":         "# Sprint planning\n\nStatus: planned\n",
+		"proj/sprint-story.md":   "# Sprint story\n\nStatus: planned\n",
+		"proj/archive/sprint.md": "# Old sprint\n\nStatus: finished\nArchive: true\n",
+	}
+	for name, data := range files {
+		filePath := filepath.Join(rootDir, filepath.FromSlash(name))
+		assert.Nil(t, os.MkdirAll(filepath.Dir(filePath), 0755))
+		assert.Nil(t, ioutil.WriteFile(filePath, []byte(data), 0644))
+	}
+
+	repo := git.NewMemoryRepository(rootDir, "alice", "alice@example.com")
+	repo.AddRemote("origin", git.NewMemoryRepository("", "", ""))
+	assert.Nil(t, commands.NewSyncAction(repo, rootDir, "").Execute())
+
+	_, err = os.Stat(filepath.Join(rootDir, "proj", "sprint.md"))
+	assert.True(t, os.IsNotExist(err))
+	item, err := backlog.LoadBacklogItem(filepath.Join(rootDir, "proj", "sprint-story-2.md"))
+	assert.Nil(t, err)
+	assert.Equal(t, "Sprint planning", item.Title())
+	item, err = backlog.LoadBacklogItem(filepath.Join(rootDir, "proj", "archive", "sprint-story.md"))
+	assert.Nil(t, err)
+	assert.Equal(t, "Old sprint", item.Title())
+	overview, err := ioutil.ReadFile(filepath.Join(rootDir, "proj.md"))
+	assert.Nil(t, err)
+	assert.Contains(t, string(overview), "Sprint planning")
+}
@@ -11,6 +11,7 @@ import (
 )
 
 type UserList struct {
+	repo     git.Repository
 	usersDir string
 	users    []*User
 }
@@ -38,8 +39,9 @@ func (u *User) Nick() string {
 	return parts[0]
 }
 
-func NewUserList(usersDir string) *UserList {
-	userList := &UserList{usersDir: usersDir}
+// NewUserList loads the users from usersDir, the known users of the repository are added to it first.
+func NewUserList(repo git.Repository, usersDir string) *UserList {
+	userList := &UserList{repo: repo, usersDir: usersDir}
 	userList.init()
 	userList.load()
 	return userList
@@ -104,13 +106,13 @@ func (ul *UserList) init() error {
 		}
 	}
 
-	names, emails, err := git.KnownUsers()
+	names, emails, err := ul.repo.KnownUsers()
 	if err == nil {
 		for i := range names {
 			ul.AddUser(names[i], emails[i])
 		}
 	}
-	name, email, err := git.CurrentUser()
+	name, email, err := ul.repo.CurrentUser()
 	if err == nil {
 		ul.AddUser(name, email)
 	}
@@ -140,6 +142,10 @@ func (ul *UserList) load() error {
 	return nil
 }
 
+func (ul *UserList) Users() []*User {
+	return ul.users
+}
+
 func (ul *UserList) AllUsers() []string {
 	users := make([]string, 0, len(ul.users))
 	for _, user := range ul.users {
@@ -0,0 +1,112 @@
+package utils
+
+const (
+	MergeOursMarker   = "<<<<<<< ours"
+	MergeSeparator    = "======="
+	MergeTheirsMarker = ">>>>>>> theirs"
+)
+
+// Merge3 merges lines changed in ours and theirs from the common base like diff3 does.
+// Conflicting chunks are written with conflict markers.
+func Merge3(base, ours, theirs []string) (merged []string, conflict bool) {
+	merged = Merge3Func(base, ours, theirs, func(baseChunk, oursChunk, theirsChunk []string) []string {
+		conflict = true
+		chunk := append([]string{MergeOursMarker}, oursChunk...)
+		chunk = append(chunk, MergeSeparator)
+		chunk = append(chunk, theirsChunk...)
+		return append(chunk, MergeTheirsMarker)
+	})
+	return merged, conflict
+}
+
+// Merge3Func merges like Merge3, the chunks changed on both sides are replaced with the result of resolve.
+func Merge3Func(base, ours, theirs []string, resolve func(baseChunk, oursChunk, theirsChunk []string) []string) []string {
+	oursMatches := matchLines(base, ours)
+	theirsMatches := matchLines(base, theirs)
+
+	merged := make([]string, 0, len(ours))
+	baseIndex, oursIndex, theirsIndex := 0, 0, 0
+	for {
+		stable := 0
+		for baseIndex+stable < len(base) && oursMatches[baseIndex+stable] == oursIndex+stable && theirsMatches[baseIndex+stable] == theirsIndex+stable {
+			stable++
+		}
+		if stable > 0 {
+			merged = append(merged, base[baseIndex:baseIndex+stable]...)
+			baseIndex, oursIndex, theirsIndex = baseIndex+stable, oursIndex+stable, theirsIndex+stable
+			continue
+		}
+		if baseIndex == len(base) && oursIndex == len(ours) && theirsIndex == len(theirs) {
+			break
+		}
+
+		nextBase, nextOurs, nextTheirs := baseIndex, len(ours), len(theirs)
+		for nextBase < len(base) && (oursMatches[nextBase] < 0 || theirsMatches[nextBase] < 0) {
+			nextBase++
+		}
+		if nextBase < len(base) {
+			nextOurs, nextTheirs = oursMatches[nextBase], theirsMatches[nextBase]
+		}
+		baseChunk, oursChunk, theirsChunk := base[baseIndex:nextBase], ours[oursIndex:nextOurs], theirs[theirsIndex:nextTheirs]
+		switch {
+		case equalLines(oursChunk, theirsChunk), equalLines(theirsChunk, baseChunk):
+			merged = append(merged, oursChunk...)
+		case equalLines(oursChunk, baseChunk):
+			merged = append(merged, theirsChunk...)
+		default:
+			merged = append(merged, resolve(baseChunk, oursChunk, theirsChunk)...)
+		}
+		baseIndex, oursIndex, theirsIndex = nextBase, nextOurs, nextTheirs
+	}
+	return merged
+}
+
+// matchLines returns the index of the matched line in other for every line of base, or -1.
+// The matching is the longest common subsequence.
+func matchLines(base, other []string) []int {
+	lengths := make([][]int, len(base)+1)
+	for i := range lengths {
+		lengths[i] = make([]int, len(other)+1)
+	}
+	for i := len(base) - 1; i >= 0; i-- {
+		for j := len(other) - 1; j >= 0; j-- {
+			if base[i] == other[j] {
+				lengths[i][j] = lengths[i+1][j+1] + 1
+			} else if lengths[i+1][j] >= lengths[i][j+1] {
+				lengths[i][j] = lengths[i+1][j]
+			} else {
+				lengths[i][j] = lengths[i][j+1]
+			}
+		}
+	}
+
+	matches := make([]int, len(base))
+	for i := range matches {
+		matches[i] = -1
+	}
+	for i, j := 0, 0; i < len(base) && j < len(other); {
+		switch {
+		case base[i] == other[j]:
+			matches[i] = j
+			i++
+			j++
+		case lengths[i+1][j] >= lengths[i][j+1]:
+			i++
+		default:
+			j++
+		}
+	}
+	return matches
+}
+
+func equalLines(lines1, lines2 []string) bool {
+	if len(lines1) != len(lines2) {
+		return false
+	}
+	for i := range lines1 {
+		if lines1[i] != lines2[i] {
+			return false
+		}
+	}
+	return true
+}